@@ -0,0 +1,65 @@
+package common
+
+import (
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+type ServiceAccountNotFoundChecker struct {
+	Subject           kubernetes.IstioObject
+	ServiceAccounts   []string
+	GetServiceAccount func(s kubernetes.IstioObject) string
+	Path              string
+}
+
+func WorkloadEntryServiceAccountChecker(subject kubernetes.IstioObject, serviceAccounts []string) ServiceAccountNotFoundChecker {
+	return ServiceAccountNotFoundChecker{
+		Subject:           subject,
+		ServiceAccounts:   serviceAccounts,
+		GetServiceAccount: GetWorkloadEntryServiceAccount,
+		Path:              "spec/serviceAccount",
+	}
+}
+
+func WorkloadGroupServiceAccountChecker(subject kubernetes.IstioObject, serviceAccounts []string) ServiceAccountNotFoundChecker {
+	return ServiceAccountNotFoundChecker{
+		Subject:           subject,
+		ServiceAccounts:   serviceAccounts,
+		GetServiceAccount: GetWorkloadGroupServiceAccount,
+		Path:              "spec/template/serviceAccount",
+	}
+}
+
+// Check validates that the ServiceAccount of the subject exists in its namespace. The check is skipped when
+// the ServiceAccounts of the namespace are unknown (nil), e.g. when Kiali is not allowed to list them.
+func (sac ServiceAccountNotFoundChecker) Check() ([]*models.IstioCheck, bool) {
+	checks, valid := make([]*models.IstioCheck, 0), true
+
+	serviceAccount := sac.GetServiceAccount(sac.Subject)
+	if serviceAccount == "" || sac.ServiceAccounts == nil {
+		return checks, valid
+	}
+
+	for _, sa := range sac.ServiceAccounts {
+		if sa == serviceAccount {
+			return checks, valid
+		}
+	}
+
+	check := models.Build("generic.serviceaccount.notfound", sac.Path)
+	checks = append(checks, &check)
+	return checks, false
+}
+
+// GetWorkloadEntryServiceAccount returns the spec.serviceAccount of a WorkloadEntry
+func GetWorkloadEntryServiceAccount(s kubernetes.IstioObject) string {
+	serviceAccount, _ := s.GetSpec()["serviceAccount"].(string)
+	return serviceAccount
+}
+
+// GetWorkloadGroupServiceAccount returns the spec.template.serviceAccount of a WorkloadGroup
+func GetWorkloadGroupServiceAccount(s kubernetes.IstioObject) string {
+	template, _ := s.GetSpec()["template"].(map[string]interface{})
+	serviceAccount, _ := template["serviceAccount"].(string)
+	return serviceAccount
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func TestServiceAccountFound(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	we := data.AddServiceAccountToWorkloadEntry("details", data.CreateWorkloadEntry("details-vm", "bookinfo", "10.0.0.1", nil))
+	vals, valid := WorkloadEntryServiceAccountChecker(we, []string{"default", "details"}).Check()
+	assert.Empty(vals)
+	assert.True(valid)
+
+	// unknown service accounts
+	vals, valid = WorkloadEntryServiceAccountChecker(we, nil).Check()
+	assert.Empty(vals)
+	assert.True(valid)
+}
+
+func TestServiceAccountNotFound(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	we := data.AddServiceAccountToWorkloadEntry("details", data.CreateWorkloadEntry("details-vm", "bookinfo", "10.0.0.1", nil))
+	vals, valid := WorkloadEntryServiceAccountChecker(we, []string{"default"}).Check()
+	assert.False(valid)
+	assert.Len(vals, 1)
+	assert.Equal(models.ErrorSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("generic.serviceaccount.notfound", vals[0]))
+	assert.Equal("spec/serviceAccount", vals[0].Path)
+
+	wg := data.AddServiceAccountToWorkloadGroup("details", data.CreateWorkloadGroup("details", "bookinfo", nil))
+	vals, valid = WorkloadGroupServiceAccountChecker(wg, []string{}).Check()
+	assert.False(valid)
+	assert.Len(vals, 1)
+	assert.Equal("spec/template/serviceAccount", vals[0].Path)
+}
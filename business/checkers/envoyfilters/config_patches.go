@@ -0,0 +1,32 @@
+package envoyfilters
+
+import (
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// getConfigPatches returns the spec.configPatches of an EnvoyFilter, invalid patches are returned empty so the
+// index of each patch is kept for the check paths
+func getConfigPatches(envoyFilter kubernetes.IstioObject) []map[string]interface{} {
+	configPatches, ok := envoyFilter.GetSpec()["configPatches"].([]interface{})
+	if !ok {
+		return []map[string]interface{}{}
+	}
+
+	patches := make([]map[string]interface{}, 0, len(configPatches))
+	for _, cp := range configPatches {
+		patch, ok := cp.(map[string]interface{})
+		if !ok {
+			patch = map[string]interface{}{}
+		}
+		patches = append(patches, patch)
+	}
+	return patches
+}
+
+// getPatchMatch returns the match of a config patch
+func getPatchMatch(patch map[string]interface{}) map[string]interface{} {
+	if match, ok := patch["match"].(map[string]interface{}); ok {
+		return match
+	}
+	return map[string]interface{}{}
+}
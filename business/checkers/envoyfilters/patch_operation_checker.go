@@ -0,0 +1,64 @@
+package envoyfilters
+
+import (
+	"fmt"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+// operationsPerApplyTo holds the patch operations Istio supports for each applyTo value. Insert operations only
+// make sense for ordered lists (filters and routes), and the route configuration and bootstrap can only be merged.
+var operationsPerApplyTo = map[string][]string{
+	"LISTENER":            {"MERGE", "ADD", "REMOVE"},
+	"FILTER_CHAIN":        {"MERGE", "ADD", "REMOVE"},
+	"NETWORK_FILTER":      {"MERGE", "ADD", "REMOVE", "INSERT_BEFORE", "INSERT_AFTER", "INSERT_FIRST", "REPLACE"},
+	"HTTP_FILTER":         {"MERGE", "ADD", "REMOVE", "INSERT_BEFORE", "INSERT_AFTER", "INSERT_FIRST", "REPLACE"},
+	"ROUTE_CONFIGURATION": {"MERGE"},
+	"VIRTUAL_HOST":        {"MERGE", "ADD", "REMOVE"},
+	"HTTP_ROUTE":          {"MERGE", "ADD", "REMOVE", "INSERT_BEFORE", "INSERT_AFTER", "INSERT_FIRST"},
+	"CLUSTER":             {"MERGE", "ADD", "REMOVE"},
+	"EXTENSION_CONFIG":    {"ADD"},
+	"BOOTSTRAP":           {"MERGE"},
+}
+
+type PatchOperationChecker struct {
+	EnvoyFilter kubernetes.IstioObject
+}
+
+// Check validates the applyTo of each config patch, and that its operation is supported for that applyTo
+func (poc PatchOperationChecker) Check() ([]*models.IstioCheck, bool) {
+	checks, valid := make([]*models.IstioCheck, 0), true
+
+	for i, patch := range getConfigPatches(poc.EnvoyFilter) {
+		applyTo, _ := patch["applyTo"].(string)
+		operations, found := operationsPerApplyTo[applyTo]
+		if !found {
+			check := models.Build("envoyfilter.patch.unknownapplyto", fmt.Sprintf("spec/configPatches[%d]/applyTo", i))
+			checks = append(checks, &check)
+			valid = false
+			continue
+		}
+
+		operation := ""
+		if p, ok := patch["patch"].(map[string]interface{}); ok {
+			operation, _ = p["operation"].(string)
+		}
+		if !contains(operations, operation) {
+			check := models.Build("envoyfilter.patch.invalidoperation", fmt.Sprintf("spec/configPatches[%d]/patch/operation", i))
+			checks = append(checks, &check)
+			valid = false
+		}
+	}
+
+	return checks, valid
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package envoyfilters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func TestValidPatchOperations(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	ef := data.CreateEnvoyFilter("ef", "bookinfo")
+	data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("HTTP_FILTER", "INSERT_BEFORE", nil), ef)
+	data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("CLUSTER", "MERGE", nil), ef)
+	data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("NETWORK_FILTER", "REPLACE", nil), ef)
+
+	vals, valid := PatchOperationChecker{EnvoyFilter: ef}.Check()
+
+	assert.Empty(vals)
+	assert.True(valid)
+}
+
+func TestUnsupportedPatchOperation(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	ef := data.CreateEnvoyFilter("ef", "bookinfo")
+	data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("HTTP_FILTER", "MERGE", nil), ef)
+	data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("CLUSTER", "INSERT_BEFORE", nil), ef)
+	data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("ROUTE_CONFIGURATION", "", nil), ef)
+
+	vals, valid := PatchOperationChecker{EnvoyFilter: ef}.Check()
+
+	assert.False(valid)
+	assert.Len(vals, 2)
+	assert.Equal(models.ErrorSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("envoyfilter.patch.invalidoperation", vals[0]))
+	assert.Equal("spec/configPatches[1]/patch/operation", vals[0].Path)
+	assert.NoError(validations.ConfirmIstioCheckMessage("envoyfilter.patch.invalidoperation", vals[1]))
+	assert.Equal("spec/configPatches[2]/patch/operation", vals[1].Path)
+}
+
+func TestUnknownApplyTo(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	ef := data.CreateEnvoyFilter("ef", "bookinfo")
+	data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("HTTP_FILTERS", "MERGE", nil), ef)
+
+	vals, valid := PatchOperationChecker{EnvoyFilter: ef}.Check()
+
+	assert.False(valid)
+	assert.Len(vals, 1)
+	assert.NoError(validations.ConfirmIstioCheckMessage("envoyfilter.patch.unknownapplyto", vals[0]))
+	assert.Equal("spec/configPatches[0]/applyTo", vals[0].Path)
+}
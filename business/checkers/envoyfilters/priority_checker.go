@@ -0,0 +1,118 @@
+package envoyfilters
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/business/checkers/common"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+// listenerApplyTo holds the applyTo values patching listeners
+var listenerApplyTo = map[string]bool{
+	"LISTENER":       true,
+	"FILTER_CHAIN":   true,
+	"NETWORK_FILTER": true,
+	"HTTP_FILTER":    true,
+}
+
+type PriorityChecker struct {
+	EnvoyFilters []kubernetes.IstioObject
+}
+
+type patchReference struct {
+	envoyFilter kubernetes.IstioObject
+	index       int
+}
+
+// Check looks for EnvoyFilters selecting the same workloads and patching the same listener in the same context
+// with the same priority: Istio then applies the patches in the creation order of the filters, which is hardly
+// ever intended.
+func (pc PriorityChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	patchesPerTarget := make(map[string][]patchReference)
+	targets := []string{}
+	for _, ef := range pc.EnvoyFilters {
+		for i, patch := range getConfigPatches(ef) {
+			target, ok := patchTarget(ef, patch)
+			if !ok {
+				continue
+			}
+			if _, found := patchesPerTarget[target]; !found {
+				targets = append(targets, target)
+			}
+			patchesPerTarget[target] = append(patchesPerTarget[target], patchReference{envoyFilter: ef, index: i})
+		}
+	}
+
+	for _, target := range targets {
+		patches := patchesPerTarget[target]
+		for _, patch := range patches {
+			references := make([]models.IstioValidationKey, 0, len(patches))
+			for _, other := range patches {
+				if other.envoyFilter.GetObjectMeta().Name != patch.envoyFilter.GetObjectMeta().Name {
+					references = appendUniqueReference(references, envoyFilterKey(other.envoyFilter))
+				}
+			}
+			// patches of the same filter are applied in order
+			if len(references) == 0 {
+				continue
+			}
+
+			key := envoyFilterKey(patch.envoyFilter)
+			check := models.Build("envoyfilter.priority.conflict", fmt.Sprintf("spec/configPatches[%d]", patch.index))
+			validations.MergeValidations(models.IstioValidations{key: &models.IstioValidation{
+				Name:       key.Name,
+				ObjectType: key.ObjectType,
+				Valid:      true,
+				Checks:     []*models.IstioCheck{&check},
+				References: references,
+			}})
+		}
+	}
+
+	return validations
+}
+
+// patchTarget returns the workloads, listener, context and priority of a listener patch as a comparable string
+func patchTarget(envoyFilter kubernetes.IstioObject, patch map[string]interface{}) (string, bool) {
+	applyTo, _ := patch["applyTo"].(string)
+	match := getPatchMatch(patch)
+	listener, hasListener := match["listener"]
+	if !listenerApplyTo[applyTo] && !hasListener {
+		return "", false
+	}
+
+	context, ok := match["context"].(string)
+	if !ok || context == "" {
+		context = "ANY"
+	}
+	priority, ok := envoyFilter.GetSpec()["priority"]
+	if !ok {
+		priority = 0
+	}
+	listenerMatch, err := json.Marshal(listener)
+	if err != nil {
+		return "", false
+	}
+	selector := labels.Set(common.GetWorkloadSelectorLabels(envoyFilter)).String()
+
+	return fmt.Sprintf("%s|%s|%v|%s|%s|%s", envoyFilter.GetObjectMeta().Namespace, selector, priority, applyTo, context, listenerMatch), true
+}
+
+func envoyFilterKey(envoyFilter kubernetes.IstioObject) models.IstioValidationKey {
+	return models.BuildKey("envoyfilter", envoyFilter.GetObjectMeta().Name, envoyFilter.GetObjectMeta().Namespace)
+}
+
+func appendUniqueReference(references []models.IstioValidationKey, key models.IstioValidationKey) []models.IstioValidationKey {
+	for _, ref := range references {
+		if ref == key {
+			return references
+		}
+	}
+	return append(references, key)
+}
@@ -0,0 +1,75 @@
+package envoyfilters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func listenerEnvoyFilter(name string, priority int, app string) kubernetes.IstioObject {
+	ef := data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("HTTP_FILTER", "INSERT_BEFORE", map[string]interface{}{
+		"context": "SIDECAR_INBOUND",
+		"listener": map[string]interface{}{
+			"portNumber": 9080,
+		},
+	}), data.CreateEnvoyFilter(name, "bookinfo"))
+	ef.GetSpec()["priority"] = priority
+	return data.AddSelectorToEnvoyFilter(map[string]interface{}{
+		"labels": map[string]interface{}{
+			"app": app,
+		},
+	}, ef)
+}
+
+func TestPriorityConflict(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals := PriorityChecker{EnvoyFilters: []kubernetes.IstioObject{
+		listenerEnvoyFilter("ef1", 0, "reviews"),
+		listenerEnvoyFilter("ef2", 0, "reviews"),
+	}}.Check()
+
+	assert.Len(vals, 2)
+	for name, ref := range map[string]string{"ef1": "ef2", "ef2": "ef1"} {
+		validation, ok := vals[models.BuildKey("envoyfilter", name, "bookinfo")]
+		assert.True(ok)
+		assert.True(validation.Valid)
+		assert.Len(validation.Checks, 1)
+		assert.NoError(validations.ConfirmIstioCheckMessage("envoyfilter.priority.conflict", validation.Checks[0]))
+		assert.Equal("spec/configPatches[0]", validation.Checks[0].Path)
+		assert.Equal([]models.IstioValidationKey{models.BuildKey("envoyfilter", ref, "bookinfo")}, validation.References)
+	}
+}
+
+func TestNoPriorityConflict(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	// different priorities
+	vals := PriorityChecker{EnvoyFilters: []kubernetes.IstioObject{
+		listenerEnvoyFilter("ef1", 0, "reviews"),
+		listenerEnvoyFilter("ef2", 10, "reviews"),
+	}}.Check()
+	assert.Empty(vals)
+
+	// different workloads
+	vals = PriorityChecker{EnvoyFilters: []kubernetes.IstioObject{
+		listenerEnvoyFilter("ef1", 0, "reviews"),
+		listenerEnvoyFilter("ef2", 0, "ratings"),
+	}}.Check()
+	assert.Empty(vals)
+
+	// not patching listeners
+	vals = PriorityChecker{EnvoyFilters: []kubernetes.IstioObject{
+		data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("CLUSTER", "MERGE", nil), data.CreateEnvoyFilter("ef1", "bookinfo")),
+		data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("CLUSTER", "MERGE", nil), data.CreateEnvoyFilter("ef2", "bookinfo")),
+	}}.Check()
+	assert.Empty(vals)
+}
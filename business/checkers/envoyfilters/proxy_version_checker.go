@@ -0,0 +1,47 @@
+package envoyfilters
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+type ProxyVersionChecker struct {
+	EnvoyFilter  kubernetes.IstioObject
+	IstioVersion string
+}
+
+// Check validates the match.proxy.proxyVersion of each config patch: the regular expression must be valid and
+// match the running Istio version, otherwise the patch is never applied. The version match is skipped when the
+// Istio version is unknown.
+func (pvc ProxyVersionChecker) Check() ([]*models.IstioCheck, bool) {
+	checks, valid := make([]*models.IstioCheck, 0), true
+
+	for i, patch := range getConfigPatches(pvc.EnvoyFilter) {
+		proxy, ok := getPatchMatch(patch)["proxy"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		proxyVersion, ok := proxy["proxyVersion"].(string)
+		if !ok || proxyVersion == "" {
+			continue
+		}
+
+		path := fmt.Sprintf("spec/configPatches[%d]/match/proxy/proxyVersion", i)
+		versionExpr, err := regexp.Compile(proxyVersion)
+		if err != nil {
+			check := models.Build("envoyfilter.proxyversion.invalid", path)
+			checks = append(checks, &check)
+			valid = false
+			continue
+		}
+		if pvc.IstioVersion != "" && !versionExpr.MatchString(pvc.IstioVersion) {
+			check := models.Build("envoyfilter.proxyversion.nomatch", path)
+			checks = append(checks, &check)
+		}
+	}
+
+	return checks, valid
+}
@@ -0,0 +1,68 @@
+package envoyfilters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func proxyVersionEnvoyFilter(proxyVersion string) kubernetes.IstioObject {
+	return data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("CLUSTER", "MERGE", map[string]interface{}{
+		"proxy": map[string]interface{}{
+			"proxyVersion": proxyVersion,
+		},
+	}), data.CreateEnvoyFilter("ef", "bookinfo"))
+}
+
+func TestProxyVersionMatching(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := ProxyVersionChecker{EnvoyFilter: proxyVersionEnvoyFilter(`^1\.9.*`), IstioVersion: "1.9.2"}.Check()
+	assert.Empty(vals)
+	assert.True(valid)
+
+	// no patch with a proxy version
+	vals, valid = ProxyVersionChecker{
+		EnvoyFilter:  data.AddPatchToEnvoyFilter(data.CreateEnvoyFilterPatch("CLUSTER", "MERGE", nil), data.CreateEnvoyFilter("ef", "bookinfo")),
+		IstioVersion: "1.9.2",
+	}.Check()
+	assert.Empty(vals)
+	assert.True(valid)
+
+	// unknown Istio version
+	vals, valid = ProxyVersionChecker{EnvoyFilter: proxyVersionEnvoyFilter(`^1\.8.*`)}.Check()
+	assert.Empty(vals)
+	assert.True(valid)
+}
+
+func TestProxyVersionNotMatching(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := ProxyVersionChecker{EnvoyFilter: proxyVersionEnvoyFilter(`^1\.8.*`), IstioVersion: "1.9.2"}.Check()
+
+	assert.True(valid)
+	assert.Len(vals, 1)
+	assert.Equal(models.WarningSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("envoyfilter.proxyversion.nomatch", vals[0]))
+	assert.Equal("spec/configPatches[0]/match/proxy/proxyVersion", vals[0].Path)
+}
+
+func TestInvalidProxyVersion(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := ProxyVersionChecker{EnvoyFilter: proxyVersionEnvoyFilter(`^1\.(8|9.*`), IstioVersion: "1.9.2"}.Check()
+
+	assert.False(valid)
+	assert.Len(vals, 1)
+	assert.Equal(models.ErrorSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("envoyfilter.proxyversion.invalid", vals[0]))
+}
@@ -1,6 +1,8 @@
 package checkers
 
 import (
+	core_v1 "k8s.io/api/core/v1"
+
 	"github.com/kiali/kiali/business/checkers/gateways"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/models"
@@ -11,6 +13,7 @@ const GatewayCheckerType = "gateway"
 type GatewayChecker struct {
 	GatewaysPerNamespace  [][]kubernetes.IstioObject
 	Namespace             string
+	SecretsPerNamespace   map[string][]core_v1.Secret
 	WorkloadsPerNamespace map[string]models.WorkloadList
 }
 
@@ -41,6 +44,11 @@ func (g GatewayChecker) runSingleChecks(gw kubernetes.IstioObject) models.IstioV
 			Gateway:               gw,
 			WorkloadsPerNamespace: g.WorkloadsPerNamespace,
 		},
+		gateways.TLSChecker{
+			Gateway:               gw,
+			WorkloadsPerNamespace: g.WorkloadsPerNamespace,
+			SecretsPerNamespace:   g.SecretsPerNamespace,
+		},
 	}
 
 	for _, checker := range enabledCheckers {
@@ -0,0 +1,233 @@
+package gateways
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+// credentialKeys holds the keys of the certificate and private key in a credential Secret, Istio supports
+// both the kubernetes.io/tls keys and the generic keys
+var credentialKeys = [][2]string{
+	{core_v1.TLSCertKey, core_v1.TLSPrivateKeyKey},
+	{"cert", "key"},
+}
+
+type TLSChecker struct {
+	Gateway               kubernetes.IstioObject
+	WorkloadsPerNamespace map[string]models.WorkloadList
+	SecretsPerNamespace   map[string][]core_v1.Secret
+}
+
+// Check validates the tls.credentialName of each server: the Secret must exist in the namespace of the gateway
+// workload and hold a valid certificate and private key, the certificate must not be expired or expire soon,
+// and it must cover the hosts of the server. SecretsPerNamespace holds, at least, the existing credential Secrets
+// of each namespace: the Secrets of a missing namespace are unknown, e.g. when Kiali is not allowed to get them,
+// and the checks are then skipped.
+func (t TLSChecker) Check() ([]*models.IstioCheck, bool) {
+	validations := make([]*models.IstioCheck, 0)
+
+	servers, ok := t.Gateway.GetSpec()["servers"].([]interface{})
+	if !ok {
+		return validations, true
+	}
+	namespaces := t.getWorkloadNamespaces()
+
+	for serverIndex, server := range servers {
+		serverDef, ok := server.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		tlsDef, ok := serverDef["tls"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		credentialName, ok := tlsDef["credentialName"].(string)
+		if !ok || credentialName == "" {
+			continue
+		}
+
+		path := fmt.Sprintf("spec/servers[%d]/tls/credentialName", serverIndex)
+		secret, known := t.findSecret(credentialName, namespaces)
+		if !known {
+			continue
+		}
+		if secret == nil {
+			validation := models.Build("gateways.tls.credentialnotfound", path)
+			validations = append(validations, &validation)
+			continue
+		}
+
+		cert := parseCredential(*secret)
+		if cert == nil {
+			validation := models.Build("gateways.tls.invalidcredential", path)
+			validations = append(validations, &validation)
+			continue
+		}
+
+		now := time.Now()
+		warningDays := config.Get().KialiFeatureFlags.Validations.CertExpirationWarningDays
+		if now.After(cert.NotAfter) {
+			validation := models.Build("gateways.tls.certexpired", path)
+			validations = append(validations, &validation)
+		} else if warningDays > 0 && now.AddDate(0, 0, warningDays).After(cert.NotAfter) {
+			validation := models.Build("gateways.tls.certexpiring", path)
+			validations = append(validations, &validation)
+		}
+
+		hosts, _ := serverDef["hosts"].([]interface{})
+		for hostIndex, h := range hosts {
+			host, ok := h.(string)
+			if !ok {
+				continue
+			}
+			// hosts may be prefixed by a namespace, e.g. bookinfo/reviews.example.com
+			if i := strings.Index(host, "/"); i >= 0 {
+				host = host[i+1:]
+			}
+			if host == "*" || certificateCovers(cert, host) {
+				continue
+			}
+			validation := models.Build("gateways.tls.hostnotcovered", fmt.Sprintf("spec/servers[%d]/hosts[%d]", serverIndex, hostIndex))
+			validations = append(validations, &validation)
+		}
+	}
+
+	valid := true
+	for _, validation := range validations {
+		valid = valid && validation.Severity != models.ErrorSeverity
+	}
+	return validations, valid
+}
+
+// CredentialNames returns the names of the credential Secrets referred by the gateways, per namespace where they
+// are looked up: the namespaces of the workloads selected by the gateways
+func CredentialNames(gateways []kubernetes.IstioObject, workloadsPerNamespace map[string]models.WorkloadList) map[string][]string {
+	namesPerNamespace := make(map[string][]string)
+	for _, gw := range gateways {
+		names := credentialNames(gw)
+		if len(names) == 0 {
+			continue
+		}
+		for _, ns := range (TLSChecker{Gateway: gw, WorkloadsPerNamespace: workloadsPerNamespace}).getWorkloadNamespaces() {
+			for _, name := range names {
+				if !containsName(namesPerNamespace[ns], name) {
+					namesPerNamespace[ns] = append(namesPerNamespace[ns], name)
+				}
+			}
+		}
+	}
+	return namesPerNamespace
+}
+
+func credentialNames(gw kubernetes.IstioObject) []string {
+	names := []string{}
+	servers, _ := gw.GetSpec()["servers"].([]interface{})
+	for _, server := range servers {
+		serverDef, _ := server.(map[string]interface{})
+		tlsDef, _ := serverDef["tls"].(map[string]interface{})
+		if credentialName, _ := tlsDef["credentialName"].(string); credentialName != "" && !containsName(names, credentialName) {
+			names = append(names, credentialName)
+		}
+	}
+	return names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getWorkloadNamespaces returns the namespaces of the workloads selected by the Gateway, the credential Secrets
+// are looked up in those namespaces
+func (t TLSChecker) getWorkloadNamespaces() []string {
+	selectorSpec, ok := t.Gateway.GetSpec()["selector"].(map[string]interface{})
+	if !ok || len(selectorSpec) == 0 {
+		return []string{}
+	}
+	labelSelectors := make(map[string]string, len(selectorSpec))
+	for k, v := range selectorSpec {
+		if value, ok := v.(string); ok {
+			labelSelectors[k] = value
+		}
+	}
+	selector := labels.SelectorFromSet(labelSelectors)
+
+	namespaces := []string{}
+	for ns, wls := range t.WorkloadsPerNamespace {
+		for _, wl := range wls.Workloads {
+			if selector.Matches(labels.Set(wl.Labels)) {
+				namespaces = append(namespaces, ns)
+				break
+			}
+		}
+	}
+	return namespaces
+}
+
+// findSecret returns the Secret named name in one of the namespaces. It is unknown whether the Secret exists
+// when no namespace is given, or when the Secrets of one of the namespaces are unknown and it is not found.
+func (t TLSChecker) findSecret(name string, namespaces []string) (*core_v1.Secret, bool) {
+	known := len(namespaces) > 0
+	for _, ns := range namespaces {
+		secrets, found := t.SecretsPerNamespace[ns]
+		if !found {
+			known = false
+			continue
+		}
+		for i := range secrets {
+			if secrets[i].Name == name {
+				return &secrets[i], true
+			}
+		}
+	}
+	return nil, known
+}
+
+// parseCredential returns the certificate of a credential Secret, or nil when the Secret doesn't hold a valid
+// certificate and private key
+func parseCredential(secret core_v1.Secret) *x509.Certificate {
+	for _, keys := range credentialKeys {
+		certPEM, found := secret.Data[keys[0]]
+		if !found {
+			continue
+		}
+		pair, err := tls.X509KeyPair(certPEM, secret.Data[keys[1]])
+		if err != nil {
+			return nil
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return nil
+		}
+		return cert
+	}
+	return nil
+}
+
+// certificateCovers returns true when the certificate is valid for the host. A wildcard host is only covered by
+// the same wildcard name.
+func certificateCovers(cert *x509.Certificate, host string) bool {
+	if strings.HasPrefix(host, "*") {
+		for _, name := range cert.DNSNames {
+			if strings.EqualFold(name, host) {
+				return true
+			}
+		}
+		return false
+	}
+	return cert.VerifyHostname(host) == nil
+}
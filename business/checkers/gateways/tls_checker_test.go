@@ -0,0 +1,158 @@
+package gateways
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	core_v1 "k8s.io/api/core/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func tlsGateway(hosts []string, credentialName string) kubernetes.IstioObject {
+	return data.AddServerToGateway(data.AddTLSToServer("SIMPLE", credentialName, data.CreateServer(hosts, 443, "https", "https")),
+		data.CreateEmptyGateway("bookinfo-gateway", "bookinfo", map[string]string{"istio": "ingressgateway"}))
+}
+
+func tlsWorkloads() map[string]models.WorkloadList {
+	return map[string]models.WorkloadList{
+		"istio-system": data.CreateWorkloadList("istio-system", data.CreateWorkloadListItem("istio-ingressgateway", map[string]string{"istio": "ingressgateway"})),
+		"bookinfo":     data.CreateWorkloadList("bookinfo", data.CreateWorkloadListItem("reviews-v1", map[string]string{"app": "reviews"})),
+	}
+}
+
+func TestValidCredential(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := TLSChecker{
+		Gateway:               tlsGateway([]string{"bookinfo/bookinfo.example.com", "reviews.example.com"}, "bookinfo-cert"),
+		WorkloadsPerNamespace: tlsWorkloads(),
+		SecretsPerNamespace: map[string][]core_v1.Secret{
+			"istio-system": {data.CreateTLSSecret("bookinfo-cert", "istio-system", []string{"*.example.com"}, time.Now().AddDate(1, 0, 0))},
+		},
+	}.Check()
+
+	assert.Empty(vals)
+	assert.True(valid)
+}
+
+func TestCredentialNotFound(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	// the secret must be in the namespace of the gateway workload
+	vals, valid := TLSChecker{
+		Gateway:               tlsGateway([]string{"bookinfo.example.com"}, "bookinfo-cert"),
+		WorkloadsPerNamespace: tlsWorkloads(),
+		SecretsPerNamespace: map[string][]core_v1.Secret{
+			"istio-system": {},
+			"bookinfo":     {data.CreateTLSSecret("bookinfo-cert", "bookinfo", []string{"bookinfo.example.com"}, time.Now().AddDate(1, 0, 0))},
+		},
+	}.Check()
+
+	assert.False(valid)
+	assert.Len(vals, 1)
+	assert.Equal(models.ErrorSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("gateways.tls.credentialnotfound", vals[0]))
+	assert.Equal("spec/servers[0]/tls/credentialName", vals[0].Path)
+
+	// unknown secrets
+	vals, valid = TLSChecker{
+		Gateway:               tlsGateway([]string{"bookinfo.example.com"}, "bookinfo-cert"),
+		WorkloadsPerNamespace: tlsWorkloads(),
+	}.Check()
+	assert.Empty(vals)
+	assert.True(valid)
+}
+
+func TestInvalidCredential(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	secret := data.CreateTLSSecret("bookinfo-cert", "istio-system", []string{"bookinfo.example.com"}, time.Now().AddDate(1, 0, 0))
+	// the key doesn't match the certificate
+	secret.Data[core_v1.TLSPrivateKeyKey] = data.CreateTLSSecret("other", "istio-system", nil, time.Now()).Data[core_v1.TLSPrivateKeyKey]
+
+	vals, valid := TLSChecker{
+		Gateway:               tlsGateway([]string{"bookinfo.example.com"}, "bookinfo-cert"),
+		WorkloadsPerNamespace: tlsWorkloads(),
+		SecretsPerNamespace:   map[string][]core_v1.Secret{"istio-system": {secret}},
+	}.Check()
+
+	assert.False(valid)
+	assert.Len(vals, 1)
+	assert.NoError(validations.ConfirmIstioCheckMessage("gateways.tls.invalidcredential", vals[0]))
+}
+
+func TestCertificateExpiration(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.KialiFeatureFlags.Validations.CertExpirationWarningDays = 30
+	config.Set(conf)
+
+	check := func(notAfter time.Time) ([]*models.IstioCheck, bool) {
+		return TLSChecker{
+			Gateway:               tlsGateway([]string{"bookinfo.example.com"}, "bookinfo-cert"),
+			WorkloadsPerNamespace: tlsWorkloads(),
+			SecretsPerNamespace: map[string][]core_v1.Secret{
+				"istio-system": {data.CreateTLSSecret("bookinfo-cert", "istio-system", []string{"bookinfo.example.com"}, notAfter)},
+			},
+		}.Check()
+	}
+
+	vals, valid := check(time.Now().AddDate(0, 0, -1))
+	assert.False(valid)
+	assert.Len(vals, 1)
+	assert.NoError(validations.ConfirmIstioCheckMessage("gateways.tls.certexpired", vals[0]))
+
+	vals, valid = check(time.Now().AddDate(0, 0, 10))
+	assert.True(valid)
+	assert.Len(vals, 1)
+	assert.Equal(models.WarningSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("gateways.tls.certexpiring", vals[0]))
+
+	// no warning when disabled
+	conf.KialiFeatureFlags.Validations.CertExpirationWarningDays = 0
+	config.Set(conf)
+	vals, valid = check(time.Now().AddDate(0, 0, 10))
+	assert.True(valid)
+	assert.Empty(vals)
+}
+
+func TestHostNotCovered(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := TLSChecker{
+		Gateway:               tlsGateway([]string{"bookinfo.example.com", "bookinfo.example.org", "*.example.com", "*"}, "bookinfo-cert"),
+		WorkloadsPerNamespace: tlsWorkloads(),
+		SecretsPerNamespace: map[string][]core_v1.Secret{
+			"istio-system": {data.CreateTLSSecret("bookinfo-cert", "istio-system", []string{"bookinfo.example.com"}, time.Now().AddDate(1, 0, 0))},
+		},
+	}.Check()
+
+	assert.True(valid)
+	assert.Len(vals, 2)
+	assert.NoError(validations.ConfirmIstioCheckMessage("gateways.tls.hostnotcovered", vals[0]))
+	assert.Equal("spec/servers[0]/hosts[1]", vals[0].Path)
+	assert.Equal("spec/servers[0]/hosts[2]", vals[1].Path)
+}
+
+func TestCredentialNames(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	gws := []kubernetes.IstioObject{
+		tlsGateway([]string{"bookinfo.example.com"}, "bookinfo-cert"),
+		data.AddServerToGateway(data.CreateServer([]string{"reviews.example.com"}, 80, "http", "http"),
+			data.CreateEmptyGateway("reviews-gateway", "bookinfo", map[string]string{"app": "reviews"})),
+	}
+
+	assert.Equal(map[string][]string{"istio-system": {"bookinfo-cert"}}, CredentialNames(gws, tlsWorkloads()))
+}
@@ -0,0 +1,50 @@
+package checkers
+
+import (
+	core_v1 "k8s.io/api/core/v1"
+
+	"github.com/kiali/kiali/business/checkers/common"
+	"github.com/kiali/kiali/business/checkers/workloadentries"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+const WorkloadEntryCheckerType = "workloadentry"
+
+type WorkloadEntryChecker struct {
+	WorkloadEntries []kubernetes.IstioObject
+	Services        []core_v1.Service
+	ServiceEntries  []kubernetes.IstioObject
+	ServiceAccounts []string
+}
+
+func (w WorkloadEntryChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	validations.MergeValidations(workloadentries.DuplicateAddressChecker{WorkloadEntries: w.WorkloadEntries}.Check())
+
+	for _, workloadEntry := range w.WorkloadEntries {
+		validations.MergeValidations(w.runChecks(workloadEntry))
+	}
+
+	return validations
+}
+
+// runChecks runs all the individual checks for a single WorkloadEntry and appends the result into validations.
+func (w WorkloadEntryChecker) runChecks(workloadEntry kubernetes.IstioObject) models.IstioValidations {
+	workloadEntryName := workloadEntry.GetObjectMeta().Name
+	key, rrValidation := EmptyValidValidation(workloadEntryName, workloadEntry.GetObjectMeta().Namespace, WorkloadEntryCheckerType)
+
+	enabledCheckers := []Checker{
+		workloadentries.ServiceSelectorChecker{WorkloadEntry: workloadEntry, Services: w.Services, ServiceEntries: w.ServiceEntries},
+		common.WorkloadEntryServiceAccountChecker(workloadEntry, w.ServiceAccounts),
+	}
+
+	for _, checker := range enabledCheckers {
+		checks, validChecker := checker.Check()
+		rrValidation.Checks = append(rrValidation.Checks, checks...)
+		rrValidation.Valid = rrValidation.Valid && validChecker
+	}
+
+	return models.IstioValidations{key: rrValidation}
+}
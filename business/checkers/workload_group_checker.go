@@ -0,0 +1,45 @@
+package checkers
+
+import (
+	"github.com/kiali/kiali/business/checkers/common"
+	"github.com/kiali/kiali/business/checkers/workloadgroups"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+const WorkloadGroupCheckerType = "workloadgroup"
+
+type WorkloadGroupChecker struct {
+	WorkloadGroups  []kubernetes.IstioObject
+	WorkloadList    models.WorkloadList
+	ServiceAccounts []string
+}
+
+func (w WorkloadGroupChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	for _, workloadGroup := range w.WorkloadGroups {
+		validations.MergeValidations(w.runChecks(workloadGroup))
+	}
+
+	return validations
+}
+
+// runChecks runs all the individual checks for a single WorkloadGroup and appends the result into validations.
+func (w WorkloadGroupChecker) runChecks(workloadGroup kubernetes.IstioObject) models.IstioValidations {
+	workloadGroupName := workloadGroup.GetObjectMeta().Name
+	key, rrValidation := EmptyValidValidation(workloadGroupName, workloadGroup.GetObjectMeta().Namespace, WorkloadGroupCheckerType)
+
+	enabledCheckers := []Checker{
+		workloadgroups.WorkloadLabelsChecker{WorkloadGroup: workloadGroup, WorkloadList: w.WorkloadList},
+		common.WorkloadGroupServiceAccountChecker(workloadGroup, w.ServiceAccounts),
+	}
+
+	for _, checker := range enabledCheckers {
+		checks, validChecker := checker.Check()
+		rrValidation.Checks = append(rrValidation.Checks, checks...)
+		rrValidation.Valid = rrValidation.Valid && validChecker
+	}
+
+	return models.IstioValidations{key: rrValidation}
+}
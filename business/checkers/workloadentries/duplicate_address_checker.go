@@ -0,0 +1,58 @@
+package workloadentries
+
+import (
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+type DuplicateAddressChecker struct {
+	WorkloadEntries []kubernetes.IstioObject
+}
+
+// Check looks for WorkloadEntries with the same address in the same network, the endpoints of the workloads
+// are then duplicated
+func (dac DuplicateAddressChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	entriesPerAddress := make(map[string][]kubernetes.IstioObject)
+	addresses := []string{}
+	for _, we := range dac.WorkloadEntries {
+		address, _ := we.GetSpec()["address"].(string)
+		if address == "" {
+			continue
+		}
+		network, _ := we.GetSpec()["network"].(string)
+		key := network + "/" + address
+		if _, found := entriesPerAddress[key]; !found {
+			addresses = append(addresses, key)
+		}
+		entriesPerAddress[key] = append(entriesPerAddress[key], we)
+	}
+
+	for _, address := range addresses {
+		entries := entriesPerAddress[address]
+		if len(entries) < 2 {
+			continue
+		}
+		for _, we := range entries {
+			key := models.BuildKey("workloadentry", we.GetObjectMeta().Name, we.GetObjectMeta().Namespace)
+			references := make([]models.IstioValidationKey, 0, len(entries)-1)
+			for _, other := range entries {
+				if otherKey := models.BuildKey("workloadentry", other.GetObjectMeta().Name, other.GetObjectMeta().Namespace); otherKey != key {
+					references = append(references, otherKey)
+				}
+			}
+
+			check := models.Build("workloadentry.address.duplicate", "spec/address")
+			validations.MergeValidations(models.IstioValidations{key: &models.IstioValidation{
+				Name:       key.Name,
+				ObjectType: key.ObjectType,
+				Valid:      true,
+				Checks:     []*models.IstioCheck{&check},
+				References: references,
+			}})
+		}
+	}
+
+	return validations
+}
@@ -0,0 +1,49 @@
+package workloadentries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func TestDuplicateAddress(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals := DuplicateAddressChecker{WorkloadEntries: []kubernetes.IstioObject{
+		data.CreateWorkloadEntry("details-vm1", "bookinfo", "10.0.0.1", nil),
+		data.CreateWorkloadEntry("details-vm2", "bookinfo", "10.0.0.1", nil),
+		data.CreateWorkloadEntry("details-vm3", "bookinfo", "10.0.0.3", nil),
+	}}.Check()
+
+	assert.Len(vals, 2)
+	for name, ref := range map[string]string{"details-vm1": "details-vm2", "details-vm2": "details-vm1"} {
+		validation, ok := vals[models.BuildKey("workloadentry", name, "bookinfo")]
+		assert.True(ok)
+		assert.True(validation.Valid)
+		assert.Len(validation.Checks, 1)
+		assert.NoError(validations.ConfirmIstioCheckMessage("workloadentry.address.duplicate", validation.Checks[0]))
+		assert.Equal("spec/address", validation.Checks[0].Path)
+		assert.Equal([]models.IstioValidationKey{models.BuildKey("workloadentry", ref, "bookinfo")}, validation.References)
+	}
+}
+
+func TestSameAddressInDifferentNetworks(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vm1 := data.CreateWorkloadEntry("details-vm1", "bookinfo", "10.0.0.1", nil)
+	vm1.GetSpec()["network"] = "network1"
+	vm2 := data.CreateWorkloadEntry("details-vm2", "bookinfo", "10.0.0.1", nil)
+	vm2.GetSpec()["network"] = "network2"
+
+	vals := DuplicateAddressChecker{WorkloadEntries: []kubernetes.IstioObject{vm1, vm2}}.Check()
+
+	assert.Empty(vals)
+}
@@ -0,0 +1,17 @@
+package workloadentries
+
+import (
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// getLabels returns the spec.labels of a WorkloadEntry
+func getLabels(workloadEntry kubernetes.IstioObject) map[string]string {
+	labels := map[string]string{}
+	specLabels, _ := workloadEntry.GetSpec()["labels"].(map[string]interface{})
+	for k, v := range specLabels {
+		if value, ok := v.(string); ok {
+			labels[k] = value
+		}
+	}
+	return labels
+}
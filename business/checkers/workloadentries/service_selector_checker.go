@@ -0,0 +1,38 @@
+package workloadentries
+
+import (
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/business/checkers/common"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+type ServiceSelectorChecker struct {
+	WorkloadEntry  kubernetes.IstioObject
+	Services       []core_v1.Service
+	ServiceEntries []kubernetes.IstioObject
+}
+
+// Check validates that a Service selector or a ServiceEntry workloadSelector of the namespace selects the
+// WorkloadEntry: a WorkloadEntry selected by none never receives traffic.
+func (ssc ServiceSelectorChecker) Check() ([]*models.IstioCheck, bool) {
+	checks, valid := make([]*models.IstioCheck, 0), true
+
+	weLabels := labels.Set(getLabels(ssc.WorkloadEntry))
+	for _, svc := range ssc.Services {
+		if len(svc.Spec.Selector) > 0 && labels.SelectorFromSet(svc.Spec.Selector).Matches(weLabels) {
+			return checks, valid
+		}
+	}
+	for _, se := range ssc.ServiceEntries {
+		if seLabels := common.GetWorkloadSelectorLabels(se); len(seLabels) > 0 && labels.SelectorFromSet(seLabels).Matches(weLabels) {
+			return checks, valid
+		}
+	}
+
+	check := models.Build("workloadentry.labels.servicenotfound", "spec/labels")
+	checks = append(checks, &check)
+	return checks, valid
+}
@@ -0,0 +1,70 @@
+package workloadentries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func fakeService(name string, selector map[string]string) core_v1.Service {
+	return core_v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "bookinfo"},
+		Spec:       core_v1.ServiceSpec{Selector: selector},
+	}
+}
+
+func TestWorkloadEntrySelectedByService(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := ServiceSelectorChecker{
+		WorkloadEntry: data.CreateWorkloadEntry("details-vm", "bookinfo", "10.0.0.1", map[string]interface{}{"app": "details", "version": "vm"}),
+		Services:      []core_v1.Service{fakeService("reviews", map[string]string{"app": "reviews"}), fakeService("details", map[string]string{"app": "details"})},
+	}.Check()
+
+	assert.Empty(vals)
+	assert.True(valid)
+}
+
+func TestWorkloadEntrySelectedByServiceEntry(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	se := data.CreateEmptyMeshExternalServiceEntry("details-se", "bookinfo", []string{"details.example.com"})
+	se.GetSpec()["workloadSelector"] = map[string]interface{}{
+		"labels": map[string]interface{}{"app": "details"},
+	}
+
+	vals, valid := ServiceSelectorChecker{
+		WorkloadEntry:  data.CreateWorkloadEntry("details-vm", "bookinfo", "10.0.0.1", map[string]interface{}{"app": "details"}),
+		ServiceEntries: []kubernetes.IstioObject{se},
+	}.Check()
+
+	assert.Empty(vals)
+	assert.True(valid)
+}
+
+func TestWorkloadEntryNotSelected(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := ServiceSelectorChecker{
+		WorkloadEntry: data.CreateWorkloadEntry("details-vm", "bookinfo", "10.0.0.1", map[string]interface{}{"app": "details"}),
+		// a Service without selector selects nothing
+		Services: []core_v1.Service{fakeService("reviews", map[string]string{"app": "reviews"}), fakeService("external", nil)},
+	}.Check()
+
+	assert.True(valid)
+	assert.Len(vals, 1)
+	assert.Equal(models.WarningSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("workloadentry.labels.servicenotfound", vals[0]))
+	assert.Equal("spec/labels", vals[0].Path)
+}
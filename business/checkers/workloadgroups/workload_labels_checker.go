@@ -0,0 +1,52 @@
+package workloadgroups
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+type WorkloadLabelsChecker struct {
+	WorkloadGroup kubernetes.IstioObject
+	WorkloadList  models.WorkloadList
+}
+
+// Check validates that the labels of the WorkloadEntries created for the group don't match the labels of an
+// existing workload, e.g. a Deployment: the VMs could then not be told apart from its pods, every Service or
+// policy selecting the VMs selecting the pods as well.
+func (wlc WorkloadLabelsChecker) Check() ([]*models.IstioCheck, bool) {
+	checks, valid := make([]*models.IstioCheck, 0), true
+
+	wgLabels := getLabels(wlc.WorkloadGroup)
+	if len(wgLabels) == 0 {
+		return checks, valid
+	}
+
+	selector := labels.SelectorFromSet(wgLabels)
+	for _, wl := range wlc.WorkloadList.Workloads {
+		if selector.Matches(labels.Set(wl.Labels)) {
+			check := models.Build("workloadgroup.labels.workloadconflict", "spec/metadata/labels")
+			checks = append(checks, &check)
+			break
+		}
+	}
+
+	return checks, valid
+}
+
+// getLabels returns the labels of the WorkloadEntries created for a WorkloadGroup, the spec.metadata.labels
+// merged with the spec.template.labels
+func getLabels(workloadGroup kubernetes.IstioObject) map[string]string {
+	result := map[string]string{}
+	for _, field := range []string{"metadata", "template"} {
+		spec, _ := workloadGroup.GetSpec()[field].(map[string]interface{})
+		specLabels, _ := spec["labels"].(map[string]interface{})
+		for k, v := range specLabels {
+			if value, ok := v.(string); ok {
+				result[k] = value
+			}
+		}
+	}
+	return result
+}
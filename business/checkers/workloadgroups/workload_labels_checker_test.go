@@ -0,0 +1,51 @@
+package workloadgroups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func TestWorkloadGroupLabelsConflict(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := WorkloadLabelsChecker{
+		WorkloadGroup: data.CreateWorkloadGroup("details", "bookinfo", map[string]interface{}{"app": "details", "version": "v1"}),
+		WorkloadList: data.CreateWorkloadList("bookinfo",
+			data.CreateWorkloadListItem("details-v1", map[string]string{"app": "details", "version": "v1", "pod-template-hash": "abc"}),
+		),
+	}.Check()
+
+	assert.True(valid)
+	assert.Len(vals, 1)
+	assert.Equal(models.WarningSeverity, vals[0].Severity)
+	assert.NoError(validations.ConfirmIstioCheckMessage("workloadgroup.labels.workloadconflict", vals[0]))
+	assert.Equal("spec/metadata/labels", vals[0].Path)
+}
+
+func TestWorkloadGroupLabelsNoConflict(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	wg := data.CreateWorkloadGroup("details", "bookinfo", map[string]interface{}{"app": "details"})
+	// the template labels are merged with the metadata labels
+	wg.GetSpec()["template"] = map[string]interface{}{
+		"labels": map[string]interface{}{"version": "vm"},
+	}
+
+	vals, valid := WorkloadLabelsChecker{
+		WorkloadGroup: wg,
+		WorkloadList: data.CreateWorkloadList("bookinfo",
+			data.CreateWorkloadListItem("details-v1", map[string]string{"app": "details", "version": "v1"}),
+		),
+	}.Check()
+
+	assert.Empty(vals)
+	assert.True(valid)
+}
@@ -23,12 +23,16 @@ type HealthService struct {
 }
 
 // Annotation Filter for Health
-var HealthAnnotation = []models.AnnotationKey{models.RateHealthAnnotation}
+var HealthAnnotation = []models.AnnotationKey{models.RateHealthAnnotation, models.SLOHealthAnnotation}
 
 // GetServiceHealth returns a service health (service request error rate)
 func (in *HealthService) GetServiceHealth(namespace, service, rateInterval string, queryTime time.Time) (models.ServiceHealth, error) {
 	rqHealth, err := in.getServiceRequestsHealth(namespace, service, rateInterval, queryTime)
-	return models.ServiceHealth{Requests: rqHealth}, err
+	if err != nil {
+		return models.ServiceHealth{Requests: rqHealth}, err
+	}
+	sloStatus, err := in.getSLOStatus(namespace, SLOKindService, service, rqHealth.HealthAnnotations, queryTime)
+	return models.ServiceHealth{Requests: rqHealth, SLOStatus: sloStatus}, err
 }
 
 // GetAppHealth returns an app health from just Namespace and app name (thus, it fetches data from K8S and Prometheus)
@@ -66,6 +70,9 @@ func (in *HealthService) getAppHealth(namespace, app, rateInterval string, query
 		rate, err := in.getAppRequestsHealth(namespace, app, rateInterval, queryTime)
 		health.Requests = rate
 		errRate = err
+		if errRate == nil {
+			health.SLOStatus, errRate = in.getSLOStatus(namespace, SLOKindApp, app, nil, queryTime)
+		}
 	}
 
 	// Deployment status
@@ -93,10 +100,15 @@ func (in *HealthService) GetWorkloadHealth(namespace, workload, workloadType, ra
 
 	// Add Telemetry info
 	rate, err := in.getWorkloadRequestsHealth(namespace, workload, rateInterval, queryTime)
-	return models.WorkloadHealth{
+	health := models.WorkloadHealth{
 		WorkloadStatus: status,
 		Requests:       rate,
-	}, err
+	}
+	if err != nil {
+		return health, err
+	}
+	health.SLOStatus, err = in.getSLOStatus(namespace, SLOKindWorkload, workload, w.HealthAnnotations, queryTime)
+	return health, err
 }
 
 // GetNamespaceAppHealth returns a health for all apps in given Namespace (thus, it fetches data from K8S and Prometheus)
@@ -140,6 +152,11 @@ func (in *HealthService) getNamespaceAppHealth(namespace string, appEntities nam
 		}
 		// Fill with collected request rates
 		fillAppRequestRates(allHealth, rates)
+		for app, h := range allHealth {
+			if h.SLOStatus, err = in.getSLOStatus(namespace, SLOKindApp, app, nil, queryTime); err != nil {
+				log.Errorf("Error fetching SLO status for app [%s/%s]: %v", namespace, app, err)
+			}
+		}
 	}
 
 	return allHealth, nil
@@ -147,8 +164,14 @@ func (in *HealthService) getNamespaceAppHealth(namespace string, appEntities nam
 
 // GetNamespaceServiceHealth returns a health for all services in given Namespace (thus, it fetches data from K8S and Prometheus)
 func (in *HealthService) GetNamespaceServiceHealth(namespace, rateInterval string, queryTime time.Time) (models.NamespaceServiceHealth, error) {
-	var services []core_v1.Service
-	var err error
+	services, err := in.getNamespaceServices(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return in.getNamespaceServiceHealth(namespace, services, rateInterval, queryTime), nil
+}
+
+func (in *HealthService) getNamespaceServices(namespace string) ([]core_v1.Service, error) {
 	// Check if user has access to the namespace (RBAC) in cache scenarios and/or
 	// if namespace is accessible from Kiali (Deployment.AccessibleNamespaces)
 	if _, err := in.businessLayer.Namespace.GetNamespace(namespace); err != nil {
@@ -157,14 +180,9 @@ func (in *HealthService) GetNamespaceServiceHealth(namespace, rateInterval strin
 
 	// Check if namespace is cached
 	if IsNamespaceCached(namespace) {
-		services, err = kialiCache.GetServices(namespace, nil)
-	} else {
-		services, err = in.k8s.GetServices(namespace, nil)
-	}
-	if err != nil {
-		return nil, err
+		return kialiCache.GetServices(namespace, nil)
 	}
-	return in.getNamespaceServiceHealth(namespace, services, rateInterval, queryTime), nil
+	return in.k8s.GetServices(namespace, nil)
 }
 
 func (in *HealthService) getNamespaceServiceHealth(namespace string, services []core_v1.Service, rateInterval string, queryTime time.Time) models.NamespaceServiceHealth {
@@ -190,6 +208,12 @@ func (in *HealthService) getNamespaceServiceHealth(namespace string, services []
 	for _, health := range allHealth {
 		health.Requests.CombineReporters()
 	}
+	for _, service := range services {
+		var err error
+		if allHealth[service.Name].SLOStatus, err = in.getSLOStatus(namespace, SLOKindService, service.Name, service.Annotations, queryTime); err != nil {
+			log.Errorf("Error fetching SLO status for service [%s/%s]: %v", namespace, service.Name, err)
+		}
+	}
 	return allHealth
 }
 
@@ -225,6 +249,14 @@ func (in *HealthService) getNamespaceWorkloadHealth(namespace string, ws models.
 		}
 		// Fill with collected request rates
 		fillWorkloadRequestRates(allHealth, rates)
+		for _, w := range ws {
+			if !w.IstioSidecar {
+				continue
+			}
+			if allHealth[w.Name].SLOStatus, err = in.getSLOStatus(namespace, SLOKindWorkload, w.Name, w.HealthAnnotations, queryTime); err != nil {
+				log.Errorf("Error fetching SLO status for workload [%s/%s]: %v", namespace, w.Name, err)
+			}
+		}
 	}
 
 	return allHealth, nil
@@ -9,11 +9,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/kiali/kiali/business/checkers"
+	"github.com/kiali/kiali/business/checkers/gateways"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
+	"github.com/kiali/kiali/status"
 )
 
 type IstioValidationsService struct {
@@ -95,7 +97,17 @@ func (in *IstioValidationsService) GetValidations(namespace, service string) (mo
 		}
 	}
 
-	objectCheckers := in.getAllObjectCheckers(namespace, istioDetails, exportedResources, services, workloadsPerNamespace, workloads, gatewaysPerNamespace, mtlsDetails, rbacDetails, namespaces, registryStatus)
+	serviceAccounts, err := in.getWorkloadEntriesServiceAccounts(namespace, istioDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	secretsPerNamespace, err := in.getGatewaySecrets(istioDetails.Gateways, workloadsPerNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	objectCheckers := in.getAllObjectCheckers(namespace, istioDetails, exportedResources, services, workloadsPerNamespace, workloads, gatewaysPerNamespace, mtlsDetails, rbacDetails, namespaces, registryStatus, getEnvoyFiltersIstioVersion(istioDetails.EnvoyFilters), serviceAccounts, secretsPerNamespace)
 
 	if service != "" {
 		objectCheckers = append(objectCheckers, in.getServiceCheckers(namespace, services, deployments, pods)...)
@@ -116,18 +128,92 @@ func (in *IstioValidationsService) getServiceCheckers(namespace string, services
 	}
 }
 
-func (in *IstioValidationsService) getAllObjectCheckers(namespace string, istioDetails kubernetes.IstioDetails, exportedResources kubernetes.ExportedResources, services []core_v1.Service, workloadsPerNamespace map[string]models.WorkloadList, workloads models.WorkloadList, gatewaysPerNamespace [][]kubernetes.IstioObject, mtlsDetails kubernetes.MTLSDetails, rbacDetails kubernetes.RBACDetails, namespaces []models.Namespace, registryStatus []*kubernetes.RegistryStatus) []ObjectChecker {
+func (in *IstioValidationsService) getAllObjectCheckers(namespace string, istioDetails kubernetes.IstioDetails, exportedResources kubernetes.ExportedResources, services []core_v1.Service, workloadsPerNamespace map[string]models.WorkloadList, workloads models.WorkloadList, gatewaysPerNamespace [][]kubernetes.IstioObject, mtlsDetails kubernetes.MTLSDetails, rbacDetails kubernetes.RBACDetails, namespaces []models.Namespace, registryStatus []*kubernetes.RegistryStatus, istioVersion string, serviceAccounts []string, secretsPerNamespace map[string][]core_v1.Secret) []ObjectChecker {
 	return []ObjectChecker{
 		checkers.NoServiceChecker{Namespace: namespace, Namespaces: namespaces, IstioDetails: &istioDetails, Services: services, WorkloadList: workloads, GatewaysPerNamespace: gatewaysPerNamespace, AuthorizationDetails: &rbacDetails, RegistryStatus: registryStatus},
 		checkers.VirtualServiceChecker{Namespace: namespace, Namespaces: namespaces, DestinationRules: istioDetails.DestinationRules, VirtualServices: istioDetails.VirtualServices, ExportedDestinationRules: exportedResources.DestinationRules, ExportedVirtualServices: exportedResources.VirtualServices},
 		checkers.DestinationRulesChecker{Namespaces: namespaces, DestinationRules: istioDetails.DestinationRules, MTLSDetails: mtlsDetails, ServiceEntries: istioDetails.ServiceEntries},
-		checkers.GatewayChecker{GatewaysPerNamespace: gatewaysPerNamespace, Namespace: namespace, WorkloadsPerNamespace: workloadsPerNamespace},
+		checkers.GatewayChecker{GatewaysPerNamespace: gatewaysPerNamespace, Namespace: namespace, SecretsPerNamespace: secretsPerNamespace, WorkloadsPerNamespace: workloadsPerNamespace},
 		checkers.PeerAuthenticationChecker{PeerAuthentications: mtlsDetails.PeerAuthentications, MTLSDetails: mtlsDetails, WorkloadList: workloads},
 		checkers.ServiceEntryChecker{ServiceEntries: istioDetails.ServiceEntries, Namespaces: namespaces},
 		checkers.AuthorizationPolicyChecker{AuthorizationPolicies: rbacDetails.AuthorizationPolicies, Namespace: namespace, Namespaces: namespaces, Services: services, ServiceEntries: istioDetails.ServiceEntries, WorkloadList: workloads, MtlsDetails: mtlsDetails, VirtualServices: istioDetails.VirtualServices, RegistryStatus: registryStatus},
 		checkers.SidecarChecker{Sidecars: istioDetails.Sidecars, Namespaces: namespaces, WorkloadList: workloads, Services: services, ServiceEntries: istioDetails.ServiceEntries},
 		checkers.RequestAuthenticationChecker{RequestAuthentications: istioDetails.RequestAuthentications, WorkloadList: workloads},
+		checkers.EnvoyFilterChecker{EnvoyFilters: istioDetails.EnvoyFilters, IstioVersion: istioVersion, WorkloadList: workloads},
+		checkers.WorkloadEntryChecker{WorkloadEntries: istioDetails.WorkloadEntries, Services: services, ServiceEntries: istioDetails.ServiceEntries, ServiceAccounts: serviceAccounts},
+		checkers.WorkloadGroupChecker{WorkloadGroups: istioDetails.WorkloadGroups, WorkloadList: workloads, ServiceAccounts: serviceAccounts},
+	}
+}
+
+// getGatewaySecrets returns the existing credential Secrets referred by the gateways, per namespace where they
+// are looked up. Only the referred Secrets are fetched, none when no gateway refers to a credential. The namespaces
+// where Kiali is not allowed to get Secrets are missing, which skips the credential validations.
+func (in *IstioValidationsService) getGatewaySecrets(gws []kubernetes.IstioObject, workloadsPerNamespace map[string]models.WorkloadList) (map[string][]core_v1.Secret, error) {
+	secretsPerNamespace := make(map[string][]core_v1.Secret)
+NamespacesLoop:
+	for ns, names := range gateways.CredentialNames(gws, workloadsPerNamespace) {
+		secrets := []core_v1.Secret{}
+		for _, name := range names {
+			secret, err := in.k8s.GetSecret(ns, name)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				if checkForbidden("GetSecret", err, "") {
+					continue NamespacesLoop
+				}
+				return nil, err
+			}
+			secrets = append(secrets, *secret)
+		}
+		secretsPerNamespace[ns] = secrets
+	}
+	return secretsPerNamespace, nil
+}
+
+// getWorkloadEntriesServiceAccounts returns the names of the ServiceAccounts of the namespace when they are needed
+// to validate the service accounts of the WorkloadEntries and WorkloadGroups, they are not fetched otherwise.
+// Nil is returned when Kiali is not allowed to list them, which skips those validations.
+func (in *IstioValidationsService) getWorkloadEntriesServiceAccounts(namespace string, istioDetails kubernetes.IstioDetails) ([]string, error) {
+	if len(istioDetails.WorkloadEntries) == 0 && len(istioDetails.WorkloadGroups) == 0 {
+		return nil, nil
+	}
+
+	serviceAccounts, err := in.k8s.GetServiceAccounts(namespace)
+	if err != nil {
+		if checkForbidden("GetServiceAccounts", err, "") {
+			return nil, nil
+		}
+		return nil, err
 	}
+
+	names := make([]string, 0, len(serviceAccounts))
+	for _, sa := range serviceAccounts {
+		names = append(names, sa.Name)
+	}
+	return names, nil
+}
+
+// getEnvoyFiltersIstioVersion returns the running Istio version when it is needed to validate the proxy version
+// matches of the EnvoyFilters, the version is not fetched otherwise. An empty version skips those validations.
+func getEnvoyFiltersIstioVersion(envoyFilters []kubernetes.IstioObject) string {
+	for _, ef := range envoyFilters {
+		configPatches, _ := ef.GetSpec()["configPatches"].([]interface{})
+		for _, cp := range configPatches {
+			patch, _ := cp.(map[string]interface{})
+			match, _ := patch["match"].(map[string]interface{})
+			proxy, _ := match["proxy"].(map[string]interface{})
+			if _, found := proxy["proxyVersion"]; !found {
+				continue
+			}
+			istioVersion, err := status.IstioVersion()
+			if err != nil {
+				log.Debugf("Unable to get the Istio version, EnvoyFilter proxy versions are not validated: %v", err)
+			}
+			return istioVersion
+		}
+	}
+	return ""
 }
 
 // GetIstioObjectValidations validates a single Istio object of the given type with the given name found in the given namespace.
@@ -176,8 +262,12 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 
 	switch objectType {
 	case kubernetes.Gateways:
+		secretsPerNamespace, secretsErr := in.getGatewaySecrets(istioDetails.Gateways, workloadsPerNamespace)
+		if secretsErr != nil {
+			return nil, secretsErr
+		}
 		objectCheckers = []ObjectChecker{
-			checkers.GatewayChecker{GatewaysPerNamespace: gatewaysPerNamespace, Namespace: namespace, WorkloadsPerNamespace: workloadsPerNamespace},
+			checkers.GatewayChecker{GatewaysPerNamespace: gatewaysPerNamespace, Namespace: namespace, SecretsPerNamespace: secretsPerNamespace, WorkloadsPerNamespace: workloadsPerNamespace},
 		}
 	case kubernetes.VirtualServices:
 		virtualServiceChecker := checkers.VirtualServiceChecker{Namespace: namespace, Namespaces: namespaces, VirtualServices: istioDetails.VirtualServices, DestinationRules: istioDetails.DestinationRules, ExportedDestinationRules: exportedResources.DestinationRules, ExportedVirtualServices: exportedResources.VirtualServices}
@@ -202,15 +292,26 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 		peerAuthnChecker := checkers.PeerAuthenticationChecker{PeerAuthentications: mtlsDetails.PeerAuthentications, MTLSDetails: mtlsDetails, WorkloadList: workloads}
 		objectCheckers = []ObjectChecker{peerAuthnChecker}
 	case kubernetes.WorkloadEntries:
-		// Validation on WorkloadEntries are not yet in place
+		serviceAccounts, saErr := in.getWorkloadEntriesServiceAccounts(namespace, istioDetails)
+		if saErr != nil {
+			return nil, saErr
+		}
+		workloadEntryChecker := checkers.WorkloadEntryChecker{WorkloadEntries: istioDetails.WorkloadEntries, Services: services, ServiceEntries: istioDetails.ServiceEntries, ServiceAccounts: serviceAccounts}
+		objectCheckers = []ObjectChecker{workloadEntryChecker}
 	case kubernetes.WorkloadGroups:
-		// Validation on WorkloadGroups are not yet in place
+		serviceAccounts, saErr := in.getWorkloadEntriesServiceAccounts(namespace, istioDetails)
+		if saErr != nil {
+			return nil, saErr
+		}
+		workloadGroupChecker := checkers.WorkloadGroupChecker{WorkloadGroups: istioDetails.WorkloadGroups, WorkloadList: workloads, ServiceAccounts: serviceAccounts}
+		objectCheckers = []ObjectChecker{workloadGroupChecker}
 	case kubernetes.RequestAuthentications:
 		// Validation on RequestAuthentications are not yet in place
 		requestAuthnChecker := checkers.RequestAuthenticationChecker{RequestAuthentications: istioDetails.RequestAuthentications, WorkloadList: workloads}
 		objectCheckers = []ObjectChecker{requestAuthnChecker}
 	case kubernetes.EnvoyFilters:
-		// Validation on EnvoyFilters are not yet in place
+		envoyFilterChecker := checkers.EnvoyFilterChecker{EnvoyFilters: istioDetails.EnvoyFilters, IstioVersion: getEnvoyFiltersIstioVersion(istioDetails.EnvoyFilters), WorkloadList: workloads}
+		objectCheckers = []ObjectChecker{envoyFilterChecker}
 	default:
 		err = fmt.Errorf("object type not found: %v", objectType)
 	}
@@ -487,6 +588,33 @@ func (in *IstioValidationsService) fetchDetails(rValue *kubernetes.IstioDetails,
 			}
 			go fetchIstioObjects(&istioDetails.RequestAuthentications, namespace, getRequestAuthentications, &wg2, errChan2)
 		}
+		if IsResourceCached(namespace, kubernetes.EnvoyFilters) {
+			istioDetails.EnvoyFilters, err = kialiCache.GetIstioObjects(namespace, kubernetes.EnvoyFilters, "")
+		} else {
+			wg2.Add(1)
+			getEnvoyFilters := func(namespace string) ([]kubernetes.IstioObject, error) {
+				return in.k8s.GetIstioObjects(namespace, kubernetes.EnvoyFilters, "")
+			}
+			go fetchIstioObjects(&istioDetails.EnvoyFilters, namespace, getEnvoyFilters, &wg2, errChan2)
+		}
+		if IsResourceCached(namespace, kubernetes.WorkloadEntries) {
+			istioDetails.WorkloadEntries, err = kialiCache.GetIstioObjects(namespace, kubernetes.WorkloadEntries, "")
+		} else {
+			wg2.Add(1)
+			getWorkloadEntries := func(namespace string) ([]kubernetes.IstioObject, error) {
+				return in.k8s.GetIstioObjects(namespace, kubernetes.WorkloadEntries, "")
+			}
+			go fetchIstioObjects(&istioDetails.WorkloadEntries, namespace, getWorkloadEntries, &wg2, errChan2)
+		}
+		if IsResourceCached(namespace, kubernetes.WorkloadGroups) {
+			istioDetails.WorkloadGroups, err = kialiCache.GetIstioObjects(namespace, kubernetes.WorkloadGroups, "")
+		} else {
+			wg2.Add(1)
+			getWorkloadGroups := func(namespace string) ([]kubernetes.IstioObject, error) {
+				return in.k8s.GetIstioObjects(namespace, kubernetes.WorkloadGroups, "")
+			}
+			go fetchIstioObjects(&istioDetails.WorkloadGroups, namespace, getWorkloadGroups, &wg2, errChan2)
+		}
 		wg2.Wait()
 
 		// Error may come either from errChan2 (when goroutines are used / without cache) or err (with cache / synchronous)
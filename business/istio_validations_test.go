@@ -3,6 +3,7 @@ package business
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	osapps_v1 "github.com/openshift/api/apps/v1"
 	"github.com/stretchr/testify/assert"
@@ -11,6 +12,7 @@ import (
 	batch_v1 "k8s.io/api/batch/v1"
 	batch_v1beta1 "k8s.io/api/batch/v1beta1"
 	core_v1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kiali/kiali/config"
@@ -56,6 +58,45 @@ func TestGatewayValidation(t *testing.T) {
 	assert.NotEmpty(validations)
 }
 
+func TestGetGatewaySecrets(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	gws := []kubernetes.IstioObject{
+		data.AddServerToGateway(data.AddTLSToServer("SIMPLE", "bookinfo-cert", data.CreateServer([]string{"bookinfo.example.com"}, 443, "https", "https")),
+			data.AddServerToGateway(data.AddTLSToServer("SIMPLE", "reviews-cert", data.CreateServer([]string{"reviews.example.com"}, 443, "https", "https")),
+				data.CreateEmptyGateway("bookinfo-gateway", "bookinfo", map[string]string{"istio": "ingressgateway"}))),
+	}
+	workloadsPerNamespace := map[string]models.WorkloadList{
+		"istio-system": data.CreateWorkloadList("istio-system", data.CreateWorkloadListItem("istio-ingressgateway", map[string]string{"istio": "ingressgateway"})),
+	}
+	secret := data.CreateTLSSecret("bookinfo-cert", "istio-system", []string{"bookinfo.example.com"}, time.Now().AddDate(1, 0, 0))
+
+	// only the referred Secrets are fetched
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("IsMaistraApi").Return(false)
+	k8s.On("GetSecret", "istio-system", "bookinfo-cert").Return(&secret, nil)
+	k8s.On("GetSecret", "istio-system", "reviews-cert").Return(&core_v1.Secret{}, errors.NewNotFound(core_v1.Resource("secrets"), "reviews-cert"))
+	vs := IstioValidationsService{k8s: k8s, businessLayer: NewWithBackends(k8s, nil, nil)}
+
+	secretsPerNamespace, err := vs.getGatewaySecrets(gws, workloadsPerNamespace)
+	assert.NoError(err)
+	assert.Equal(map[string][]core_v1.Secret{"istio-system": {secret}}, secretsPerNamespace)
+	k8s.AssertNumberOfCalls(t, "GetSecret", 2)
+
+	// the Secrets are unknown when Kiali is not allowed to get them
+	k8s = new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("IsMaistraApi").Return(false)
+	k8s.On("GetSecret", "istio-system", mock.AnythingOfType("string")).Return(&core_v1.Secret{}, errors.NewForbidden(core_v1.Resource("secrets"), "bookinfo-cert", fmt.Errorf("forbidden")))
+	vs = IstioValidationsService{k8s: k8s, businessLayer: NewWithBackends(k8s, nil, nil)}
+
+	secretsPerNamespace, err = vs.getGatewaySecrets(gws, workloadsPerNamespace)
+	assert.NoError(err)
+	assert.Empty(secretsPerNamespace)
+}
+
 func TestFilterExportToNamespacesVS(t *testing.T) {
 	assert := assert.New(t)
 	conf := config.NewConfig()
@@ -116,6 +157,9 @@ func mockMultiNamespaceGatewaysValidationService() IstioValidationsService {
 	k8s.On("GetMeshPolicies", mock.AnythingOfType("string")).Return(fakeMeshPolicies(), nil)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "peerauthentications", "").Return(fakePolicies(), nil)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "requestauthentications", "").Return([]kubernetes.IstioObject{}, nil)
+	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "envoyfilters", "").Return([]kubernetes.IstioObject{}, nil)
+	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "workloadentries", "").Return([]kubernetes.IstioObject{}, nil)
+	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "workloadgroups", "").Return([]kubernetes.IstioObject{}, nil)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "clusterrbacconfigs", "").Return([]kubernetes.IstioObject{}, nil)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "authorizationpolicies", "").Return([]kubernetes.IstioObject{}, nil)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "servicerolebindings", "").Return([]kubernetes.IstioObject{}, nil)
@@ -130,6 +174,9 @@ func mockCombinedValidationService(istioObjects *kubernetes.IstioDetails, servic
 	k8s := new(kubetest.K8SClientMock)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "sidecars", "").Return(istioObjects.Sidecars, nil)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "requestauthentications", "").Return(istioObjects.RequestAuthentications, nil)
+	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "envoyfilters", "").Return(istioObjects.EnvoyFilters, nil)
+	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "workloadentries", "").Return(istioObjects.WorkloadEntries, nil)
+	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "workloadgroups", "").Return(istioObjects.WorkloadGroups, nil)
 	k8s.On("GetServices", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string")).Return(fakeCombinedServices(services), nil)
 	k8s.On("GetDeployments", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(FakeDepSyncedWithRS(), nil)
 	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), "virtualservices", "").Return(fakeCombinedIstioDetails().VirtualServices, nil)
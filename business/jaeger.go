@@ -32,6 +32,11 @@ func (in *JaegerService) client() (jaeger.ClientInterface, error) {
 	return in.jaeger, in.loaderErr
 }
 
+// Client returns the Jaeger client, for callers requiring direct access to the traces (e.g. graph generation)
+func (in *JaegerService) Client() (jaeger.ClientInterface, error) {
+	return in.client()
+}
+
 func (in *JaegerService) getFilteredSpans(ns, app string, query models.TracingQuery, filter SpanFilter) ([]jaeger.JaegerSpan, error) {
 	r, err := in.GetAppTraces(ns, app, query)
 	if err != nil {
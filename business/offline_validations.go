@@ -0,0 +1,175 @@
+package business
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+// offlineNamespace holds the objects of a namespace loaded from manifests
+type offlineNamespace struct {
+	istioObjects    map[string][]kubernetes.IstioObject // keyed by kind
+	services        []core_v1.Service
+	serviceAccounts []string
+	workloads       models.WorkloadList
+}
+
+// ValidateManifests runs the Istio config checkers against objects loaded from manifests (see
+// kubernetes.ParseManifests) instead of the objects of a cluster. The manifests must hold everything the
+// checkers rely on: the Istio config, but also the Services and the workloads (Deployments, StatefulSets and
+// DaemonSets) they refer to. Objects without a namespace are set in defaultNamespace. Unlike GetValidations
+// nothing is known about the service registry, so hosts can only be matched to Services and ServiceEntries.
+// The service accounts of a namespace are only validated when the manifests define ServiceAccounts in it.
+// The Gateway credentials are not validated, the Secrets are not loaded from the manifests.
+func ValidateManifests(objects []kubernetes.GenericIstioObject, defaultNamespace string) (models.IstioValidations, error) {
+	namespaces := make(map[string]*offlineNamespace)
+	getNamespace := func(name string) *offlineNamespace {
+		ns, ok := namespaces[name]
+		if !ok {
+			ns = &offlineNamespace{
+				istioObjects: make(map[string][]kubernetes.IstioObject),
+				workloads:    models.WorkloadList{Namespace: models.Namespace{Name: name}, Workloads: []models.WorkloadListItem{}},
+			}
+			namespaces[name] = ns
+		}
+		return ns
+	}
+	// the mesh-wide config is always looked up in the Istio namespace
+	getNamespace(config.Get().IstioNamespace)
+
+	for i := range objects {
+		object := objects[i].DeepCopyIstioObject().(*kubernetes.GenericIstioObject)
+		if object.Kind == "Namespace" {
+			getNamespace(object.Name)
+			continue
+		}
+		if object.Namespace == "" {
+			object.Namespace = defaultNamespace
+		}
+		ns := getNamespace(object.Namespace)
+
+		workload := &models.Workload{}
+		switch object.Kind {
+		case kubernetes.AuthorizationPoliciesType, kubernetes.DestinationRuleType, kubernetes.EnvoyFilterType, kubernetes.GatewayType, kubernetes.PeerAuthenticationsType,
+			kubernetes.RequestAuthenticationsType, kubernetes.ServiceEntryType, kubernetes.SidecarType, kubernetes.VirtualServiceType,
+			kubernetes.WorkloadEntryType, kubernetes.WorkloadGroupType:
+			ns.istioObjects[object.Kind] = append(ns.istioObjects[object.Kind], object)
+			continue
+		case kubernetes.ServiceType:
+			service := core_v1.Service{}
+			if err := convertManifest(object, &service); err != nil {
+				return nil, err
+			}
+			ns.services = append(ns.services, service)
+			continue
+		case "ServiceAccount":
+			ns.serviceAccounts = append(ns.serviceAccounts, object.Name)
+			continue
+		case kubernetes.DeploymentType:
+			deployment := apps_v1.Deployment{}
+			if err := convertManifest(object, &deployment); err != nil {
+				return nil, err
+			}
+			workload.ParseDeployment(&deployment)
+		case kubernetes.StatefulSetType:
+			statefulSet := apps_v1.StatefulSet{}
+			if err := convertManifest(object, &statefulSet); err != nil {
+				return nil, err
+			}
+			workload.ParseStatefulSet(&statefulSet)
+		case kubernetes.DaemonSetType:
+			daemonSet := apps_v1.DaemonSet{}
+			if err := convertManifest(object, &daemonSet); err != nil {
+				return nil, err
+			}
+			workload.ParseDaemonSet(&daemonSet)
+		default:
+			// not used by the checkers
+			continue
+		}
+		item := models.WorkloadListItem{}
+		item.ParseWorkload(workload)
+		ns.workloads.Workloads = append(ns.workloads.Workloads, item)
+	}
+
+	names := make([]string, 0, len(namespaces))
+	for name := range namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	clusterNamespaces := models.Namespaces{}
+	workloadsPerNamespace := make(map[string]models.WorkloadList)
+	gatewaysPerNamespace := [][]kubernetes.IstioObject{}
+	allDestinationRules := []kubernetes.IstioObject{}
+	for _, name := range names {
+		ns := namespaces[name]
+		clusterNamespaces = append(clusterNamespaces, models.Namespace{Name: name})
+		workloadsPerNamespace[name] = ns.workloads
+		gatewaysPerNamespace = append(gatewaysPerNamespace, ns.istioObjects[kubernetes.GatewayType])
+		allDestinationRules = append(allDestinationRules, ns.istioObjects[kubernetes.DestinationRuleType]...)
+	}
+
+	in := IstioValidationsService{}
+	validations := models.IstioValidations{}
+	for _, name := range names {
+		ns := namespaces[name]
+		istioDetails := kubernetes.IstioDetails{
+			DestinationRules:       ns.istioObjects[kubernetes.DestinationRuleType],
+			EnvoyFilters:           ns.istioObjects[kubernetes.EnvoyFilterType],
+			Gateways:               ns.istioObjects[kubernetes.GatewayType],
+			RequestAuthentications: ns.istioObjects[kubernetes.RequestAuthenticationsType],
+			ServiceEntries:         ns.istioObjects[kubernetes.ServiceEntryType],
+			Sidecars:               ns.istioObjects[kubernetes.SidecarType],
+			VirtualServices:        ns.istioObjects[kubernetes.VirtualServiceType],
+			WorkloadEntries:        ns.istioObjects[kubernetes.WorkloadEntryType],
+			WorkloadGroups:         ns.istioObjects[kubernetes.WorkloadGroupType],
+		}
+		exportedResources := kubernetes.ExportedResources{}
+		for _, other := range names {
+			if other == name {
+				continue
+			}
+			otherObjects := namespaces[other].istioObjects
+			vsList := otherObjects[kubernetes.VirtualServiceType]
+			exportedResources.VirtualServices = append(exportedResources.VirtualServices, *in.filterExportToNamespacesIstioObjects(name, &vsList)...)
+			drList := otherObjects[kubernetes.DestinationRuleType]
+			exportedResources.DestinationRules = append(exportedResources.DestinationRules, *in.filterExportToNamespacesIstioObjects(name, &drList)...)
+			seList := otherObjects[kubernetes.ServiceEntryType]
+			exportedResources.ServiceEntries = append(exportedResources.ServiceEntries, *in.filterExportToNamespacesIstioObjects(name, &seList)...)
+		}
+		mtlsDetails := kubernetes.MTLSDetails{
+			DestinationRules:        allDestinationRules,
+			MeshPeerAuthentications: namespaces[config.Get().IstioNamespace].istioObjects[kubernetes.PeerAuthenticationsType],
+			PeerAuthentications:     ns.istioObjects[kubernetes.PeerAuthenticationsType],
+			// auto mTLS is enabled by default since Istio 1.5, the mesh config is not part of the manifests
+			EnabledAutoMtls: true,
+		}
+		rbacDetails := kubernetes.RBACDetails{
+			AuthorizationPolicies: ns.istioObjects[kubernetes.AuthorizationPoliciesType],
+		}
+
+		objectCheckers := in.getAllObjectCheckers(name, istioDetails, exportedResources, ns.services, workloadsPerNamespace, ns.workloads, gatewaysPerNamespace, mtlsDetails, rbacDetails, clusterNamespaces, nil, "", ns.serviceAccounts, nil)
+		validations.MergeValidations(runObjectCheckers(objectCheckers))
+	}
+	return validations, nil
+}
+
+// convertManifest converts a manifest object to its Kubernetes type, e.g. core_v1.Service
+func convertManifest(object *kubernetes.GenericIstioObject, typed interface{}) error {
+	b, err := json.Marshal(object)
+	if err == nil {
+		err = json.Unmarshal(b, typed)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s [%s/%s]: %v", object.Kind, object.Namespace, object.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package business
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+func loadOfflineManifests(t *testing.T, file string) []kubernetes.GenericIstioObject {
+	manifests, err := ioutil.ReadFile("../tests/data/validations/offline/" + file)
+	assert.NoError(t, err)
+	objects, err := kubernetes.ParseManifests(manifests)
+	assert.NoError(t, err)
+	return objects
+}
+
+func TestValidateManifests(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	validations, err := ValidateManifests(loadOfflineManifests(t, "bookinfo.yaml"), "default")
+	assert.NoError(err)
+
+	vs, ok := validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "bookinfo", Name: "reviews"}]
+	assert.True(ok)
+	assert.False(vs.Valid)
+	codes := []string{}
+	for _, check := range vs.Checks {
+		codes = append(codes, check.Code)
+	}
+	assert.ElementsMatch([]string{"KIA1101", "KIA1107"}, codes)
+
+	// the v1 subset matches the Deployment labels
+	dr, ok := validations[models.IstioValidationKey{ObjectType: "destinationrule", Namespace: "bookinfo", Name: "reviews"}]
+	assert.True(ok)
+	assert.Equal(1, len(dr.Checks))
+	assert.Equal("spec/subsets[1]", dr.Checks[0].Path)
+}
+
+func TestValidateManifestsDefaultNamespace(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	validations, err := ValidateManifests(loadOfflineManifests(t, "valid.yaml"), "bookinfo")
+	assert.NoError(err)
+
+	vs, ok := validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "bookinfo", Name: "ratings"}]
+	assert.True(ok)
+	assert.True(vs.Valid)
+	assert.Empty(vs.Checks)
+
+	// the objects are not modified
+	objects := loadOfflineManifests(t, "valid.yaml")
+	_, err = ValidateManifests(objects, "bookinfo")
+	assert.NoError(err)
+	assert.Equal("", objects[0].Namespace)
+}
+
+func TestValidateManifestsWorkloadEntries(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	validations, err := ValidateManifests(loadOfflineManifests(t, "vms.yaml"), "default")
+	assert.NoError(err)
+
+	details, ok := validations[models.IstioValidationKey{ObjectType: "workloadentry", Namespace: "vms", Name: "details-vm"}]
+	assert.True(ok)
+	assert.True(details.Valid)
+	assert.Equal(1, len(details.Checks))
+	assert.Equal("KIA1301", details.Checks[0].Code)
+
+	// no Service selecting the ratings labels, and the ServiceAccount is not defined
+	ratings, ok := validations[models.IstioValidationKey{ObjectType: "workloadentry", Namespace: "vms", Name: "ratings-vm"}]
+	assert.True(ok)
+	assert.False(ratings.Valid)
+	codes := []string{}
+	for _, check := range ratings.Checks {
+		codes = append(codes, check.Code)
+	}
+	assert.ElementsMatch([]string{"KIA1301", "KIA1302", "KIA0006"}, codes)
+}
@@ -0,0 +1,345 @@
+package business
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/prometheus"
+)
+
+// SLO kinds, the entities an SLO applies to
+const (
+	SLOKindApp      = "app"
+	SLOKindService  = "service"
+	SLOKindWorkload = "workload"
+)
+
+const defaultSLOWindow = "30d"
+
+// sloBurnWindow is a multi-window burn rate: it alerts when the budget consumed over both the long and the short
+// windows would exceed the budget fraction over the long window. The windows and fractions are the ones
+// recommended by the Google SRE workbook for a 30d objective window, the thresholds scale with the window.
+type sloBurnWindow struct {
+	budget float64
+	long   model.Duration
+	short  model.Duration
+}
+
+var sloBurnWindows = []sloBurnWindow{
+	{budget: 0.02, long: model.Duration(time.Hour), short: model.Duration(5 * time.Minute)},
+	{budget: 0.05, long: model.Duration(6 * time.Hour), short: model.Duration(30 * time.Minute)},
+	{budget: 0.1, long: model.Duration(24 * time.Hour), short: model.Duration(2 * time.Hour)},
+	{budget: 0.1, long: model.Duration(72 * time.Hour), short: model.Duration(6 * time.Hour)},
+}
+
+var sloStatusOrder = map[string]int{
+	models.SLOStatusNoData:    0,
+	models.SLOStatusHealthy:   1,
+	models.SLOStatusBurning:   2,
+	models.SLOStatusExhausted: 3,
+}
+
+// GetSLOStatus returns the status of the service level objectives of an app, service or workload, nil if
+// none is defined. See getSLO for the SLO definitions.
+func (in *HealthService) GetSLOStatus(namespace, kind, name string, queryTime time.Time) (*models.SLOStatus, error) {
+	var annotations map[string]string
+	switch kind {
+	case SLOKindApp:
+		// apps can't be annotated
+	case SLOKindService:
+		svc, err := in.businessLayer.Svc.getService(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		annotations = svc.Annotations
+	case SLOKindWorkload:
+		w, err := fetchWorkload(in.businessLayer, namespace, name, "")
+		if err != nil {
+			return nil, err
+		}
+		annotations = w.HealthAnnotations
+	default:
+		return nil, fmt.Errorf("invalid SLO kind [%s]", kind)
+	}
+	return in.getSLOStatus(namespace, kind, name, annotations, queryTime)
+}
+
+// GetNamespaceSLOStatus returns the status of the service level objectives of the apps, services or workloads
+// of the namespace, limited to the ones with an SLO definition.
+func (in *HealthService) GetNamespaceSLOStatus(namespace, kind string, queryTime time.Time) (models.NamespaceSLOStatus, error) {
+	annotations := make(map[string]map[string]string)
+	switch kind {
+	case SLOKindApp:
+		appEntities, err := fetchNamespaceApps(in.businessLayer, namespace, "")
+		if err != nil {
+			return nil, err
+		}
+		for app := range appEntities {
+			if app != "" {
+				annotations[app] = nil
+			}
+		}
+	case SLOKindService:
+		services, err := in.getNamespaceServices(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range services {
+			annotations[service.Name] = service.Annotations
+		}
+	case SLOKindWorkload:
+		ws, err := fetchWorkloads(in.businessLayer, namespace, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range ws {
+			annotations[w.Name] = w.HealthAnnotations
+		}
+	default:
+		return nil, fmt.Errorf("invalid SLO kind [%s]", kind)
+	}
+
+	result := make(models.NamespaceSLOStatus)
+	for name, nameAnnotations := range annotations {
+		status, err := in.getSLOStatus(namespace, kind, name, nameAnnotations, queryTime)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			result[name] = status
+		}
+	}
+	return result, nil
+}
+
+// getSLOStatus computes the SLO status from the request counts over the SLO window and the burn rate windows
+func (in *HealthService) getSLOStatus(namespace, kind, name string, annotations map[string]string, queryTime time.Time) (*models.SLOStatus, error) {
+	slo, source := getSLO(namespace, kind, name, annotations)
+	if slo == nil {
+		return nil, nil
+	}
+	window, _ := model.ParseDuration(slo.Window)
+
+	labels := sloLabels(namespace, kind, name)
+	latencyThreshold := 0.0
+	if slo.Latency > 0 {
+		latencyThreshold = slo.LatencyThreshold
+	}
+	// several burn windows may share an interval
+	counts := make(map[model.Duration]prometheus.SLOCounts)
+	fetch := func(interval model.Duration) (prometheus.SLOCounts, error) {
+		if c, ok := counts[interval]; ok {
+			return c, nil
+		}
+		c, err := in.prom.GetSLOCounts(labels, interval.String(), latencyThreshold, queryTime)
+		if err != nil {
+			return c, err
+		}
+		counts[interval] = c
+		return c, nil
+	}
+
+	status := &models.SLOStatus{
+		Objectives: []models.SLOObjectiveStatus{},
+		Status:     models.SLOStatusNoData,
+	}
+	objectives := []struct {
+		bad       func(prometheus.SLOCounts) float64
+		target    float64
+		threshold float64
+		objective string
+	}{
+		{bad: func(c prometheus.SLOCounts) float64 { return c.Errors }, target: slo.Availability, objective: models.SLOAvailability},
+		{bad: func(c prometheus.SLOCounts) float64 { return c.Slow }, target: slo.Latency, threshold: slo.LatencyThreshold, objective: models.SLOLatency},
+	}
+	for _, o := range objectives {
+		if o.target <= 0 {
+			continue
+		}
+		budget := 1 - o.target/100
+
+		c, err := fetch(window)
+		if err != nil {
+			return nil, err
+		}
+		objective := models.SLOObjectiveStatus{
+			BurnRates:            []models.SLOBurnRate{},
+			ErrorBudgetRemaining: 100,
+			SLI:                  100,
+			Source:               source,
+			Status:               models.SLOStatusNoData,
+			Target:               o.target,
+			Threshold:            o.threshold,
+			Type:                 o.objective,
+			Window:               window.String(),
+		}
+		if c.Total > 0 {
+			ratio := sloRatio(o.bad(c), c.Total)
+			objective.SLI = 100 * (1 - ratio)
+			objective.ErrorBudgetRemaining = 100 * (1 - ratio/budget)
+			objective.Status = models.SLOStatusHealthy
+		}
+
+		for _, bw := range sloBurnWindows {
+			// a burn window is meaningless unless it is shorter than the objective window
+			if bw.long >= window {
+				continue
+			}
+			long, err := fetch(bw.long)
+			if err != nil {
+				return nil, err
+			}
+			short, err := fetch(bw.short)
+			if err != nil {
+				return nil, err
+			}
+			burnRate := models.SLOBurnRate{
+				LongRate:    sloRatio(o.bad(long), long.Total) / budget,
+				LongWindow:  bw.long.String(),
+				ShortRate:   sloRatio(o.bad(short), short.Total) / budget,
+				ShortWindow: bw.short.String(),
+				Threshold:   bw.budget * float64(window) / float64(bw.long),
+			}
+			burnRate.Alerting = burnRate.LongRate > burnRate.Threshold && burnRate.ShortRate > burnRate.Threshold
+			if burnRate.Alerting && objective.Status == models.SLOStatusHealthy {
+				objective.Status = models.SLOStatusBurning
+			}
+			objective.BurnRates = append(objective.BurnRates, burnRate)
+		}
+		if objective.Status != models.SLOStatusNoData && objective.ErrorBudgetRemaining <= 0 {
+			objective.Status = models.SLOStatusExhausted
+		}
+
+		if sloStatusOrder[objective.Status] > sloStatusOrder[status.Status] {
+			status.Status = objective.Status
+		}
+		status.Objectives = append(status.Objectives, objective)
+	}
+	return status, nil
+}
+
+// getSLO returns the SLO of an app, service or workload, and its source. The health.kiali.io/slo annotation
+// takes precedence over the health_config.slo entries, the first matching entry is used. Invalid definitions
+// are ignored. The result is nil if there is no (valid) SLO.
+func getSLO(namespace, kind, name string, annotations map[string]string) (*config.SLO, string) {
+	if annotation, ok := annotations[string(models.SLOHealthAnnotation)]; ok {
+		slo, err := parseSLOAnnotation(annotation)
+		if err == nil {
+			err = validateSLO(&slo)
+		}
+		if err == nil {
+			return &slo, models.SLOSourceAnnotation
+		}
+		log.Warningf("Ignoring invalid %s annotation of %s [%s/%s]: %v", models.SLOHealthAnnotation, kind, namespace, name, err)
+	}
+
+	for _, slo := range config.Get().HealthConfig.SLO {
+		if !sloMatches(slo.Namespace, namespace) || !sloMatches(slo.Kind, kind) || !sloMatches(slo.Name, name) {
+			continue
+		}
+		if err := validateSLO(&slo); err != nil {
+			log.Warningf("Ignoring invalid health_config.slo entry for %s [%s/%s]: %v", kind, namespace, name, err)
+			continue
+		}
+		return &slo, models.SLOSourceConfig
+	}
+	return nil, ""
+}
+
+// parseSLOAnnotation parses a health.kiali.io/slo annotation, a comma separated list of key=value, with the
+// keys of the health_config.slo entries. e.g. "availability=99.9,latency=99,latencyThreshold=500,window=28d"
+func parseSLOAnnotation(annotation string) (config.SLO, error) {
+	slo := config.SLO{}
+	for _, field := range strings.Split(annotation, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return slo, fmt.Errorf("invalid field [%s], expecting key=value", field)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		var err error
+		switch key {
+		case "availability":
+			slo.Availability, err = strconv.ParseFloat(value, 64)
+		case "latency":
+			slo.Latency, err = strconv.ParseFloat(value, 64)
+		case "latencyThreshold":
+			slo.LatencyThreshold, err = strconv.ParseFloat(value, 64)
+		case "window":
+			slo.Window = value
+		default:
+			err = fmt.Errorf("unknown key [%s]", key)
+		}
+		if err != nil {
+			return slo, err
+		}
+	}
+	return slo, nil
+}
+
+// validateSLO checks the objectives, and sets the default window
+func validateSLO(slo *config.SLO) error {
+	if slo.Window == "" {
+		slo.Window = defaultSLOWindow
+	}
+	if window, err := model.ParseDuration(slo.Window); err != nil || window <= 0 {
+		return fmt.Errorf("invalid window [%s]", slo.Window)
+	}
+	if slo.Availability == 0 && slo.Latency == 0 {
+		return fmt.Errorf("no availability or latency objective")
+	}
+	if slo.Availability < 0 || slo.Availability >= 100 {
+		return fmt.Errorf("invalid availability [%v], expecting a percentage lower than 100", slo.Availability)
+	}
+	if slo.Latency < 0 || slo.Latency >= 100 {
+		return fmt.Errorf("invalid latency [%v], expecting a percentage lower than 100", slo.Latency)
+	}
+	if slo.Latency > 0 && slo.LatencyThreshold <= 0 {
+		return fmt.Errorf("a latency objective requires a latencyThreshold")
+	}
+	return nil
+}
+
+// sloMatches returns true if the expression, a regular expression, matches the whole value. An empty
+// expression matches anything.
+func sloMatches(expression, value string) bool {
+	if expression == "" {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + expression + ")$")
+	if err != nil {
+		log.Warningf("Ignoring invalid health_config.slo expression [%s]: %v", expression, err)
+		return false
+	}
+	return re.MatchString(value)
+}
+
+// sloLabels returns the Prometheus labels selecting the requests received by an app, service or workload
+func sloLabels(namespace, kind, name string) string {
+	switch kind {
+	case SLOKindApp:
+		return fmt.Sprintf(`destination_workload_namespace="%s",destination_app="%s"`, namespace, name)
+	case SLOKindService:
+		return fmt.Sprintf(`destination_service_namespace="%s",destination_service_name="%s"`, namespace, name)
+	default:
+		return fmt.Sprintf(`destination_workload_namespace="%s",destination_workload="%s"`, namespace, name)
+	}
+}
+
+// sloRatio returns the ratio of bad requests, 0 if there is no request
+func sloRatio(bad, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	if bad > total {
+		return 1
+	}
+	return bad / total
+}
@@ -0,0 +1,159 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	osproject_v1 "github.com/openshift/api/project/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/prometheus"
+	"github.com/kiali/kiali/prometheus/prometheustest"
+)
+
+func TestParseSLOAnnotation(t *testing.T) {
+	assert := assert.New(t)
+
+	slo, err := parseSLOAnnotation("availability=99.9, latency=99,latencyThreshold=250,window=28d")
+	assert.NoError(err)
+	assert.Equal(config.SLO{Availability: 99.9, Latency: 99, LatencyThreshold: 250, Window: "28d"}, slo)
+
+	_, err = parseSLOAnnotation("availability")
+	assert.Error(err)
+	_, err = parseSLOAnnotation("availability=high")
+	assert.Error(err)
+	_, err = parseSLOAnnotation("durability=99")
+	assert.Error(err)
+}
+
+func TestGetSLO(t *testing.T) {
+	assert := assert.New(t)
+
+	conf := config.NewConfig()
+	conf.HealthConfig.SLO = []config.SLO{
+		{Namespace: "bookinfo", Kind: "service", Name: "reviews|ratings", Latency: 99},
+		{Namespace: "bookinfo", Kind: "service", Name: "reviews|ratings", Availability: 99.5},
+		{Kind: "app", Availability: 99, Window: "7d"},
+	}
+	config.Set(conf)
+
+	// the first entry is invalid, a latency objective requires a threshold
+	slo, source := getSLO("bookinfo", SLOKindService, "reviews", nil)
+	assert.Equal(&config.SLO{Namespace: "bookinfo", Kind: "service", Name: "reviews|ratings", Availability: 99.5, Window: "30d"}, slo)
+	assert.Equal(models.SLOSourceConfig, source)
+
+	// the expressions match the whole value
+	slo, _ = getSLO("bookinfo", SLOKindService, "reviews-v1", nil)
+	assert.Nil(slo)
+	slo, _ = getSLO("bookinfo", SLOKindWorkload, "reviews", nil)
+	assert.Nil(slo)
+	slo, _ = getSLO("tutorial", SLOKindApp, "reviews", nil)
+	assert.Equal("7d", slo.Window)
+
+	// the annotation takes precedence, unless invalid
+	slo, source = getSLO("bookinfo", SLOKindService, "reviews", map[string]string{"health.kiali.io/slo": "availability=99.99"})
+	assert.Equal(&config.SLO{Availability: 99.99, Window: "30d"}, slo)
+	assert.Equal(models.SLOSourceAnnotation, source)
+	slo, source = getSLO("bookinfo", SLOKindService, "reviews", map[string]string{"health.kiali.io/slo": "availability=100"})
+	assert.Equal(99.5, slo.Availability)
+	assert.Equal(models.SLOSourceConfig, source)
+}
+
+func TestGetServiceHealthSLOStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	k8s := new(kubetest.K8SClientMock)
+	prom := new(prometheustest.PromClientMock)
+	config.Set(config.NewConfig())
+
+	queryTime := time.Date(2017, 01, 15, 0, 0, 0, 0, time.UTC)
+	service := &core_v1.Service{ObjectMeta: meta_v1.ObjectMeta{
+		Name:        "httpbin",
+		Annotations: map[string]string{"health.kiali.io/slo": "availability=99,window=1d"},
+	}}
+	prom.MockServiceRequestRates("ns", "httpbin", serviceRates)
+	k8s.On("IsOpenShift").Return(true)
+	k8s.On("GetProject", mock.AnythingOfType("string")).Return(&osproject_v1.Project{}, nil)
+	k8s.On("GetService", "ns", "httpbin").Return(service, nil)
+
+	labels := `destination_service_namespace="ns",destination_service_name="httpbin"`
+	counts := func(interval string, total, errors float64) {
+		prom.On("GetSLOCounts", labels, interval, 0.0, queryTime).Return(prometheus.SLOCounts{Errors: errors, Total: total}, nil)
+	}
+	counts("1d", 1000, 5)
+	counts("1h", 100, 10)
+	counts("5m", 10, 1)
+	counts("6h", 500, 0)
+	counts("30m", 50, 0)
+
+	hs := HealthService{k8s: k8s, prom: prom, businessLayer: NewWithBackends(k8s, prom, nil)}
+	health, err := hs.GetServiceHealth("ns", "httpbin", "1m", queryTime)
+	assert.NoError(err)
+
+	// the windows longer than the objective window are skipped
+	prom.AssertNumberOfCalls(t, "GetSLOCounts", 5)
+
+	status := health.SLOStatus
+	assert.NotNil(status)
+	assert.Equal(models.SLOStatusBurning, status.Status)
+	assert.Equal(1, len(status.Objectives))
+
+	objective := status.Objectives[0]
+	assert.Equal(models.SLOAvailability, objective.Type)
+	assert.Equal(models.SLOSourceAnnotation, objective.Source)
+	assert.Equal("1d", objective.Window)
+	assert.InDelta(99.5, objective.SLI, 0.0001)
+	assert.InDelta(50.0, objective.ErrorBudgetRemaining, 0.0001)
+
+	assert.Equal(2, len(objective.BurnRates))
+	fast := objective.BurnRates[0]
+	assert.Equal("1h", fast.LongWindow)
+	assert.Equal("5m", fast.ShortWindow)
+	assert.InDelta(10.0, fast.LongRate, 0.0001)
+	assert.InDelta(10.0, fast.ShortRate, 0.0001)
+	assert.InDelta(0.48, fast.Threshold, 0.0001)
+	assert.True(fast.Alerting)
+	assert.False(objective.BurnRates[1].Alerting)
+}
+
+func TestGetSLOStatusExhausted(t *testing.T) {
+	assert := assert.New(t)
+
+	prom := new(prometheustest.PromClientMock)
+	conf := config.NewConfig()
+	conf.HealthConfig.SLO = []config.SLO{{Availability: 99.9, Latency: 95, LatencyThreshold: 500, Window: "1h"}}
+	config.Set(conf)
+
+	queryTime := time.Date(2017, 01, 15, 0, 0, 0, 0, time.UTC)
+	labels := `destination_workload_namespace="ns",destination_workload="reviews-v1"`
+	prom.On("GetSLOCounts", labels, "1h", 500.0, queryTime).Return(prometheus.SLOCounts{Errors: 1, Slow: 1, Total: 100}, nil)
+
+	hs := HealthService{prom: prom}
+	status, err := hs.getSLOStatus("ns", SLOKindWorkload, "reviews-v1", nil, queryTime)
+	assert.NoError(err)
+
+	assert.Equal(models.SLOStatusExhausted, status.Status)
+	assert.Equal(2, len(status.Objectives))
+	assert.Equal(models.SLOStatusExhausted, status.Objectives[0].Status)
+	assert.InDelta(-900.0, status.Objectives[0].ErrorBudgetRemaining, 0.0001)
+	assert.Equal(models.SLOLatency, status.Objectives[1].Type)
+	assert.Equal(500.0, status.Objectives[1].Threshold)
+	assert.Equal(models.SLOStatusHealthy, status.Objectives[1].Status)
+	assert.InDelta(80.0, status.Objectives[1].ErrorBudgetRemaining, 0.0001)
+	assert.Empty(status.Objectives[1].BurnRates)
+
+	// no traffic
+	prom = new(prometheustest.PromClientMock)
+	prom.On("GetSLOCounts", labels, "1h", 500.0, queryTime).Return(prometheus.SLOCounts{}, nil)
+	hs = HealthService{prom: prom}
+	status, err = hs.getSLOStatus("ns", SLOKindWorkload, "reviews-v1", nil, queryTime)
+	assert.NoError(err)
+	assert.Equal(models.SLOStatusNoData, status.Status)
+	assert.Equal(100.0, status.Objectives[0].SLI)
+}
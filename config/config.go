@@ -86,6 +86,7 @@ type Server struct {
 	WebRoot                    string `yaml:"web_root,omitempty"`
 	WebHistoryMode             string `yaml:"web_history_mode,omitempty"`
 	WebSchema                  string `yaml:"web_schema,omitempty"`
+	WriteTimeout               int    `yaml:"write_timeout,omitempty"` // in seconds, also bounds streamed responses unable to clear it
 }
 
 // Auth provides authentication data for external services
@@ -114,10 +115,21 @@ type PrometheusConfig struct {
 	// Enable cache for Prometheus queries
 	CacheEnabled bool `yaml:"cache_enabled,omitempty"`
 	// Global cache expiration expressed in seconds
-	CacheExpiration int    `yaml:"cache_expiration,omitempty"`
-	HealthCheckUrl  string `yaml:"health_check_url,omitempty"`
-	IsCore          bool   `yaml:"is_core,omitempty"`
-	URL             string `yaml:"url,omitempty"`
+	CacheExpiration int `yaml:"cache_expiration,omitempty"`
+	// Prometheus endpoints of the remote clusters, keyed by cluster name. When set, queries are sent to URL and
+	// to every cluster endpoint, and the results are merged. Each endpoint must be a distinct Prometheus, other
+	// than URL, or its series would be counted twice.
+	Clusters       map[string]PrometheusClusterConfig `yaml:"clusters,omitempty"`
+	HealthCheckUrl string                             `yaml:"health_check_url,omitempty"`
+	IsCore         bool                               `yaml:"is_core,omitempty"`
+	URL            string                             `yaml:"url,omitempty"`
+}
+
+// PrometheusClusterConfig describes the Prometheus endpoint of a remote cluster. When Auth is not set, the
+// Auth of the PrometheusConfig is used.
+type PrometheusClusterConfig struct {
+	Auth Auth   `yaml:"auth,omitempty"`
+	URL  string `yaml:"url"`
 }
 
 // CustomDashboardsConfig describes configuration specific to Custom Dashboards
@@ -317,6 +329,49 @@ type DeploymentConfig struct {
 	ViewOnlyMode         bool     `yaml:"view_only_mode,omitempty"`
 }
 
+// GraphConfig describes configuration of the server-side graph generation
+type GraphConfig struct {
+	// Cache duration expressed in seconds. A cached namespace graph is reused for requests with a query time
+	// up to CacheDuration after its own query time.
+	CacheDuration int `yaml:"cache_duration,omitempty"`
+	// Enable cache for namespace graphs, shared by all graph requests
+	CacheEnabled bool `yaml:"cache_enabled,omitempty"`
+	// Cache expiration expressed in seconds, a cached namespace graph is removed after CacheExpiration
+	CacheExpiration int `yaml:"cache_expiration,omitempty"`
+	// External appenders, HTTP webhooks decorating the namespace graphs
+	ExternalAppenders []GraphExternalAppender `yaml:"external_appenders,omitempty"`
+	// The maximum number of namespace traffic maps built concurrently for a single graph request
+	NamespaceMaxConcurrent int `yaml:"namespace_max_concurrent,omitempty"`
+	// Where saved graph snapshots are stored
+	Snapshot GraphSnapshotConfig `yaml:"snapshot,omitempty"`
+	// The maximum number of concurrent graph update streams, unlimited when not positive
+	StreamMaxConcurrent int `yaml:"stream_max_concurrent,omitempty"`
+	// The maximum duration of a graph update stream expressed in seconds, clients then reconnect. Unlimited when
+	// not positive.
+	StreamMaxDuration int `yaml:"stream_max_duration,omitempty"`
+}
+
+// GraphExternalAppender registers an external graph appender, an HTTP webhook receiving the traffic map of
+// a namespace and returning the data to add to its nodes and edges. The appender only runs when its Name is
+// requested, it is not part of the default appenders. The Name must not be the name of a built-in appender,
+// such an appender is ignored.
+// Timeout is expressed in seconds, the graph is generated without the external data when the webhook fails.
+type GraphExternalAppender struct {
+	Auth    Auth   `yaml:"auth,omitempty"`
+	Name    string `yaml:"name"`
+	Timeout int    `yaml:"timeout,omitempty"`
+	URL     string `yaml:"url"`
+}
+
+// GraphSnapshotConfig describes the storage of saved graph snapshots.
+// storage options : none (default, snapshots disabled) | directory | configmap
+// The directory storage writes the snapshots to Directory, typically a mounted PVC. The configmap
+// storage saves every snapshot as a ConfigMap in the Kiali deployment namespace.
+type GraphSnapshotConfig struct {
+	Directory string `yaml:"directory,omitempty"`
+	Storage   string `yaml:"storage,omitempty"`
+}
+
 // GraphFindOption defines a single Graph Find/Hide Option
 type GraphFindOption struct {
 	Description string `yaml:"description,omitempty" json:"description,omitempty"`
@@ -350,7 +405,10 @@ type UIDefaults struct {
 
 // Validations defines default settings configured for the Validations subsystem
 type Validations struct {
-	Ignore []string `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	// The number of days before the expiration of a Gateway certificate a warning is raised, 0 disables the
+	// warning (expired certificates are always reported)
+	CertExpirationWarningDays int      `yaml:"cert_expiration_warning_days,omitempty" json:"certExpirationWarningDays,omitempty"`
+	Ignore                    []string `yaml:"ignore,omitempty" json:"ignore,omitempty"`
 }
 
 // KialiFeatureFlags available from the CR
@@ -378,9 +436,23 @@ type Rate struct {
 	Tolerance []Tolerance `yaml:"tolerance,omitempty" json:"tolerance"`
 }
 
+// SLO config, the service level objectives of the matching services, apps or workloads. Namespace, Kind
+// and Name are regular expressions matching the whole value, empty matches anything. Kind is one of
+// app, service or workload. The objectives apply to the requests received over the window.
+type SLO struct {
+	Namespace        string  `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Kind             string  `yaml:"kind,omitempty" json:"kind,omitempty"`
+	Name             string  `yaml:"name,omitempty" json:"name,omitempty"`
+	Availability     float64 `yaml:"availability,omitempty" json:"availability,omitempty"`          // target percentage of successful requests, e.g. 99.9
+	Latency          float64 `yaml:"latency,omitempty" json:"latency,omitempty"`                    // target percentage of requests faster than the latency threshold
+	LatencyThreshold float64 `yaml:"latency_threshold,omitempty" json:"latencyThreshold,omitempty"` // millis, a bucket boundary of istio_request_duration_milliseconds
+	Window           string  `yaml:"window,omitempty" json:"window,omitempty"`                      // e.g. 28d, defaults to 30d
+}
+
 // HealthConfig rates
 type HealthConfig struct {
 	Rate []Rate `yaml:"rate,omitempty" json:"rate,omitempty"`
+	SLO  []SLO  `yaml:"slo,omitempty" json:"slo,omitempty"`
 }
 
 // Config defines full YAML configuration.
@@ -392,6 +464,7 @@ type Config struct {
 	Deployment               DeploymentConfig                    `yaml:"deployment,omitempty"`
 	Extensions               Extensions                          `yaml:"extensions,omitempty"`
 	ExternalServices         ExternalServices                    `yaml:"external_services,omitempty"`
+	Graph                    GraphConfig                         `yaml:"graph,omitempty"`
 	HealthConfig             HealthConfig                        `yaml:"health_config,omitempty" json:"healthConfig,omitempty"`
 	Identity                 security.Identity                   `yaml:",omitempty"`
 	InCluster                bool                                `yaml:"in_cluster,omitempty"`
@@ -523,6 +596,19 @@ func NewConfig() (c *Config) {
 				WhiteListIstioSystem: []string{"jaeger-query", "istio-ingressgateway"},
 			},
 		},
+		Graph: GraphConfig{
+			// The default UI refresh interval
+			CacheDuration:          15,
+			CacheEnabled:           false,
+			CacheExpiration:        300,
+			NamespaceMaxConcurrent: 5,
+			Snapshot: GraphSnapshotConfig{
+				Directory: "/var/lib/kiali/snapshots",
+				Storage:   "none",
+			},
+			StreamMaxConcurrent: 10,
+			StreamMaxDuration:   600,
+		},
 		IstioLabels: IstioLabels{
 			AppLabelName:       "app",
 			InjectionLabelName: "istio-injection",
@@ -569,7 +655,8 @@ func NewConfig() (c *Config) {
 				RefreshInterval:   "15s",
 			},
 			Validations: Validations{
-				Ignore: make([]string, 0),
+				CertExpirationWarningDays: 30,
+				Ignore:                    make([]string, 0),
 			},
 		},
 		KubernetesConfig: KubernetesConfig{
@@ -597,6 +684,7 @@ func NewConfig() (c *Config) {
 			WebRoot:                    "/",
 			WebHistoryMode:             "browser",
 			WebSchema:                  "",
+			WriteTimeout:               30,
 		},
 	}
 
@@ -666,7 +754,21 @@ func (conf Config) String() (str string) {
 	obf := conf
 	obf.ExternalServices.Grafana.Auth.Obfuscate()
 	obf.ExternalServices.Prometheus.Auth.Obfuscate()
+	if clusters := conf.ExternalServices.Prometheus.Clusters; clusters != nil {
+		obf.ExternalServices.Prometheus.Clusters = make(map[string]PrometheusClusterConfig, len(clusters))
+		for cluster, clusterConfig := range clusters {
+			clusterConfig.Auth.Obfuscate()
+			obf.ExternalServices.Prometheus.Clusters[cluster] = clusterConfig
+		}
+	}
 	obf.ExternalServices.Tracing.Auth.Obfuscate()
+	if appenders := conf.Graph.ExternalAppenders; appenders != nil {
+		obf.Graph.ExternalAppenders = make([]GraphExternalAppender, len(appenders))
+		for i, appender := range appenders {
+			appender.Auth.Obfuscate()
+			obf.Graph.ExternalAppenders[i] = appender
+		}
+	}
 	obf.Identity.Obfuscate()
 	obf.LoginToken.Obfuscate()
 	obf.Auth.OpenId.ClientSecret = "xxx"
@@ -712,6 +814,10 @@ func Unmarshal(yamlString string) (conf *Config, err error) {
 
 	conf.prepareDashboards()
 
+	if err = conf.checkPrometheusClusters(); err != nil {
+		return nil, err
+	}
+
 	// Some config settings (such as sensitive settings like passwords) are overrideable
 	// via environment variables. This allows a user to store sensitive values in secrets
 	// and mount those secrets to environment variables rather than storing them directly
@@ -762,6 +868,27 @@ func Unmarshal(yamlString string) (conf *Config, err error) {
 	return
 }
 
+// checkPrometheusClusters rejects the Prometheus cluster endpoints with the URL of the home Prometheus or of
+// another cluster endpoint
+func (conf *Config) checkPrometheusClusters() error {
+	prom := conf.ExternalServices.Prometheus
+	clusters := make([]string, 0, len(prom.Clusters))
+	for cluster := range prom.Clusters {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	endpoints := map[string]string{strings.TrimSuffix(prom.URL, "/"): "home"}
+	for _, cluster := range clusters {
+		url := strings.TrimSuffix(prom.Clusters[cluster].URL, "/")
+		if other, found := endpoints[url]; found {
+			return fmt.Errorf("prometheus cluster [%s] has the same URL [%s] as [%s]", cluster, url, other)
+		}
+		endpoints[url] = cluster
+	}
+	return nil
+}
+
 // Marshal converts the Config object and returns its YAML string.
 func Marshal(conf *Config) (yamlString string, err error) {
 	yamlBytes, err := yaml.Marshal(&conf)
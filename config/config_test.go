@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 
@@ -67,6 +68,9 @@ func TestSensitiveDataObfuscation(t *testing.T) {
 	conf.ExternalServices.Prometheus.Auth.Username = "my-username"
 	conf.ExternalServices.Prometheus.Auth.Password = "my-password"
 	conf.ExternalServices.Prometheus.Auth.Token = "my-token"
+	conf.ExternalServices.Prometheus.Clusters = map[string]PrometheusClusterConfig{
+		"east": {Auth: Auth{Password: "my-password", Token: "my-token"}, URL: "http://prometheus.east:9090"},
+	}
 	conf.ExternalServices.Tracing.Auth.Username = "my-username"
 	conf.ExternalServices.Tracing.Auth.Password = "my-password"
 	conf.ExternalServices.Tracing.Auth.Token = "my-token"
@@ -85,6 +89,7 @@ func TestSensitiveDataObfuscation(t *testing.T) {
 	assert.Equal(t, "my-username", conf.ExternalServices.Grafana.Auth.Username)
 	assert.Equal(t, "my-password", conf.ExternalServices.Prometheus.Auth.Password)
 	assert.Equal(t, "my-token", conf.ExternalServices.Tracing.Auth.Token)
+	assert.Equal(t, "my-token", conf.ExternalServices.Prometheus.Clusters["east"].Auth.Token)
 	assert.Equal(t, "my-signkey", conf.LoginToken.SigningKey)
 }
 
@@ -213,6 +218,33 @@ func TestError(t *testing.T) {
 	}
 }
 
+func TestPrometheusClustersError(t *testing.T) {
+	yamlString := `
+external_services:
+  prometheus:
+    url: http://prometheus.istio-system:9090
+    clusters:
+      east:
+        url: http://prometheus.east:9090
+      west:
+        url: http://prometheus.istio-system:9090/
+`
+	_, err := Unmarshal(yamlString)
+	if err == nil {
+		t.Errorf("Unmarshal should have failed, the west cluster endpoint is the home Prometheus")
+	}
+
+	_, err = Unmarshal(strings.Replace(yamlString, "prometheus.istio-system:9090/", "prometheus.east:9090", 1))
+	if err == nil {
+		t.Errorf("Unmarshal should have failed, the east and west cluster endpoints are the same Prometheus")
+	}
+
+	_, err = Unmarshal(strings.Replace(yamlString, "prometheus.istio-system:9090/", "prometheus.west:9090", 1))
+	if err != nil {
+		t.Errorf("Failed to unmarshal: %v", err)
+	}
+}
+
 func TestRaces(t *testing.T) {
 
 	wg := sync.WaitGroup{}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/snapshot"
 	"github.com/kiali/kiali/handlers"
 	"github.com/kiali/kiali/jaeger"
 	"github.com/kiali/kiali/models"
@@ -34,7 +35,7 @@ type AggregateValueParam struct {
 	Name string `json:"aggregateValue"`
 }
 
-// swagger:parameters appMetrics appDetails graphApp graphAppVersion appDashboard appSpans appTraces errorTraces
+// swagger:parameters appMetrics appDetails graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies appDashboard appSpans appTraces errorTraces
 type AppParam struct {
 	// The app name (label value).
 	//
@@ -43,7 +44,7 @@ type AppParam struct {
 	Name string `json:"app"`
 }
 
-// swagger:parameters graphAppVersion
+// swagger:parameters graphAppVersion graphAppVersionDependencies
 type AppVersionParam struct {
 	// The app version (label value).
 	//
@@ -52,7 +53,7 @@ type AppVersionParam struct {
 	Name string `json:"version"`
 }
 
-// swagger:parameters graphAggregate graphAggregateByService graphApp graphAppVersion graphService graphWorkload
+// swagger:parameters graphAggregate graphAggregateByService graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphService graphServiceDependencies graphWorkload graphWorkloadDependencies
 type ClusterParam struct {
 	// The cluster name. If not supplied queries/results will not be constrained by cluster.
 	//
@@ -70,7 +71,7 @@ type ContainerParam struct {
 	Name string `json:"container"`
 }
 
-// swagger:parameters istioConfigList workloadList workloadDetails workloadUpdate serviceDetails serviceUpdate appSpans serviceSpans workloadSpans appTraces serviceTraces workloadTraces errorTraces workloadValidations appList serviceMetrics aggregateMetrics appMetrics workloadMetrics istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype serviceList appDetails graphAggregate graphAggregateByService graphApp graphAppVersion graphNamespace graphService graphWorkload namespaceMetrics customDashboard appDashboard serviceDashboard workloadDashboard istioConfigCreate istioConfigCreateSubtype namespaceUpdate namespaceTls podDetails podLogs namespaceValidations getIter8Experiments postIter8Experiments patchIter8Experiments deleteIter8Experiments podProxyDump podProxyResource
+// swagger:parameters istioConfigList workloadList workloadDetails workloadUpdate serviceDetails serviceUpdate appSpans serviceSpans workloadSpans appTraces serviceTraces workloadTraces errorTraces workloadValidations appList serviceMetrics aggregateMetrics appMetrics workloadMetrics istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype serviceList appDetails graphAggregate graphAggregateByService graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespace graphService graphServiceDependencies graphSimulation graphWorkload graphWorkloadDependencies namespaceMetrics customDashboard appDashboard serviceDashboard workloadDashboard istioConfigCreate istioConfigCreateSubtype namespaceUpdate namespaceTls podDetails podLogs namespaceValidations getIter8Experiments postIter8Experiments patchIter8Experiments deleteIter8Experiments podProxyDump podProxyResource
 type NamespaceParam struct {
 	// The namespace name.
 	//
@@ -125,7 +126,7 @@ type ResourceParam struct {
 	Name string `json:"resource"`
 }
 
-// swagger:parameters serviceDetails serviceUpdate serviceMetrics graphService graphAggregateByService serviceDashboard serviceSpans serviceTraces
+// swagger:parameters serviceDetails serviceUpdate serviceMetrics graphService graphServiceDependencies graphAggregateByService serviceDashboard serviceSpans serviceTraces
 type ServiceParam struct {
 	// The service name.
 	//
@@ -171,7 +172,7 @@ type DashboardParam struct {
 	Name string `json:"dashboard"`
 }
 
-// swagger:parameters workloadDetails workloadUpdate workloadValidations workloadMetrics graphWorkload workloadDashboard workloadSpans workloadTraces
+// swagger:parameters workloadDetails workloadUpdate workloadValidations workloadMetrics graphWorkload graphWorkloadDependencies workloadDashboard workloadSpans workloadTraces
 type WorkloadParam struct {
 	// The workload name.
 	//
@@ -180,24 +181,64 @@ type WorkloadParam struct {
 	Name string `json:"workload"`
 }
 
+// swagger:parameters namespaceValidations
+type ValidationFormatParam struct {
+	// The validations format, one of: json (summary) | junit | sarif. Takes precedence over the Accept header.
+	//
+	// in: query
+	// required: false
+	// default: json
+	Name string `json:"format"`
+}
+
 /////////////////////
 // SWAGGER PARAMETERS - GRAPH
 // - keep this alphabetized
 /////////////////////
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphSimulation graphSnapshotSave graphWorkload
+type AnomalyBaselineParam struct {
+	// Used only with anomaly appender. The duration of the baseline time period, which immediately precedes the queried time period.
+	//
+	// in: query
+	// required: false
+	// default: 1h
+	Name string `json:"anomalyBaseline"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphSimulation graphSnapshotSave graphWorkload
+type AnomalyErrorThresholdParam struct {
+	// Used only with anomaly appender. The increase in error percentage, in percentage points, that is anomalous.
+	//
+	// in: query
+	// required: false
+	// default: 5
+	Name string `json:"anomalyErrorThreshold"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphSimulation graphSnapshotSave graphWorkload
+type AnomalyResponseTimeThresholdParam struct {
+	// Used only with anomaly appender. The relative increase in average response time that is anomalous (e.g. 0.5 is 50% slower).
+	//
+	// in: query
+	// required: false
+	// default: 0.5
+	Name string `json:"anomalyResponseTimeThreshold"`
+}
+
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type AppendersParam struct {
-	// Comma-separated list of Appenders to run. Available appenders: [aggregateNode, deadNode, healthConfig, idleNode, istio, responseTime, securityPolicy, serviceEntry, sidecarsCheck, throughput].
+	// Comma-separated list of Appenders to run. Available appenders: [aggregateNode, anomaly, authorizationPolicy, deadNode, healthConfig, idleNode, istio, requestSize, responseSize, responseTime, responseTimePercentiles, rootCause, securityPolicy, serviceEntry, sidecarsCheck, throughput], plus the external appenders registered in the graph.external_appenders config.
 	//
 	// in: query
 	// required: false
-	// default: run all appenders
+	// default: run all appenders (except anomaly, authorizationPolicy, requestSize, responseSize, responseTimePercentiles, rootCause and the external appenders, which must be requested)
 	Name string `json:"appenders"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type BoxByParam struct {
-	// Comma-separated list of desired node boxing. Available boxings: [app, cluster, namespace, none].
+	// Comma-separated list of desired node boxing. Available boxings: [app, cluster, label:<labelName>, namespace, none, workloadGroup]. Label and workloadGroup boxing can not be combined.
 	//
 	// in: query
 	// required: false
@@ -205,7 +246,36 @@ type BoxByParam struct {
 	Name string `json:"boxBy"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphNamespacesDiff
+type CompareTimeParam struct {
+	// Unix time (seconds) of the baseline graph, such that its time range is [compareTime-duration..compareTime]. Default is queryTime-duration.
+	//
+	// in: query
+	// required: false
+	// default: queryTime-duration
+	Name string `json:"compareTime"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphService graphSimulation graphSnapshotSave graphWorkload
+type ConfigVendorParam struct {
+	// Graph config format. Available config vendors: [cytoscape, dot, graphml, jgf].
+	//
+	// in: query
+	// required: false
+	// default: cytoscape
+	Name string `json:"configVendor"`
+}
+
+// swagger:parameters graphAppDependencies graphAppVersionDependencies graphServiceDependencies graphWorkloadDependencies
+type DependenciesNamespacesParam struct {
+	// Comma-separated list of namespaces to analyze in addition to the node namespace. The namespaces must be accessible to the client.
+	//
+	// in: query
+	// required: false
+	Name string `json:"namespaces"`
+}
+
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type DurationGraphParam struct {
 	// Query time-range duration (Golang string duration).
 	//
@@ -215,7 +285,16 @@ type DurationGraphParam struct {
 	Name string `json:"duration"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
+type FindParam struct {
+	// Find expression, using the graph find/hide grammar (e.g. rpt > 100, %error > 5, ns = foo, node = service, mtls, authorization = deny). Matching nodes or edges are flagged with isFind.
+	//
+	// in: query
+	// required: false
+	Name string `json:"find"`
+}
+
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type GraphTypeParam struct {
 	// Graph type. Available graph types: [app, service, versionedApp, workload].
 	//
@@ -225,7 +304,16 @@ type GraphTypeParam struct {
 	Name string `json:"graphType"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
+type HideParam struct {
+	// Hide expression, using the graph find/hide grammar. Matching nodes or edges are removed, along with nodes left without edges.
+	//
+	// in: query
+	// required: false
+	Name string `json:"hide"`
+}
+
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type IncludeIdleEdges struct {
 	// Flag for including edges that have no request traffic for the time period.
 	//
@@ -235,7 +323,7 @@ type IncludeIdleEdges struct {
 	Name string `json:"includeIdleEdges"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphSnapshotSave graphWorkload graphWorkloadDependencies
 type InjectServiceNodes struct {
 	// Flag for injecting the requested service node between source and destination nodes.
 	//
@@ -245,7 +333,7 @@ type InjectServiceNodes struct {
 	Name string `json:"injectServiceNodes"`
 }
 
-// swagger:parameters graphNamespaces
+// swagger:parameters graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphSnapshotSave
 type NamespacesParam struct {
 	// Comma-separated list of namespaces to include in the graph. The namespaces must be accessible to the client.
 	//
@@ -254,7 +342,7 @@ type NamespacesParam struct {
 	Name string `json:"namespaces"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type QueryTimeParam struct {
 	// Unix time (seconds) for query such that time range is [queryTime-duration..queryTime]. Default is now.
 	//
@@ -264,7 +352,7 @@ type QueryTimeParam struct {
 	Name string `json:"queryTime"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type RateGrpcParam struct {
 	// How to calculate gRPC traffic rate. One of: none | received (i.e. response_messages) | requests | sent (i.e. request_messages) | total (i.e. sent+received).
 	//
@@ -274,7 +362,7 @@ type RateGrpcParam struct {
 	Name string `json:"rateGrpc"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type RateHttpParam struct {
 	// How to calculate HTTP traffic rate. One of: none | requests.
 	//
@@ -284,7 +372,7 @@ type RateHttpParam struct {
 	Name string `json:"rateHttp"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type RateTcpParam struct {
 	// How to calculate TCP traffic rate. One of: none | received (i.e. received_bytes) | sent (i.e. sent_bytes) | total (i.e. sent+received).
 	//
@@ -294,7 +382,7 @@ type RateTcpParam struct {
 	Name string `json:"rateTcp"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type ResponseTimeParam struct {
 	// Used only with responseTime appender. One of: avg | 50 | 95 | 99.
 	//
@@ -304,7 +392,7 @@ type ResponseTimeParam struct {
 	Name string `json:"responseTime"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppDependencies graphAppVersion graphAppVersionDependencies graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphService graphServiceDependencies graphSimulation graphSnapshotSave graphWorkload graphWorkloadDependencies
 type ThroughputParam struct {
 	// Used only with throughput appender. One of: request | response.
 	//
@@ -314,6 +402,45 @@ type ThroughputParam struct {
 	Name string `json:"throughput"`
 }
 
+// swagger:parameters graphNamespacesStream
+type RefreshIntervalParam struct {
+	// Time between streamed graph updates (Golang string duration), at least 5s.
+	//
+	// in: query
+	// required: false
+	// default: 15s
+	Name string `json:"refreshInterval"`
+}
+
+// swagger:parameters graphSnapshot graphSnapshotDelete graphSnapshotSave
+type SnapshotParam struct {
+	// The graph snapshot name.
+	//
+	// in: path
+	// required: true
+	Name string `json:"snapshot"`
+}
+
+// swagger:parameters graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphSnapshotSave
+type TimeSeriesParam struct {
+	// Flag for providing a request traffic time series for each node and edge.
+	//
+	// in: query
+	// required: false
+	// default: false
+	Name bool `json:"timeSeries"`
+}
+
+// swagger:parameters graphNamespaces graphNamespacesDiff graphNamespacesEntryPoints graphNamespacesStream graphSnapshotSave
+type TimeSeriesStepParam struct {
+	// Used only with timeSeries. The time between points, at least 1m. At most 120 points are allowed.
+	//
+	// in: query
+	// required: false
+	// default: duration/30, at least 1m
+	Name string `json:"timeSeriesStep"`
+}
+
 /////////////////////
 // SWAGGER PARAMETERS - METRICS
 // - keep this alphabetized
@@ -565,6 +692,35 @@ type GraphResponse struct {
 	Body cytoscape.Config
 }
 
+// HTTP status code 200 and a stream of Server-Sent Events. The first "graph" event provides the cytoscapejs Config,
+// subsequent "delta" events provide the Delta against the previous event.
+// swagger:response graphStreamResponse
+type GraphStreamResponse struct {
+	// in:body
+	Body cytoscape.Delta
+}
+
+// HTTP status code 201 and the snapshot Info in data
+// swagger:response graphSnapshotInfoResponse
+type GraphSnapshotInfoResponse struct {
+	// in:body
+	Body snapshot.Info
+}
+
+// HTTP status code 200 and the snapshot Info list in data
+// swagger:response graphSnapshotListResponse
+type GraphSnapshotListResponse struct {
+	// in:body
+	Body []snapshot.Info
+}
+
+// HTTP status code 200 and cytoscapejs DependenciesConfig in data
+// swagger:response dependenciesResponse
+type DependenciesResponse struct {
+	// in:body
+	Body cytoscape.DependenciesConfig
+}
+
 // HTTP status code 200 and IstioConfigList model in data
 // swagger:response istioConfigList
 type IstioConfigResponse struct {
@@ -621,6 +777,20 @@ type namespaceAppHealthResponse struct {
 	Body models.NamespaceAppHealth
 }
 
+// namespaceSLOStatusResponse is a map of app, service or workload name x SLO status
+// swagger:response namespaceSLOStatusResponse
+type namespaceSLOStatusResponse struct {
+	// in:body
+	Body models.NamespaceSLOStatus
+}
+
+// sloStatusResponse is the status of the service level objectives of an app, service or workload
+// swagger:response sloStatusResponse
+type sloStatusResponse struct {
+	// in:body
+	Body models.SLOStatus
+}
+
 // namespaceResponse is a basic namespace
 // swagger:response namespaceResponse
 type namespaceResponse struct {
@@ -3,12 +3,25 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/config/dot"
+	"github.com/kiali/kiali/graph/config/graphml"
+	"github.com/kiali/kiali/graph/config/jgf"
+	"github.com/kiali/kiali/graph/telemetry"
 	"github.com/kiali/kiali/graph/telemetry/istio"
+	"github.com/kiali/kiali/graph/telemetry/istio/appender"
+	jaegerTelemetry "github.com/kiali/kiali/graph/telemetry/jaeger"
+	"github.com/kiali/kiali/jaeger"
+	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
 )
@@ -24,6 +37,10 @@ func GraphNamespaces(business *business.Layer, o graph.Options) (code int, confi
 		prom, err := prometheus.NewClient()
 		graph.CheckError(err)
 		code, config = graphNamespacesIstio(business, prom, o)
+	case graph.VendorJaeger:
+		client, err := business.Jaeger.Client()
+		graph.CheckError(err)
+		code, config = graphNamespacesJaeger(business, client, o)
 	default:
 		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
 	}
@@ -47,6 +64,243 @@ func graphNamespacesIstio(business *business.Layer, prom *prometheus.Client, o g
 	return code, config
 }
 
+// graphNamespacesJaeger provides a test hook that accepts mock clients
+func graphNamespacesJaeger(business *business.Layer, client jaeger.ClientInterface, o graph.Options) (code int, config interface{}) {
+
+	// Create a 'global' object to store the business. Global only to the request.
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+
+	trafficMap := jaegerTelemetry.BuildNamespacesTrafficMap(o.TelemetryOptions, client, globalInfo)
+	code, config = generateGraph(trafficMap, o)
+
+	return code, config
+}
+
+// GraphNamespacesEntryPoints generates a namespaces graph using the provided options, holding only the paths
+// through the external entry points of the mesh: the ingress gateways and the egress nodes.
+func GraphNamespacesEntryPoints(business *business.Layer, o graph.Options) (code int, config interface{}) {
+	// time how long it takes to generate this graph
+	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
+	defer promtimer.ObserveDuration()
+
+	switch o.TelemetryVendor {
+	case graph.VendorIstio:
+		prom, err := prometheus.NewClient()
+		graph.CheckError(err)
+		code, config = graphNamespacesEntryPointsIstio(business, prom, o)
+	case graph.VendorJaeger:
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] does not identify the ingress gateways", o.TelemetryVendor))
+	default:
+		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
+	}
+
+	// update metrics
+	internalmetrics.SetGraphNodes(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes, 0)
+
+	return code, config
+}
+
+// graphNamespacesEntryPointsIstio provides a test hook that accepts mock clients
+func graphNamespacesEntryPointsIstio(business *business.Layer, prom *prometheus.Client, o graph.Options) (code int, config interface{}) {
+
+	// the entry points are identified by the istio and serviceEntry appenders, make sure they are applied
+	if !o.Appenders.All {
+		appenderNames := append([]string{}, o.Appenders.AppenderNames...)
+		o.Appenders.AppenderNames = append(appenderNames, appender.IstioAppenderName, appender.ServiceEntryAppenderName)
+	}
+
+	// Create a 'global' object to store the business. Global only to the request.
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+
+	trafficMap := istio.BuildNamespacesTrafficMap(o.TelemetryOptions, prom, globalInfo)
+	code, config = generateGraph(telemetry.FilterEntryPoints(trafficMap), o)
+
+	return code, config
+}
+
+// GraphNamespacesDiff generates a namespaces graph using the provided options, decorated with the
+// differences found when compared to the baseline graph.
+func GraphNamespacesDiff(business *business.Layer, o graph.DiffOptions) (code int, config interface{}) {
+	// time how long it takes to generate this graph
+	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
+	defer promtimer.ObserveDuration()
+
+	switch o.TelemetryVendor {
+	case graph.VendorIstio:
+		prom, err := prometheus.NewClient()
+		graph.CheckError(err)
+		code, config = graphNamespacesDiffIstio(business, prom, o)
+	case graph.VendorJaeger:
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] supports only namespaces graphs", o.TelemetryVendor))
+	default:
+		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
+	}
+
+	// update metrics
+	internalmetrics.SetGraphNodes(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes, 0)
+
+	return code, config
+}
+
+// graphNamespacesDiffIstio provides a test hook that accepts mock clients
+func graphNamespacesDiffIstio(business *business.Layer, prom *prometheus.Client, o graph.DiffOptions) (code int, config interface{}) {
+
+	// Each graph gets its own 'global' object, the appenders may cache time-specific information
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+	trafficMap := istio.BuildNamespacesTrafficMap(o.TelemetryOptions, prom, globalInfo)
+
+	baselineGlobalInfo := graph.NewAppenderGlobalInfo()
+	baselineGlobalInfo.Business = business
+	baselineTrafficMap := istio.BuildNamespacesTrafficMap(o.Baseline.TelemetryOptions, prom, baselineGlobalInfo)
+
+	trafficMap = telemetry.DiffTrafficMaps(trafficMap, baselineTrafficMap)
+	code, config = generateGraph(trafficMap, o.Options)
+
+	return code, config
+}
+
+// GraphSimulation generates a namespace graph in which the traffic is redistributed as if the proposed
+// VirtualService was applied, decorated with the differences found when compared to the current graph.
+func GraphSimulation(business *business.Layer, o graph.Options, vs models.VirtualService) (code int, config interface{}) {
+	// time how long it takes to generate this graph
+	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
+	defer promtimer.ObserveDuration()
+
+	switch o.TelemetryVendor {
+	case graph.VendorIstio:
+		prom, err := prometheus.NewClient()
+		graph.CheckError(err)
+		code, config = graphSimulationIstio(business, prom, o, vs)
+	case graph.VendorJaeger:
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] supports only namespaces graphs", o.TelemetryVendor))
+	default:
+		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
+	}
+
+	// update metrics
+	internalmetrics.SetGraphNodes(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes, 0)
+
+	return code, config
+}
+
+// graphSimulationIstio provides a test hook that accepts mock clients
+func graphSimulationIstio(business *business.Layer, prom *prometheus.Client, o graph.Options, vs models.VirtualService) (code int, config interface{}) {
+
+	// the ingress gateways are identified by the istio appender, make sure it is applied
+	if !o.Appenders.All && !hasAppender(o.Appenders, appender.IstioAppenderName) {
+		appenderNames := append([]string{}, o.Appenders.AppenderNames...)
+		o.Appenders.AppenderNames = append(appenderNames, appender.IstioAppenderName)
+	}
+
+	// Create a 'global' object to store the business. Global only to the request.
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+	trafficMap := istio.BuildNamespacesTrafficMap(o.TelemetryOptions, prom, globalInfo)
+
+	simulatedTrafficMap := telemetry.SimulateVirtualService(trafficMap, newSimulation(business, o, vs))
+	simulatedTrafficMap = telemetry.DiffTrafficMaps(simulatedTrafficMap, trafficMap)
+	code, config = generateGraph(simulatedTrafficMap, o)
+
+	return code, config
+}
+
+// newSimulation resolves the possible destinations of the proposed VirtualService: the DestinationRule subsets
+// and the workloads selected by the services, in the VirtualService namespace, in the namespaces of its hosts and
+// route destinations, and in the graph namespaces.
+func newSimulation(layer *business.Layer, o graph.Options, vs models.VirtualService) telemetry.Simulation {
+	cfg := config.Get()
+	s := telemetry.Simulation{
+		GraphType:      o.TelemetryOptions.GraphType,
+		Namespace:      vs.Metadata.Namespace,
+		Subsets:        make(map[string]map[string]map[string]string),
+		VirtualService: vs,
+		Workloads:      make(map[string][]telemetry.SimulationWorkload),
+	}
+	for namespace := range o.AccessibleNamespaces {
+		s.ClusterNamespaces = append(s.ClusterNamespaces, namespace)
+	}
+	sort.Strings(s.ClusterNamespaces)
+
+	namespaces := []string{vs.Metadata.Namespace}
+	seen := map[string]bool{vs.Metadata.Namespace: true}
+	for _, namespace := range s.RouteNamespaces() {
+		if !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	for namespace := range o.TelemetryOptions.Namespaces {
+		if !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		if _, ok := o.AccessibleNamespaces[namespace]; !ok {
+			continue
+		}
+
+		istioCfg, err := layer.IstioConfig.GetIstioConfigList(business.IstioConfigCriteria{
+			IncludeDestinationRules: true,
+			Namespace:               namespace,
+		})
+		graph.CheckError(err)
+		for _, dr := range istioCfg.DestinationRules.Items {
+			host, _ := dr.Spec.Host.(string)
+			h := kubernetes.GetHost(host, namespace, "", s.ClusterNamespaces)
+			subsets, ok := dr.Spec.Subsets.([]interface{})
+			if !h.CompleteInput || !ok {
+				continue
+			}
+			serviceKey := telemetry.SimulationServiceKey(h.Namespace, h.Service)
+			if _, ok := s.Subsets[serviceKey]; !ok {
+				s.Subsets[serviceKey] = make(map[string]map[string]string)
+			}
+			for _, ss := range subsets {
+				subset, ok := ss.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _ := subset["name"].(string)
+				labels := make(map[string]string)
+				if subsetLabels, ok := subset["labels"].(map[string]interface{}); ok {
+					for k, v := range subsetLabels {
+						labels[k], _ = v.(string)
+					}
+				}
+				s.Subsets[serviceKey][name] = labels
+			}
+		}
+
+		services, err := layer.Svc.GetServiceDefinitionList(namespace)
+		graph.CheckError(err)
+		workloads, err := layer.Workload.GetWorkloadList(namespace, false)
+		graph.CheckError(err)
+		for _, sd := range services.ServiceDefinitions {
+			if len(sd.Service.Selectors) == 0 {
+				continue
+			}
+			serviceKey := telemetry.SimulationServiceKey(namespace, sd.Service.Name)
+			for _, w := range workloads.Workloads {
+				if labels.SelectorFromSet(sd.Service.Selectors).Matches(labels.Set(w.Labels)) {
+					s.Workloads[serviceKey] = append(s.Workloads[serviceKey], telemetry.SimulationWorkload{
+						App:     w.Labels[cfg.IstioLabels.AppLabelName],
+						Labels:  w.Labels,
+						Name:    w.Name,
+						Version: w.Labels[cfg.IstioLabels.VersionLabelName],
+					})
+				}
+			}
+		}
+	}
+
+	return s
+}
+
 // GraphNode generates a node graph using the provided options
 func GraphNode(business *business.Layer, o graph.Options) (code int, config interface{}) {
 	if len(o.Namespaces) != 1 {
@@ -62,6 +316,8 @@ func GraphNode(business *business.Layer, o graph.Options) (code int, config inte
 		prom, err := prometheus.NewClient()
 		graph.CheckError(err)
 		code, config = graphNodeIstio(business, prom, o)
+	case graph.VendorJaeger:
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] supports only namespaces graphs", o.TelemetryVendor))
 	default:
 		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
 	}
@@ -84,7 +340,80 @@ func graphNodeIstio(business *business.Layer, client *prometheus.Client, o graph
 	return code, config
 }
 
+// GraphDependencies analyzes the dependencies of a node using the provided options. The analysis is based on the
+// namespaces graph, so that dependencies are found beyond the node's immediate neighbors. The find and hide
+// expressions apply to that graph: hidden nodes and edges are left out of the analysis.
+func GraphDependencies(business *business.Layer, o graph.Options) (code int, config interface{}) {
+	if o.ConfigVendor != graph.VendorCytoscape {
+		graph.BadRequest(fmt.Sprintf("Dependencies do not support configVendor [%s]", o.ConfigVendor))
+	}
+
+	// time how long it takes to generate this graph
+	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
+	defer promtimer.ObserveDuration()
+
+	switch o.TelemetryVendor {
+	case graph.VendorIstio:
+		prom, err := prometheus.NewClient()
+		graph.CheckError(err)
+		code, config = graphDependenciesIstio(business, prom, o)
+	case graph.VendorJaeger:
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] supports only namespaces graphs", o.TelemetryVendor))
+	default:
+		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
+	}
+
+	// update metrics
+	internalmetrics.SetGraphNodes(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes, 0)
+
+	return code, config
+}
+
+// graphDependenciesIstio provides a test hook that accepts mock clients
+func graphDependenciesIstio(business *business.Layer, prom *prometheus.Client, o graph.Options) (code int, config interface{}) {
+
+	// Create a 'global' object to store the business. Global only to the request.
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+
+	// build a namespaces graph, the node options only identify the root nodes. The path latency requires
+	// the edge response times.
+	telemetryOptions := o.TelemetryOptions
+	telemetryOptions.NodeOptions = graph.NodeOptions{}
+	if !telemetryOptions.Appenders.All && !hasAppender(telemetryOptions.Appenders, appender.ResponseTimeAppenderName) {
+		appenderNames := append([]string{}, telemetryOptions.Appenders.AppenderNames...)
+		telemetryOptions.Appenders.AppenderNames = append(appenderNames, appender.ResponseTimeAppenderName)
+	}
+	trafficMap := istio.BuildNamespacesTrafficMap(telemetryOptions, prom, globalInfo)
+
+	// apply any find and hide before the analysis, hidden nodes and edges are not dependencies
+	graph.FindHideTrafficMap(trafficMap, o.Find, o.Hide)
+
+	roots := telemetry.MatchNodes(trafficMap, o.NodeOptions)
+	if len(roots) == 0 {
+		graph.Panic(fmt.Sprintf("No traffic found for the requested node in namespace [%s]", o.NodeOptions.Namespace), http.StatusNotFound)
+	}
+	dependencies := telemetry.AnalyzeDependencies(trafficMap, roots)
+
+	promtimer := internalmetrics.GetGraphMarshalTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
+	defer promtimer.ObserveDuration()
+
+	return http.StatusOK, cytoscape.NewDependenciesConfig(trafficMap, dependencies, o.ConfigOptions)
+}
+
+func hasAppender(appenders graph.RequestedAppenders, name string) bool {
+	for _, appenderName := range appenders.AppenderNames {
+		if appenderName == name {
+			return true
+		}
+	}
+	return false
+}
+
 func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface{}) {
+	// apply any find and hide before generating the config, hidden nodes and edges are not returned
+	graph.FindHideTrafficMap(trafficMap, o.Find, o.Hide)
+
 	log.Tracef("Generating config for [%s] graph...", o.ConfigVendor)
 
 	promtimer := internalmetrics.GetGraphMarshalTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
@@ -94,6 +423,12 @@ func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface
 	switch o.ConfigVendor {
 	case graph.VendorCytoscape:
 		vendorConfig = cytoscape.NewConfig(trafficMap, o.ConfigOptions)
+	case graph.VendorDot:
+		vendorConfig = dot.NewConfig(trafficMap, o.ConfigOptions)
+	case graph.VendorGraphML:
+		vendorConfig = graphml.NewConfig(trafficMap, o.ConfigOptions)
+	case graph.VendorJGF:
+		vendorConfig = jgf.NewConfig(trafficMap, o.ConfigOptions)
 	default:
 		graph.Error(fmt.Sprintf("ConfigVendor [%s] not supported", o.ConfigVendor))
 	}
@@ -1098,6 +1098,46 @@ func TestAppGraph(t *testing.T) {
 	assert.Equal(t, 200, resp.StatusCode)
 }
 
+func TestAppDependenciesHide(t *testing.T) {
+	client, xapi, err := mockNamespaceGraph(t)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// the responseTime appender queries find no telemetry
+	xapi.On("Query", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(model.Vector{}, nil)
+
+	mr := mux.NewRouter()
+	mr.HandleFunc("/api/namespaces/{namespace}/applications/{app}/graph/dependencies", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			context := context.WithValue(r.Context(), "authInfo", &api.AuthInfo{Token: "test"})
+			code, config := graphDependenciesIstio(nil, client, graph.NewDependenciesOptions(r.WithContext(context)))
+			respond(w, code, config)
+		}))
+
+	ts := httptest.NewServer(mr)
+	defer ts.Close()
+
+	url := ts.URL + "/api/namespaces/bookinfo/applications/reviews/graph/dependencies?graphType=app&appenders&queryTime=1523364075"
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Contains(t, string(actual), `"app": "productpage"`)
+
+	// the hidden upstream node is not a dependency
+	resp, err = http.Get(url + "&hide=app%3Dproductpage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ = ioutil.ReadAll(resp.Body)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Contains(t, string(actual), `"app": "reviews"`)
+	assert.NotContains(t, string(actual), `"app": "productpage"`)
+}
+
 func TestVersionedAppGraph(t *testing.T) {
 	client, _, err := mockNamespaceGraph(t)
 	if err != nil {
@@ -2825,7 +2865,8 @@ func TestRatesNodeGraphTotal(t *testing.T) {
 // - workload -> egress -> service-entry traffic
 // - 0 response code (no response)
 // note: appenders still tested in separate unit tests given that they create their own new business/kube clients
-func TestComplexGraph(t *testing.T) {
+// mockComplexGraph provides the mocks of a graph spanning the bookinfo, tutorial and istio-system namespaces
+func mockComplexGraph() (*prometheus.Client, *prometheustest.PromAPIMock, error) {
 	// bookinfo
 	q0 := `round(sum(rate(istio_requests_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol,response_code,grpc_response_status,response_flags) > 0,0.001)`
 	q0m0 := model.Metric{ // outsider request that fails to reach workload
@@ -3262,8 +3303,7 @@ func TestComplexGraph(t *testing.T) {
 
 	client, xapi, _, err := setupMockedWithIstioComponentNamespaces()
 	if err != nil {
-		t.Error(err)
-		return
+		return client, xapi, err
 	}
 	mockQuery(xapi, q0, &v0)
 	mockQuery(xapi, q1, &v1)
@@ -3284,6 +3324,16 @@ func TestComplexGraph(t *testing.T) {
 	mockQuery(xapi, q16, &v16)
 	mockQuery(xapi, q17, &v17)
 
+	return client, xapi, nil
+}
+
+func TestComplexGraph(t *testing.T) {
+	client, _, err := mockComplexGraph()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
 	var fut func(b *business.Layer, p *prometheus.Client, o graph.Options) (int, interface{})
 
 	mr := mux.NewRouter()
@@ -3316,6 +3366,91 @@ func TestComplexGraph(t *testing.T) {
 	assert.Equal(t, 200, resp.StatusCode)
 }
 
+// TestComplexGraphConcurrent expects the same graph when the namespaces are built sequentially and concurrently.
+// The appenders share the global info, run it with -race to detect unguarded accesses.
+func TestComplexGraphConcurrent(t *testing.T) {
+	graphs := [][]byte{}
+	for _, maxConcurrent := range []int{1, 3} {
+		client, xapi, err := mockComplexGraph()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conf := config.Get()
+		conf.Graph.NamespaceMaxConcurrent = maxConcurrent
+		config.Set(conf)
+		// the appender queries find no telemetry
+		xapi.On("Query", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(model.Vector{}, nil)
+
+		mr := mux.NewRouter()
+		mr.HandleFunc("/api/namespaces/graph", http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				context := context.WithValue(r.Context(), "authInfo", &api.AuthInfo{Token: "test"})
+				code, config := graphNamespacesIstio(nil, client, graph.NewOptions(r.WithContext(context)))
+				respond(w, code, config)
+			}))
+
+		ts := httptest.NewServer(mr)
+		url := ts.URL + "/api/namespaces/graph?graphType=versionedApp&appenders=responseTime,securityPolicy,throughput&queryTime=1523364075&namespaces=bookinfo,tutorial,istio-system"
+		resp, err := http.Get(url)
+		if err != nil {
+			ts.Close()
+			t.Fatal(err)
+		}
+		actual, _ := ioutil.ReadAll(resp.Body)
+		ts.Close()
+
+		assert.Equal(t, 200, resp.StatusCode)
+		graphs = append(graphs, actual)
+	}
+
+	if !assert.Equal(t, graphs[0], graphs[1]) {
+		fmt.Printf("\nSequential:\n%v\nConcurrent:\n%v", string(graphs[0]), string(graphs[1]))
+	}
+}
+
+// TestComplexGraphAppenderPanic expects a panic of an appender, running on a namespace goroutine, to reach the
+// request handler
+func TestComplexGraphAppenderPanic(t *testing.T) {
+	client, xapi, err := mockComplexGraph()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// the appender queries return a matrix, the appenders panic on it
+	xapi.On("Query", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(model.Matrix{}, nil)
+
+	mr := mux.NewRouter()
+	mr.HandleFunc("/api/namespaces/graph", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if r := recover(); r != nil {
+					if response, ok := r.(graph.Response); ok {
+						respond(w, response.Code, response.Message)
+						return
+					}
+					respond(w, http.StatusInternalServerError, fmt.Sprintf("%v", r))
+				}
+			}()
+			context := context.WithValue(r.Context(), "authInfo", &api.AuthInfo{Token: "test"})
+			code, config := graphNamespacesIstio(nil, client, graph.NewOptions(r.WithContext(context)))
+			respond(w, code, config)
+		}))
+
+	ts := httptest.NewServer(mr)
+	defer ts.Close()
+
+	url := ts.URL + "/api/namespaces/graph?graphType=versionedApp&appenders=responseTime&queryTime=1523364075&namespaces=bookinfo,tutorial,istio-system"
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := ioutil.ReadAll(resp.Body)
+
+	assert.Equal(t, 500, resp.StatusCode)
+	assert.Contains(t, string(actual), "No handling for type matrix")
+}
+
 func TestMultiClusterSourceGraph(t *testing.T) {
 	// bookinfo
 	q0 := `round(sum(rate(istio_requests_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol,response_code,grpc_response_status,response_flags) ,0.001)`
@@ -1,6 +1,8 @@
 package graph
 
 import (
+	"sync"
+
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/prometheus"
 )
@@ -10,8 +12,11 @@ type AppenderVendorInfo map[string]interface{}
 // AppenderGlobalInfo caches information relevant to a single graph. It allows
 // an appender to populate the cache and then it, or another appender
 // can re-use the information.  A new instance is generated for graph and
-// is initially empty.
+// is initially empty. Namespaces may be appended concurrently, so an appender must
+// hold the lock when reading or populating HomeCluster and Vendor. PromClient is set
+// before the namespaces are appended.
 type AppenderGlobalInfo struct {
+	sync.Mutex
 	Business    *business.Layer
 	HomeCluster string
 	PromClient  *prometheus.Client
@@ -0,0 +1,255 @@
+package graph
+
+// Cache.go provides an optional cache of namespace TrafficMaps, shared by all graph requests. Building a
+// namespace TrafficMap requires several Prometheus queries, as well as the appender work. When many clients
+// poll the same namespaces (e.g. dashboards) the cache avoids rebuilding the same TrafficMaps.
+//
+// A cached TrafficMap is reused for requests with the same options, other than the query time, when the
+// query time is not more than the cache duration past the cached query time. Once the query time moves
+// past the cache step the TrafficMap is rebuilt. Because the appenders decorate nodes based on the requesting
+// user's accessible namespaces, a cached TrafficMap is reused only for users with the same access to the
+// namespaces of its nodes.
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
+)
+
+// GraphCache stores namespace TrafficMaps. The TrafficMaps are copied in and out of the cache, the
+// caller is free to modify them.
+type GraphCache interface {
+	GetNamespaceTrafficMap(namespace string, o TelemetryOptions) (bool, TrafficMap)
+	SetNamespaceTrafficMap(namespace string, o TelemetryOptions, trafficMap TrafficMap)
+}
+
+type graphCacheEntry struct {
+	accessible map[string]bool // for each namespace of the trafficMap nodes, whether it was accessible
+	expiration time.Time       // zero if the entry does not expire
+	queryTime  int64
+	trafficMap TrafficMap
+}
+
+type graphCacheImpl struct {
+	cacheDuration   time.Duration
+	cacheExpiration time.Duration
+	entries         map[string]graphCacheEntry
+	lock            sync.RWMutex
+}
+
+// graphCacheIgnoredParams are the query params not relevant to a namespace TrafficMap, or already
+// accounted for by the options used in the cache key.
+var graphCacheIgnoredParams = map[string]bool{
+	"appenders":          true,
+	"boxBy":              true,
+	"compareTime":        true,
+	"configVendor":       true,
+	"duration":           true,
+	"find":               true,
+	"graphType":          true,
+	"hide":               true,
+	"includeIdleEdges":   true,
+	"injectServiceNodes": true,
+	"namespaces":         true,
+	"queryTime":          true,
+	"rateGrpc":           true,
+	"rateHttp":           true,
+	"rateTcp":            true,
+	"timeSeries":         true,
+	"timeSeriesStep":     true,
+}
+
+var graphCache GraphCache
+var graphCacheOnce sync.Once
+
+// GetGraphCache returns the graph cache, or nil if the cache is disabled
+func GetGraphCache() GraphCache {
+	graphCacheOnce.Do(func() {
+		cfg := config.Get().Graph
+		if cfg.CacheEnabled {
+			log.Infof("[Graph Cache] Enabled")
+			graphCache = NewGraphCache(time.Duration(cfg.CacheDuration)*time.Second, time.Duration(cfg.CacheExpiration)*time.Second)
+		} else {
+			log.Infof("[Graph Cache] Disabled")
+		}
+	})
+	return graphCache
+}
+
+// NewGraphCache returns a GraphCache. Cached TrafficMaps are reused for query times up to cacheDuration past
+// their own query time, and are removed cacheExpiration after they are cached (never, if not positive).
+func NewGraphCache(cacheDuration, cacheExpiration time.Duration) GraphCache {
+	c := graphCacheImpl{
+		cacheDuration:   cacheDuration,
+		cacheExpiration: cacheExpiration,
+		entries:         make(map[string]graphCacheEntry),
+	}
+
+	if cacheExpiration > 0 {
+		go c.watchExpiration()
+	}
+
+	return &c
+}
+
+func (c *graphCacheImpl) GetNamespaceTrafficMap(namespace string, o TelemetryOptions) (bool, TrafficMap) {
+	key := graphCacheKey(namespace, o)
+
+	c.lock.RLock()
+	entry, ok := c.entries[key]
+	c.lock.RUnlock()
+
+	if ok && c.isValid(entry, o) {
+		log.Tracef("[Graph Cache] GetNamespaceTrafficMap hit [namespace: %s] [queryTime: %d]", namespace, o.QueryTime)
+		internalmetrics.GetGraphCacheRequestsMetric("hit").Inc()
+		return true, CloneTrafficMap(entry.trafficMap)
+	}
+
+	internalmetrics.GetGraphCacheRequestsMetric("miss").Inc()
+	return false, nil
+}
+
+func (c *graphCacheImpl) SetNamespaceTrafficMap(namespace string, o TelemetryOptions, trafficMap TrafficMap) {
+	entry := graphCacheEntry{
+		accessible: make(map[string]bool),
+		queryTime:  o.QueryTime,
+		trafficMap: CloneTrafficMap(trafficMap),
+	}
+	if c.cacheExpiration > 0 {
+		entry.expiration = time.Now().Add(c.cacheExpiration)
+	}
+	for _, n := range trafficMap {
+		_, entry.accessible[n.Namespace] = o.AccessibleNamespaces[n.Namespace]
+	}
+	key := graphCacheKey(namespace, o)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// don't replace a more recent entry
+	if current, ok := c.entries[key]; ok && current.queryTime > entry.queryTime {
+		return
+	}
+	c.entries[key] = entry
+	internalmetrics.SetGraphCacheEntries(len(c.entries))
+	log.Tracef("[Graph Cache] SetNamespaceTrafficMap [namespace: %s] [queryTime: %d]", namespace, o.QueryTime)
+}
+
+// isValid returns true if the entry can be used for the options
+func (c *graphCacheImpl) isValid(entry graphCacheEntry, o TelemetryOptions) bool {
+	if o.QueryTime < entry.queryTime || time.Duration(o.QueryTime-entry.queryTime)*time.Second >= c.cacheDuration {
+		return false
+	}
+	if !entry.expiration.IsZero() && !time.Now().Before(entry.expiration) {
+		return false
+	}
+	for namespace, wasAccessible := range entry.accessible {
+		if _, isAccessible := o.AccessibleNamespaces[namespace]; isAccessible != wasAccessible {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *graphCacheImpl) watchExpiration() {
+	for {
+		time.Sleep(c.cacheExpiration)
+		now := time.Now()
+		c.lock.Lock()
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiration) {
+				delete(c.entries, key)
+			}
+		}
+		internalmetrics.SetGraphCacheEntries(len(c.entries))
+		c.lock.Unlock()
+		log.Tracef("[Graph Cache] Expired")
+	}
+}
+
+// graphCacheKey identifies the namespace TrafficMap for all of the options that affect it, other than
+// the query time and the user's accessible namespaces, which are validated separately.
+func graphCacheKey(namespace string, o TelemetryOptions) string {
+	appenders := "all"
+	if !o.Appenders.All {
+		appenderNames := append([]string{}, o.Appenders.AppenderNames...)
+		sort.Strings(appenderNames)
+		appenders = strings.Join(appenderNames, ",")
+	}
+
+	// include any vendor-specific params, they may affect the appenders (url.Values.Encode sorts by key)
+	params := url.Values{}
+	for k, v := range o.Params {
+		if !graphCacheIgnoredParams[k] {
+			params[k] = v
+		}
+	}
+
+	return fmt.Sprintf("%s|%d|%s|%s|%t|%t|%t|%s|%s|%s|%d|%s|%s",
+		namespace,
+		int64(o.Namespaces[namespace].Duration.Seconds()),
+		o.GraphType,
+		o.BoxByLabel,
+		o.BoxByWorkloadGroup,
+		o.InjectServiceNodes,
+		o.IncludeIdleEdges,
+		o.Rates.Grpc,
+		o.Rates.Http,
+		o.Rates.Tcp,
+		int64(o.TimeSeriesStep.Seconds()),
+		appenders,
+		params.Encode())
+}
+
+// CloneTrafficMap returns a copy of the trafficMap, with new nodes, edges and metadata maps. Metadata
+// values are shared, other than DestServicesMetadata, which may be updated by the appenders.
+func CloneTrafficMap(trafficMap TrafficMap) TrafficMap {
+	clone := NewTrafficMap()
+	for id, n := range trafficMap {
+		cloneNode := *n
+		cloneNode.Metadata = cloneMetadata(n.Metadata)
+		clone[id] = &cloneNode
+	}
+	for id, n := range trafficMap {
+		cloneNode := clone[id]
+		cloneNode.Edges = make([]*Edge, len(n.Edges))
+		for i, e := range n.Edges {
+			dest, ok := clone[e.Dest.ID]
+			if !ok {
+				// should not happen, but don't share the node if the dest is missing from the map
+				cloneDest := *e.Dest
+				cloneDest.Edges = []*Edge{}
+				cloneDest.Metadata = cloneMetadata(e.Dest.Metadata)
+				dest = &cloneDest
+			}
+			cloneNode.Edges[i] = &Edge{
+				Source:   cloneNode,
+				Dest:     dest,
+				Metadata: cloneMetadata(e.Metadata),
+			}
+		}
+	}
+	return clone
+}
+
+func cloneMetadata(md Metadata) Metadata {
+	clone := make(Metadata, len(md))
+	for k, v := range md {
+		if destServices, ok := v.(DestServicesMetadata); ok {
+			cloneDestServices := NewDestServicesMetadata()
+			for dsk, ds := range destServices {
+				cloneDestServices[dsk] = ds
+			}
+			v = cloneDestServices
+		}
+		clone[k] = v
+	}
+	return clone
+}
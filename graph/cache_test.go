@@ -0,0 +1,134 @@
+package graph
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheTestOptions(queryTime int64, accessible ...string) TelemetryOptions {
+	o := TelemetryOptions{
+		AccessibleNamespaces: map[string]time.Time{},
+		Appenders:            RequestedAppenders{AppenderNames: []string{"responseTime", "deadNode"}},
+		Namespaces:           NamespaceInfoMap{"foo": NamespaceInfo{Name: "foo", Duration: 10 * time.Minute}},
+		CommonOptions: CommonOptions{
+			Duration:  10 * time.Minute,
+			GraphType: GraphTypeWorkload,
+			Params:    url.Values{},
+			QueryTime: queryTime,
+		},
+	}
+	for _, namespace := range accessible {
+		o.AccessibleNamespaces[namespace] = time.Time{}
+	}
+	return o
+}
+
+func newCacheTestTrafficMap() TrafficMap {
+	trafficMap := NewTrafficMap()
+	a := newFindTestNode(trafficMap, "foo", "a")
+	b := newFindTestNode(trafficMap, "bar", "b")
+	addFindTestEdge(a, b, 10.0, 1.0)
+	return trafficMap
+}
+
+func TestGraphCache(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := NewGraphCache(15*time.Second, 0)
+	cache.SetNamespaceTrafficMap("foo", newCacheTestOptions(1000, "foo"), newCacheTestTrafficMap())
+
+	ok, trafficMap := cache.GetNamespaceTrafficMap("foo", newCacheTestOptions(1000, "foo"))
+	assert.True(ok)
+	assert.Equal(2, len(trafficMap))
+	ok, _ = cache.GetNamespaceTrafficMap("foo", newCacheTestOptions(1014, "foo"))
+	assert.True(ok)
+
+	// past the cache step, or before the cached query time
+	ok, _ = cache.GetNamespaceTrafficMap("foo", newCacheTestOptions(1015, "foo"))
+	assert.False(ok)
+	ok, _ = cache.GetNamespaceTrafficMap("foo", newCacheTestOptions(999, "foo"))
+	assert.False(ok)
+
+	// different access to the node namespaces
+	ok, _ = cache.GetNamespaceTrafficMap("foo", newCacheTestOptions(1000, "foo", "bar"))
+	assert.False(ok)
+
+	// different options
+	o := newCacheTestOptions(1000, "foo")
+	o.Appenders.AppenderNames = []string{"deadNode", "responseTime"}
+	ok, _ = cache.GetNamespaceTrafficMap("foo", o)
+	assert.True(ok, "appender order should not matter")
+	o.Appenders.AppenderNames = []string{"deadNode"}
+	ok, _ = cache.GetNamespaceTrafficMap("foo", o)
+	assert.False(ok)
+	o = newCacheTestOptions(1000, "foo")
+	o.InjectServiceNodes = true
+	ok, _ = cache.GetNamespaceTrafficMap("foo", o)
+	assert.False(ok)
+	o = newCacheTestOptions(1000, "foo")
+	o.Params.Set("responseTime", "50")
+	ok, _ = cache.GetNamespaceTrafficMap("foo", o)
+	assert.False(ok)
+	o = newCacheTestOptions(1000, "foo")
+	o.Params.Set("boxBy", "app")
+	ok, _ = cache.GetNamespaceTrafficMap("foo", o)
+	assert.True(ok, "boxBy does not affect the namespace traffic map")
+
+	// an older entry does not replace a newer one
+	cache.SetNamespaceTrafficMap("foo", newCacheTestOptions(990, "foo"), NewTrafficMap())
+	ok, trafficMap = cache.GetNamespaceTrafficMap("foo", newCacheTestOptions(1000, "foo"))
+	assert.True(ok)
+	assert.Equal(2, len(trafficMap))
+}
+
+func TestGraphCacheClone(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := NewGraphCache(15*time.Second, 0)
+	o := newCacheTestOptions(1000, "foo")
+	cached := newCacheTestTrafficMap()
+	cache.SetNamespaceTrafficMap("foo", o, cached)
+
+	// changes to the cached map, or to a returned map, do not affect the cache
+	for _, n := range cached {
+		n.Metadata[IsDead] = true
+	}
+	_, trafficMap := cache.GetNamespaceTrafficMap("foo", o)
+	for _, n := range trafficMap {
+		_, found := n.Metadata[IsDead]
+		assert.False(found)
+		for _, e := range n.Edges {
+			assert.Same(trafficMap[e.Dest.ID], e.Dest)
+			e.Metadata[ResponseTime] = 100.0
+		}
+	}
+	for id := range trafficMap {
+		delete(trafficMap, id)
+	}
+
+	_, trafficMap = cache.GetNamespaceTrafficMap("foo", o)
+	assert.Equal(2, len(trafficMap))
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			_, found := e.Metadata[ResponseTime]
+			assert.False(found)
+		}
+	}
+}
+
+func TestGraphCacheExpiration(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := NewGraphCache(time.Hour, 10*time.Millisecond)
+	o := newCacheTestOptions(1000, "foo")
+	cache.SetNamespaceTrafficMap("foo", o, newCacheTestTrafficMap())
+	ok, _ := cache.GetNamespaceTrafficMap("foo", o)
+	assert.True(ok)
+
+	time.Sleep(20 * time.Millisecond)
+	ok, _ = cache.GetNamespaceTrafficMap("foo", o)
+	assert.False(ok)
+}
@@ -8,3 +8,14 @@ type ConfigVendor interface {
 	// definitions for error handling. Refer to the Cytoscape implementation as an example.
 	NewConfig(trafficMap TrafficMap, o ConfigOptions) interface{}
 }
+
+// RawConfig can be implemented by a Config produced by a ConfigVendor when the config should
+// be returned as-is, as opposed to being marshaled as JSON (e.g. DOT or XML formats).
+type RawConfig interface {
+
+	// ContentType returns the media type to be set on the response
+	ContentType() string
+
+	// Raw returns the serialized config
+	Raw() ([]byte, error)
+}
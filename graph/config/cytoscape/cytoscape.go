@@ -47,11 +47,29 @@ type ResponseDetail struct {
 // Responses maps responseCodes to detailed information for that code
 type Responses map[string]*ResponseDetail
 
+// RootCause is a likely cause of the failed requests of an edge, see graph.RootCause
+type RootCause struct {
+	Cause      string   `json:"cause"`      // circuitBreaker | faultInjected | noRoute | rateLimited | timeout | upstreamConnectFailure
+	Config     []string `json:"config"`     // the Istio config possibly responsible, objectType:namespace/name
+	Flags      []string `json:"flags"`      // the Envoy response flags attributed to the cause
+	Percentage string   `json:"percentage"` // percentage of the edge requests
+}
+
 // ProtocolTraffic supplies all of the traffic information for a single protocol
 type ProtocolTraffic struct {
-	Protocol  string            `json:"protocol,omitempty"`  // protocol
-	Rates     map[string]string `json:"rates,omitempty"`     // map[rate]value
-	Responses Responses         `json:"responses,omitempty"` // see comment above
+	Protocol   string            `json:"protocol,omitempty"`   // protocol
+	Rates      map[string]string `json:"rates,omitempty"`      // map[rate]value
+	Responses  Responses         `json:"responses,omitempty"`  // see comment above
+	TimeSeries *TimeSeries       `json:"timeSeries,omitempty"` // set only when time series are requested
+}
+
+// TimeSeries holds request rates for consecutive time periods of length step. Point i covers the
+// period ending at start+(i*step). Node time series reflect incoming traffic.
+type TimeSeries struct {
+	Start    int64    `json:"start"`    // unix time in seconds, end of the first period
+	Step     int64    `json:"step"`     // in seconds
+	Rates    []string `json:"rates"`    // total request rate
+	ErrRates []string `json:"errRates"` // error request rate
 }
 
 // GWInfo contains the resolved gateway configuration if the node represents an Istio gateway
@@ -72,6 +90,15 @@ type VSInfo struct {
 	Hostnames []string `json:"hostnames,omitempty"`
 }
 
+// DiffInfo contains the result of comparing the node or edge to a baseline graph (diff graphs only). The
+// deltas are current-baseline and are set only for edges.
+type DiffInfo struct {
+	Status       string `json:"status"`                 // added | changed | removed | unchanged
+	ErrorRate    string `json:"errorRate,omitempty"`    // delta for the percentage of requests in error
+	RequestRate  string `json:"requestRate,omitempty"`  // delta for the total traffic rate, in protocol units
+	ResponseTime string `json:"responseTime,omitempty"` // delta for the response time, in millis
+}
+
 // HealthConfig maps annotations information for health
 type HealthConfig map[string]string
 
@@ -81,33 +108,40 @@ type NodeData struct {
 	Parent string `json:"parent,omitempty"` // Compound Node parent ID
 
 	// App Fields (not required by Cytoscape)
-	NodeType              string              `json:"nodeType"`
-	Cluster               string              `json:"cluster"`
-	Namespace             string              `json:"namespace"`
-	Workload              string              `json:"workload,omitempty"`
-	App                   string              `json:"app,omitempty"`
-	Version               string              `json:"version,omitempty"`
-	Service               string              `json:"service,omitempty"`               // requested service for NodeTypeService
-	Aggregate             string              `json:"aggregate,omitempty"`             // set like "<aggregate>=<aggregateVal>"
-	DestServices          []graph.ServiceName `json:"destServices,omitempty"`          // requested services for [dest] node
-	Traffic               []ProtocolTraffic   `json:"traffic,omitempty"`               // traffic rates for all detected protocols
-	HasCB                 bool                `json:"hasCB,omitempty"`                 // true (has circuit breaker) | false
-	HasFaultInjection     bool                `json:"hasFaultInjection,omitempty"`     // true (vs has fault injection) | false
-	HasHealthConfig       HealthConfig        `json:"hasHealthConfig,omitempty"`       // set to the health config override
-	HasMissingSC          bool                `json:"hasMissingSC,omitempty"`          // true (has missing sidecar) | false
-	HasRequestRouting     bool                `json:"hasRequestRouting,omitempty"`     // true (vs has request routing) | false
-	HasRequestTimeout     bool                `json:"hasRequestTimeout,omitempty"`     // true (vs has request timeout) | false
-	HasTCPTrafficShifting bool                `json:"hasTCPTrafficShifting,omitempty"` // true (vs has tcp traffic shifting) | false
-	HasTrafficShifting    bool                `json:"hasTrafficShifting,omitempty"`    // true (vs has traffic shifting) | false
-	HasVS                 *VSInfo             `json:"hasVS,omitempty"`                 // it can be empty if there is a VS without hostnames
-	IsBox                 string              `json:"isBox,omitempty"`                 // set for NodeTypeBox, current values: [ 'app', 'cluster', 'namespace' ]
-	IsDead                bool                `json:"isDead,omitempty"`                // true (has no pods) | false
-	IsGateway             *GWInfo             `json:"isGateway,omitempty"`             // Istio ingress/egress gateway information
-	IsIdle                bool                `json:"isIdle,omitempty"`                // true | false
-	IsInaccessible        bool                `json:"isInaccessible,omitempty"`        // true if the node exists in an inaccessible namespace
-	IsOutside             bool                `json:"isOutside,omitempty"`             // true | false
-	IsRoot                bool                `json:"isRoot,omitempty"`                // true | false
-	IsServiceEntry        *graph.SEInfo       `json:"isServiceEntry,omitempty"`        // set static service entry information
+	NodeType              string                 `json:"nodeType"`
+	Cluster               string                 `json:"cluster"`
+	Namespace             string                 `json:"namespace"`
+	Workload              string                 `json:"workload,omitempty"`
+	App                   string                 `json:"app,omitempty"`
+	Version               string                 `json:"version,omitempty"`
+	Service               string                 `json:"service,omitempty"`               // requested service for NodeTypeService
+	Aggregate             string                 `json:"aggregate,omitempty"`             // set like "<aggregate>=<aggregateVal>"
+	BoxLabel              string                 `json:"boxLabel,omitempty"`              // value of the boxBy label, set only when boxing by label
+	WorkloadGroup         string                 `json:"workloadGroup,omitempty"`         // WorkloadGroup name, set only when boxing by workloadGroup
+	DestServices          []graph.ServiceName    `json:"destServices,omitempty"`          // requested services for [dest] node
+	Diff                  *DiffInfo              `json:"diff,omitempty"`                  // set only for diff graphs
+	EntryPoints           []string               `json:"entryPoints,omitempty"`           // hostnames of the ingress gateways reaching the node, set only for entry point graphs
+	External              graph.ExternalMetadata `json:"external,omitempty"`              // data of the external appenders, keyed by appender name
+	Traffic               []ProtocolTraffic      `json:"traffic,omitempty"`               // traffic rates for all detected protocols
+	HasCB                 bool                   `json:"hasCB,omitempty"`                 // true (has circuit breaker) | false
+	HasFaultInjection     bool                   `json:"hasFaultInjection,omitempty"`     // true (vs has fault injection) | false
+	HasHealthConfig       HealthConfig           `json:"hasHealthConfig,omitempty"`       // set to the health config override
+	HasMissingSC          bool                   `json:"hasMissingSC,omitempty"`          // true (has missing sidecar) | false
+	HasRequestRouting     bool                   `json:"hasRequestRouting,omitempty"`     // true (vs has request routing) | false
+	HasRequestTimeout     bool                   `json:"hasRequestTimeout,omitempty"`     // true (vs has request timeout) | false
+	HasTCPTrafficShifting bool                   `json:"hasTCPTrafficShifting,omitempty"` // true (vs has tcp traffic shifting) | false
+	HasTrafficShifting    bool                   `json:"hasTrafficShifting,omitempty"`    // true (vs has traffic shifting) | false
+	HasVS                 *VSInfo                `json:"hasVS,omitempty"`                 // it can be empty if there is a VS without hostnames
+	IsAnomalous           string                 `json:"isAnomalous,omitempty"`           // set to the highest anomaly score of the incoming edges
+	IsBox                 string                 `json:"isBox,omitempty"`                 // set for NodeTypeBox, current values: [ 'app', 'cluster', 'label', 'namespace', 'workloadGroup' ]
+	IsDead                bool                   `json:"isDead,omitempty"`                // true (has no pods) | false
+	IsFind                bool                   `json:"isFind,omitempty"`                // true if the node matches the find expression
+	IsGateway             *GWInfo                `json:"isGateway,omitempty"`             // Istio ingress/egress gateway information
+	IsIdle                bool                   `json:"isIdle,omitempty"`                // true | false
+	IsInaccessible        bool                   `json:"isInaccessible,omitempty"`        // true if the node exists in an inaccessible namespace
+	IsOutside             bool                   `json:"isOutside,omitempty"`             // true | false
+	IsRoot                bool                   `json:"isRoot,omitempty"`                // true | false
+	IsServiceEntry        *graph.SEInfo          `json:"isServiceEntry,omitempty"`        // set static service entry information
 }
 
 type EdgeData struct {
@@ -117,12 +151,22 @@ type EdgeData struct {
 	Target string `json:"target"` // child node ID
 
 	// App Fields (not required by Cytoscape)
-	DestPrincipal   string          `json:"destPrincipal,omitempty"`   // principal used for the edge destination
-	IsMTLS          string          `json:"isMTLS,omitempty"`          // set to the percentage of traffic using a mutual TLS connection
-	ResponseTime    string          `json:"responseTime,omitempty"`    // in millis
-	SourcePrincipal string          `json:"sourcePrincipal,omitempty"` // principal used for the edge source
-	Throughput      string          `json:"throughput,omitempty"`      // in bytes/sec (request or response, depends on client request)
-	Traffic         ProtocolTraffic `json:"traffic,omitempty"`         // traffic rates for the edge protocol
+	Authorization           string                 `json:"authorization,omitempty"`           // AuthorizationPolicy result: allow | deny | none
+	AuthorizationPolicies   []string               `json:"authorizationPolicies,omitempty"`   // namespace/name of the AuthorizationPolicies deciding the result
+	DestPrincipal           string                 `json:"destPrincipal,omitempty"`           // principal used for the edge destination
+	Diff                    *DiffInfo              `json:"diff,omitempty"`                    // set only for diff graphs
+	External                graph.ExternalMetadata `json:"external,omitempty"`                // data of the external appenders, keyed by appender name
+	IsAnomalous             string                 `json:"isAnomalous,omitempty"`             // set to the anomaly score when the edge deviates from its baseline
+	IsFind                  bool                   `json:"isFind,omitempty"`                  // true if the edge matches the find expression
+	IsMTLS                  string                 `json:"isMTLS,omitempty"`                  // set to the percentage of traffic using a mutual TLS connection
+	RequestSize             map[string]string      `json:"requestSize,omitempty"`             // request size percentiles (p50, p95, p99), in bytes
+	ResponseSize            map[string]string      `json:"responseSize,omitempty"`            // response size percentiles (p50, p95, p99), in bytes
+	ResponseTime            string                 `json:"responseTime,omitempty"`            // in millis
+	ResponseTimePercentiles map[string]string      `json:"responseTimePercentiles,omitempty"` // response time percentiles (p50, p95, p99), in millis
+	RootCauses              []RootCause            `json:"rootCauses,omitempty"`              // likely causes of the failed requests, highest percentage first
+	SourcePrincipal         string                 `json:"sourcePrincipal,omitempty"`         // principal used for the edge source
+	Throughput              string                 `json:"throughput,omitempty"`              // in bytes/sec (request or response, depends on client request)
+	Traffic                 ProtocolTraffic        `json:"traffic,omitempty"`                 // traffic rates for the edge protocol
 }
 
 type NodeWrapper struct {
@@ -145,7 +189,8 @@ type Config struct {
 	Elements  Elements `json:"elements"`
 }
 
-func nodeHash(id string) string {
+// NodeHash returns the cytoscape node ID for the TrafficMap node ID
+func NodeHash(id string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(id)))
 }
 
@@ -164,6 +209,12 @@ func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Confi
 	if strings.Contains(o.BoxBy, graph.BoxByApp) || o.GraphType == graph.GraphTypeApp || o.GraphType == graph.GraphTypeVersionedApp {
 		boxByApp(&nodes)
 	}
+	if strings.Contains(o.BoxBy, graph.BoxByLabel) {
+		boxByLabel(&nodes, strings.Contains(o.BoxBy, graph.BoxByNamespace))
+	}
+	if strings.Contains(o.BoxBy, graph.BoxByWorkloadGroup) {
+		boxByWorkloadGroup(&nodes)
+	}
 	if strings.Contains(o.BoxBy, graph.BoxByNamespace) {
 		boxByNamespace(&nodes)
 	}
@@ -182,10 +233,12 @@ func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Confi
 					return 0
 				case graph.BoxByNamespace:
 					return 1
-				case graph.BoxByApp:
+				case graph.BoxByLabel, graph.BoxByWorkloadGroup:
 					return 2
-				default:
+				case graph.BoxByApp:
 					return 3
+				default:
+					return 4
 				}
 			}
 			return rank(nodes[i].Data.IsBox) < rank(nodes[j].Data.IsBox)
@@ -227,7 +280,7 @@ func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Confi
 
 func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*EdgeWrapper, o graph.ConfigOptions) {
 	for id, n := range trafficMap {
-		nodeID := nodeHash(id)
+		nodeID := NodeHash(id)
 
 		nd := &NodeData{
 			ID:        nodeID,
@@ -252,11 +305,29 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 			nd.IsDead = val.(bool)
 		}
 
+		// node may be boxed by label or by workload group
+		if val, ok := n.Metadata[graph.BoxLabel]; ok {
+			nd.BoxLabel = val.(string)
+		}
+		if val, ok := n.Metadata[graph.WorkloadGroup]; ok {
+			nd.WorkloadGroup = val.(string)
+		}
+
 		// node may be idle
 		if val, ok := n.Metadata[graph.IsIdle]; ok {
 			nd.IsIdle = val.(bool)
 		}
 
+		// node may be receiving anomalous traffic
+		if val, ok := n.Metadata[graph.IsAnomalous]; ok {
+			nd.IsAnomalous = fmt.Sprintf("%.2f", val.(float64))
+		}
+
+		// node may match the find expression
+		if val, ok := n.Metadata[graph.IsFind]; ok {
+			nd.IsFind = val.(bool)
+		}
+
 		// node may be a root
 		if val, ok := n.Metadata[graph.IsRoot]; ok {
 			nd.IsRoot = val.(bool)
@@ -267,6 +338,16 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 			nd.IsInaccessible = val.(bool)
 		}
 
+		// node may be reachable from Istio Ingress Gateways
+		if val, ok := n.Metadata[graph.EntryPoints]; ok {
+			nd.EntryPoints = val.([]string)
+		}
+
+		// node may have external appender data
+		if val, ok := n.Metadata[graph.External]; ok {
+			nd.External = val.(graph.ExternalMetadata)
+		}
+
 		// node may represent an Istio Ingress Gateway
 		if gateways, ok := n.Metadata[graph.IsIngressGateway]; ok {
 			var configuredHostnames []string
@@ -338,6 +419,11 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 			nd.IsServiceEntry = val.(*graph.SEInfo)
 		}
 
+		// node may be part of a diff graph
+		if val, ok := n.Metadata[graph.Diff]; ok {
+			nd.Diff = &DiffInfo{Status: val.(*graph.DiffMetadata).Status}
+		}
+
 		// node may be an aggregate
 		if n.NodeType == graph.NodeTypeAggregate {
 			nd.Aggregate = fmt.Sprintf("%s=%s", n.Metadata[graph.Aggregate].(string), n.Metadata[graph.AggregateValue].(string))
@@ -350,8 +436,8 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 		*nodes = append(*nodes, &nw)
 
 		for _, e := range n.Edges {
-			sourceIDHash := nodeHash(n.ID)
-			destIDHash := nodeHash(e.Dest.ID)
+			sourceIDHash := NodeHash(n.ID)
+			destIDHash := NodeHash(e.Dest.ID)
 			protocol := ""
 			if e.Metadata[graph.ProtocolKey] != nil {
 				protocol = e.Metadata[graph.ProtocolKey].(string)
@@ -393,6 +479,7 @@ func addNodeTelemetry(n *graph.Node, nd *NodeData) {
 			}
 		}
 		if protocolTraffic.Rates != nil {
+			protocolTraffic.TimeSeries = getTimeSeries(n.Metadata, p.Name)
 			if nd.Traffic == nil {
 				nd.Traffic = []ProtocolTraffic{}
 			}
@@ -402,6 +489,21 @@ func addNodeTelemetry(n *graph.Node, nd *NodeData) {
 }
 
 func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
+	if val, ok := e.Metadata[graph.Authorization]; ok {
+		ed.Authorization = val.(string)
+	}
+	if val, ok := e.Metadata[graph.AuthorizationPolicies]; ok {
+		ed.AuthorizationPolicies = val.([]string)
+	}
+	if val, ok := e.Metadata[graph.IsAnomalous]; ok {
+		ed.IsAnomalous = fmt.Sprintf("%.2f", val.(float64))
+	}
+	if val, ok := e.Metadata[graph.External]; ok {
+		ed.External = val.(graph.ExternalMetadata)
+	}
+	if val, ok := e.Metadata[graph.IsFind]; ok {
+		ed.IsFind = val.(bool)
+	}
 	if val, ok := e.Metadata[graph.IsMTLS]; ok {
 		ed.IsMTLS = fmt.Sprintf("%.0f", val.(float64))
 	}
@@ -409,10 +511,40 @@ func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
 		responseTime := val.(float64)
 		ed.ResponseTime = fmt.Sprintf("%.0f", responseTime)
 	}
+	if val, ok := e.Metadata[graph.ResponseTimePercentiles]; ok {
+		ed.ResponseTimePercentiles = percentilesToStrings(val.(graph.PercentilesMetadata))
+	}
+	if val, ok := e.Metadata[graph.RootCauses]; ok {
+		for _, rootCause := range val.([]graph.RootCause) {
+			ed.RootCauses = append(ed.RootCauses, RootCause{
+				Cause:      rootCause.Cause,
+				Config:     rootCause.Config,
+				Flags:      rootCause.Flags,
+				Percentage: fmt.Sprintf("%.1f", rootCause.Percentage),
+			})
+		}
+	}
+	if val, ok := e.Metadata[graph.RequestSize]; ok {
+		ed.RequestSize = percentilesToStrings(val.(graph.PercentilesMetadata))
+	}
+	if val, ok := e.Metadata[graph.ResponseSize]; ok {
+		ed.ResponseSize = percentilesToStrings(val.(graph.PercentilesMetadata))
+	}
 	if val, ok := e.Metadata[graph.Throughput]; ok {
 		throughput := val.(float64)
 		ed.Throughput = fmt.Sprintf("%.0f", throughput)
 	}
+	if val, ok := e.Metadata[graph.Diff]; ok {
+		diff := val.(*graph.DiffMetadata)
+		ed.Diff = &DiffInfo{
+			Status:      diff.Status,
+			ErrorRate:   fmt.Sprintf("%+.1f", diff.ErrorRateDelta),
+			RequestRate: fmt.Sprintf("%+.2f", diff.RequestRateDelta),
+		}
+		if diff.HasResponseTime {
+			ed.Diff.ResponseTime = fmt.Sprintf("%+.0f", diff.ResponseTimeDelta)
+		}
+	}
 
 	// an edge represents traffic for at most one protocol
 	for _, p := range graph.Protocols {
@@ -482,6 +614,7 @@ func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
 						protocolTraffic.Responses[code] = responseDetail
 					}
 				}
+				protocolTraffic.TimeSeries = getTimeSeries(e.Metadata, p.Name)
 				ed.Traffic = protocolTraffic
 			}
 			break
@@ -489,6 +622,28 @@ func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
 	}
 }
 
+func getTimeSeries(md graph.Metadata, protocol string) *TimeSeries {
+	tsm, ok := md[graph.TimeSeriesKey]
+	if !ok {
+		return nil
+	}
+	ts, ok := tsm.(graph.TimeSeriesMetadata)[protocol]
+	if !ok {
+		return nil
+	}
+	result := &TimeSeries{
+		Start:    ts.Start,
+		Step:     ts.Step,
+		Rates:    make([]string, len(ts.Rates)),
+		ErrRates: make([]string, len(ts.ErrRates)),
+	}
+	for i := range ts.Rates {
+		result.Rates[i] = rateToString(2, ts.Rates[i])
+		result.ErrRates[i] = rateToString(2, ts.ErrRates[i])
+	}
+	return result
+}
+
 func getRate(md graph.Metadata, k graph.MetadataKey) float64 {
 	if rate, ok := md[k]; ok {
 		return rate.(float64)
@@ -510,6 +665,39 @@ func boxByApp(nodes *[]*NodeWrapper) {
 	generateBoxCompoundNodes(box, nodes, graph.BoxByApp)
 }
 
+// boxByLabel adds compound nodes to box top-level nodes with the same label value. Unless also boxing
+// by namespace, the label boxes may span namespaces.
+func boxByLabel(nodes *[]*NodeWrapper, perNamespace bool) {
+	box := make(map[string][]*NodeData)
+
+	for _, nw := range *nodes {
+		if nw.Data.Parent == "" && nw.Data.BoxLabel != "" {
+			namespace := ""
+			if perNamespace {
+				namespace = nw.Data.Namespace
+			}
+			k := fmt.Sprintf("box_label_%s_%s_%s", nw.Data.Cluster, namespace, nw.Data.BoxLabel)
+			box[k] = append(box[k], nw.Data)
+		}
+	}
+
+	generateBoxCompoundNodes(box, nodes, graph.BoxByLabel)
+}
+
+// boxByWorkloadGroup adds compound nodes to box top-level nodes in the same WorkloadGroup
+func boxByWorkloadGroup(nodes *[]*NodeWrapper) {
+	box := make(map[string][]*NodeData)
+
+	for _, nw := range *nodes {
+		if nw.Data.Parent == "" && nw.Data.WorkloadGroup != "" {
+			k := fmt.Sprintf("box_wg_%s_%s_%s", nw.Data.Cluster, nw.Data.Namespace, nw.Data.WorkloadGroup)
+			box[k] = append(box[k], nw.Data)
+		}
+	}
+
+	generateBoxCompoundNodes(box, nodes, graph.BoxByWorkloadGroup)
+}
+
 // boxByNamespace adds compound nodes to box nodes in the same namespace
 func boxByNamespace(nodes *[]*NodeWrapper) {
 	box := make(map[string][]*NodeData)
@@ -542,24 +730,34 @@ func generateBoxCompoundNodes(box map[string][]*NodeData, nodes *[]*NodeWrapper,
 	for k, members := range box {
 		if boxBy != graph.BoxByApp || len(members) > 1 {
 			// create the compound (parent) node for the member nodes
-			nodeID := nodeHash(k)
+			nodeID := NodeHash(k)
 			namespace := ""
 			app := ""
+			boxLabel := ""
+			workloadGroup := ""
 			switch boxBy {
 			case graph.BoxByNamespace:
 				namespace = members[0].Namespace
 			case graph.BoxByApp:
 				namespace = members[0].Namespace
 				app = members[0].App
+			case graph.BoxByLabel:
+				namespace = commonNamespace(members)
+				boxLabel = members[0].BoxLabel
+			case graph.BoxByWorkloadGroup:
+				namespace = members[0].Namespace
+				workloadGroup = members[0].WorkloadGroup
 			}
 			nd := NodeData{
-				ID:        nodeID,
-				NodeType:  graph.NodeTypeBox,
-				Cluster:   members[0].Cluster,
-				Namespace: namespace,
-				App:       app,
-				Version:   "",
-				IsBox:     boxBy,
+				ID:            nodeID,
+				NodeType:      graph.NodeTypeBox,
+				Cluster:       members[0].Cluster,
+				Namespace:     namespace,
+				App:           app,
+				Version:       "",
+				BoxLabel:      boxLabel,
+				WorkloadGroup: workloadGroup,
+				IsBox:         boxBy,
 			}
 
 			nw := NodeWrapper{
@@ -571,6 +769,10 @@ func generateBoxCompoundNodes(box map[string][]*NodeData, nodes *[]*NodeWrapper,
 			nd.IsInaccessible = false
 			nd.IsOutside = false
 
+			if boxBy == graph.BoxByApp {
+				nd.BoxLabel = members[0].BoxLabel
+				nd.WorkloadGroup = members[0].WorkloadGroup
+			}
 			for _, n := range members {
 				n.Parent = nodeID
 
@@ -579,6 +781,14 @@ func generateBoxCompoundNodes(box map[string][]*NodeData, nodes *[]*NodeWrapper,
 					nd.HasMissingSC = nd.HasMissingSC || n.HasMissingSC
 					nd.IsInaccessible = nd.IsInaccessible || n.IsInaccessible
 					nd.IsOutside = nd.IsOutside || n.IsOutside
+
+					// the app box can itself be boxed by label or workload group, if its members agree
+					if n.BoxLabel != nd.BoxLabel {
+						nd.BoxLabel = ""
+					}
+					if n.WorkloadGroup != nd.WorkloadGroup {
+						nd.WorkloadGroup = ""
+					}
 				}
 			}
 
@@ -588,6 +798,17 @@ func generateBoxCompoundNodes(box map[string][]*NodeData, nodes *[]*NodeWrapper,
 	}
 }
 
+// commonNamespace returns the namespace of the nodes, or "" if they are not all in the same namespace
+func commonNamespace(nodes []*NodeData) string {
+	namespace := nodes[0].Namespace
+	for _, n := range nodes {
+		if n.Namespace != namespace {
+			return ""
+		}
+	}
+	return namespace
+}
+
 func rateToString(minPrecision int, rateVal float64) string {
 	precision := minPrecision
 	if requiredPrecision := calcPrecision(rateVal, 5); requiredPrecision > minPrecision {
@@ -613,3 +834,11 @@ func calcPrecision(val float64, max int) int {
 	}
 	return precision
 }
+
+func percentilesToStrings(percentiles graph.PercentilesMetadata) map[string]string {
+	result := make(map[string]string, len(percentiles))
+	for k, v := range percentiles {
+		result[k] = fmt.Sprintf("%.0f", v)
+	}
+	return result
+}
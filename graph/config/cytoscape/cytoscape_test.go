@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
 )
 
 func TestRateStrings(t *testing.T) {
@@ -81,3 +83,99 @@ func TestRateStrings(t *testing.T) {
 	assert.Equal("0.0009", rateToString(2, 0.00094))
 	assert.Equal("0.0010", rateToString(2, 0.00099))
 }
+
+func TestBoxByLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := graph.NewTrafficMap()
+	addNode := func(namespace, workload, team string) *graph.Node {
+		n := graph.NewNode("east", namespace, "", namespace, workload, workload, "v1", graph.GraphTypeWorkload)
+		if team != "" {
+			n.Metadata[graph.BoxLabel] = team
+		}
+		trafficMap[n.ID] = &n
+		return &n
+	}
+	a := addNode("foo", "a", "payments")
+	b := addNode("bar", "b", "payments")
+	c := addNode("foo", "c", "search")
+	d := addNode("foo", "d", "")
+
+	boxes := func(config Config) map[string]*NodeData {
+		result := map[string]*NodeData{}
+		for _, nw := range config.Elements.Nodes {
+			if nw.Data.IsBox == graph.BoxByLabel {
+				result[nw.Data.BoxLabel+"/"+nw.Data.Namespace] = nw.Data
+			}
+		}
+		return result
+	}
+	parent := func(config Config, n *graph.Node) string {
+		for _, nw := range config.Elements.Nodes {
+			if nw.Data.ID == NodeHash(n.ID) {
+				return nw.Data.Parent
+			}
+		}
+		return "missing"
+	}
+
+	// label boxes span namespaces
+	o := graph.ConfigOptions{BoxBy: graph.BoxByLabel, CommonOptions: graph.CommonOptions{GraphType: graph.GraphTypeWorkload}}
+	config := NewConfig(trafficMap, o)
+	labelBoxes := boxes(config)
+	assert.Equal(2, len(labelBoxes))
+	assert.Equal(labelBoxes["payments/"].ID, parent(config, a))
+	assert.Equal(labelBoxes["payments/"].ID, parent(config, b))
+	assert.Equal(labelBoxes["search/foo"].ID, parent(config, c))
+	assert.Equal("", parent(config, d))
+
+	// unless also boxing by namespace
+	o.BoxBy = graph.BoxByLabel + "," + graph.BoxByNamespace
+	config = NewConfig(trafficMap, o)
+	labelBoxes = boxes(config)
+	assert.Equal(3, len(labelBoxes))
+	assert.NotEqual(parent(config, a), parent(config, b))
+	for _, box := range labelBoxes {
+		assert.NotEqual("", box.Parent)
+	}
+	// box nodes come before their members
+	rank := map[string]int{}
+	for i, nw := range config.Elements.Nodes {
+		rank[nw.Data.ID] = i
+	}
+	for _, nw := range config.Elements.Nodes {
+		if nw.Data.Parent != "" {
+			assert.Less(rank[nw.Data.Parent], rank[nw.Data.ID])
+		}
+	}
+}
+
+func TestBoxByWorkloadGroupWithApp(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := graph.NewTrafficMap()
+	for _, version := range []string{"v1", "v2"} {
+		n := graph.NewNode("east", "foo", "", "foo", "vm-"+version, "vm", version, graph.GraphTypeVersionedApp)
+		n.Metadata[graph.WorkloadGroup] = "vm-group"
+		trafficMap[n.ID] = &n
+	}
+
+	o := graph.ConfigOptions{BoxBy: graph.BoxByWorkloadGroup, CommonOptions: graph.CommonOptions{GraphType: graph.GraphTypeVersionedApp}}
+	config := NewConfig(trafficMap, o)
+
+	// the app box is boxed by the workload group of its members
+	var appBox, wgBox *NodeData
+	for _, nw := range config.Elements.Nodes {
+		switch nw.Data.IsBox {
+		case graph.BoxByApp:
+			appBox = nw.Data
+		case graph.BoxByWorkloadGroup:
+			wgBox = nw.Data
+		}
+	}
+	assert.NotNil(appBox)
+	assert.NotNil(wgBox)
+	assert.Equal("vm-group", wgBox.WorkloadGroup)
+	assert.Equal("foo", wgBox.Namespace)
+	assert.Equal(wgBox.ID, appBox.Parent)
+}
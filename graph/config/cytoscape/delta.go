@@ -0,0 +1,89 @@
+package cytoscape
+
+import (
+	"reflect"
+)
+
+// NodesDelta holds the node changes between two configs. Added and Updated provide the full node data,
+// Removed provides only the node IDs.
+type NodesDelta struct {
+	Added   []*NodeWrapper `json:"added,omitempty"`
+	Updated []*NodeWrapper `json:"updated,omitempty"`
+	Removed []string       `json:"removed,omitempty"`
+}
+
+// EdgesDelta holds the edge changes between two configs. Added and Updated provide the full edge data,
+// Removed provides only the edge IDs.
+type EdgesDelta struct {
+	Added   []*EdgeWrapper `json:"added,omitempty"`
+	Updated []*EdgeWrapper `json:"updated,omitempty"`
+	Removed []string       `json:"removed,omitempty"`
+}
+
+// Delta holds the changes between a previous config and the current config. Applying the delta to the
+// previous config elements produces the current config elements.
+type Delta struct {
+	Timestamp int64      `json:"timestamp"`
+	Duration  int64      `json:"duration"`
+	GraphType string     `json:"graphType"`
+	Nodes     NodesDelta `json:"nodes"`
+	Edges     EdgesDelta `json:"edges"`
+}
+
+// NewDelta returns the Delta between the previous and current configs. Elements are matched by ID, and
+// are reported as updated if any of their data differs. The current config order is preserved.
+func NewDelta(previous, current Config) Delta {
+	delta := Delta{
+		Timestamp: current.Timestamp,
+		Duration:  current.Duration,
+		GraphType: current.GraphType,
+	}
+
+	previousNodes := make(map[string]*NodeData, len(previous.Elements.Nodes))
+	for _, n := range previous.Elements.Nodes {
+		previousNodes[n.Data.ID] = n.Data
+	}
+	for _, n := range current.Elements.Nodes {
+		if previousNode, ok := previousNodes[n.Data.ID]; !ok {
+			delta.Nodes.Added = append(delta.Nodes.Added, n)
+		} else {
+			if !reflect.DeepEqual(previousNode, n.Data) {
+				delta.Nodes.Updated = append(delta.Nodes.Updated, n)
+			}
+			delete(previousNodes, n.Data.ID)
+		}
+	}
+	for _, n := range previous.Elements.Nodes {
+		if _, ok := previousNodes[n.Data.ID]; ok {
+			delta.Nodes.Removed = append(delta.Nodes.Removed, n.Data.ID)
+		}
+	}
+
+	previousEdges := make(map[string]*EdgeData, len(previous.Elements.Edges))
+	for _, e := range previous.Elements.Edges {
+		previousEdges[e.Data.ID] = e.Data
+	}
+	for _, e := range current.Elements.Edges {
+		if previousEdge, ok := previousEdges[e.Data.ID]; !ok {
+			delta.Edges.Added = append(delta.Edges.Added, e)
+		} else {
+			if !reflect.DeepEqual(previousEdge, e.Data) {
+				delta.Edges.Updated = append(delta.Edges.Updated, e)
+			}
+			delete(previousEdges, e.Data.ID)
+		}
+	}
+	for _, e := range previous.Elements.Edges {
+		if _, ok := previousEdges[e.Data.ID]; ok {
+			delta.Edges.Removed = append(delta.Edges.Removed, e.Data.ID)
+		}
+	}
+
+	return delta
+}
+
+// IsEmpty returns true if there are no node or edge changes
+func (d Delta) IsEmpty() bool {
+	return len(d.Nodes.Added) == 0 && len(d.Nodes.Updated) == 0 && len(d.Nodes.Removed) == 0 &&
+		len(d.Edges.Added) == 0 && len(d.Edges.Updated) == 0 && len(d.Edges.Removed) == 0
+}
@@ -0,0 +1,50 @@
+package cytoscape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDelta(t *testing.T) {
+	assert := assert.New(t)
+
+	node := func(id, workload string) *NodeWrapper {
+		return &NodeWrapper{Data: &NodeData{ID: id, Workload: workload}}
+	}
+	edge := func(id, source, target, rate string) *EdgeWrapper {
+		return &EdgeWrapper{Data: &EdgeData{ID: id, Source: source, Target: target, Traffic: ProtocolTraffic{Protocol: "http", Rates: map[string]string{"http": rate}}}}
+	}
+
+	previous := Config{
+		Timestamp: 1000,
+		Elements: Elements{
+			Nodes: []*NodeWrapper{node("a", "a"), node("b", "b"), node("c", "c")},
+			Edges: []*EdgeWrapper{edge("ab", "a", "b", "1.00"), edge("bc", "b", "c", "1.00")},
+		},
+	}
+	current := Config{
+		Timestamp: 1015,
+		Duration:  600,
+		GraphType: "workload",
+		Elements: Elements{
+			Nodes: []*NodeWrapper{node("a", "a"), node("b", "b-v2"), node("d", "d")},
+			Edges: []*EdgeWrapper{edge("ab", "a", "b", "2.00"), edge("bd", "b", "d", "1.00")},
+		},
+	}
+
+	delta := NewDelta(previous, current)
+	assert.False(delta.IsEmpty())
+	assert.Equal(int64(1015), delta.Timestamp)
+	assert.Equal(int64(600), delta.Duration)
+	assert.Equal("workload", delta.GraphType)
+	assert.Equal([]*NodeWrapper{current.Elements.Nodes[2]}, delta.Nodes.Added)
+	assert.Equal([]*NodeWrapper{current.Elements.Nodes[1]}, delta.Nodes.Updated)
+	assert.Equal([]string{"c"}, delta.Nodes.Removed)
+	assert.Equal([]*EdgeWrapper{current.Elements.Edges[1]}, delta.Edges.Added)
+	assert.Equal([]*EdgeWrapper{current.Elements.Edges[0]}, delta.Edges.Updated)
+	assert.Equal([]string{"bc"}, delta.Edges.Removed)
+
+	assert.True(NewDelta(current, current).IsEmpty())
+	assert.Equal(len(current.Elements.Nodes), len(NewDelta(Config{}, current).Nodes.Added))
+}
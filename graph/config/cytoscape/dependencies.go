@@ -0,0 +1,51 @@
+package cytoscape
+
+import (
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry"
+)
+
+// DependenciesConfig is the result of a dependency analysis. Node IDs are the IDs of the nodes in Graph, which
+// holds only the analyzed nodes, their dependencies and the edges on the dependency paths.
+type DependenciesConfig struct {
+	Roots        []string                   `json:"roots"`
+	Upstream     []string                   `json:"upstream"`
+	Downstream   []string                   `json:"downstream"`
+	Cycles       [][]string                 `json:"cycles"`
+	CriticalPath *telemetry.DependencyPath  `json:"criticalPath,omitempty"`
+	Paths        []telemetry.DependencyPath `json:"paths"`
+	Truncated    bool                       `json:"truncated,omitempty"`
+	Graph        Config                     `json:"graph"`
+}
+
+// NewDependenciesConfig returns the DependenciesConfig for the analyzed trafficMap
+func NewDependenciesConfig(trafficMap graph.TrafficMap, d *telemetry.Dependencies, o graph.ConfigOptions) DependenciesConfig {
+	result := DependenciesConfig{
+		Roots:      nodeHashes(d.Roots),
+		Upstream:   nodeHashes(d.Upstream),
+		Downstream: nodeHashes(d.Downstream),
+		Cycles:     make([][]string, len(d.Cycles)),
+		Paths:      make([]telemetry.DependencyPath, len(d.Paths)),
+		Truncated:  d.Truncated,
+		Graph:      NewConfig(telemetry.FilterDependencies(trafficMap, d), o),
+	}
+	for i, cycle := range d.Cycles {
+		result.Cycles[i] = nodeHashes(cycle)
+	}
+	for i, path := range d.Paths {
+		path.Nodes = nodeHashes(path.Nodes)
+		result.Paths[i] = path
+		if d.CriticalPath == &d.Paths[i] {
+			result.CriticalPath = &result.Paths[i]
+		}
+	}
+	return result
+}
+
+func nodeHashes(ids []string) []string {
+	hashes := make([]string, len(ids))
+	for i, id := range ids {
+		hashes[i] = NodeHash(id)
+	}
+	return hashes
+}
@@ -0,0 +1,224 @@
+// Package dot provides conversion from our graph to the Graphviz DOT language.
+//
+// The following links are useful for understanding DOT:
+//
+// Language:   https://graphviz.org/doc/info/lang.html
+// Attributes: https://graphviz.org/doc/info/attrs.html
+//
+// Algorithm: Generate the cytoscape config for the graph, which resolves all of the node and edge
+//            decorations (rates, percentages, response times, etc), and then render each node
+//            and edge as a DOT statement. Kiali-specific information is provided as custom
+//            attributes, which are ignored by the Graphviz layout engines. Compound (box) nodes
+//            are not supported.
+//
+// The package provides the DOT implementation of graph/ConfigVendor.
+package dot
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/config/util"
+)
+
+// ContentType is the media type for the DOT language
+const ContentType = "text/vnd.graphviz; charset=utf-8"
+
+// Attribute is a single DOT attribute (name=value)
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Statement is a single DOT node or edge statement
+type Statement struct {
+	ID         string      // node id, or "source" -> "target" for an edge
+	Attributes []Attribute // attributes in presentation order
+}
+
+// Config is the DOT representation of the graph. It implements graph/RawConfig.
+type Config struct {
+	Name      string
+	Label     string
+	Nodes     []Statement
+	Edges     []Statement
+	Timestamp int64
+	Duration  int64
+	GraphType string
+}
+
+// ContentType is required by the graph/RawConfig interface
+func (c Config) ContentType() string {
+	return ContentType
+}
+
+// Raw is required by the graph/RawConfig interface
+func (c Config) Raw() ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "digraph %s {\n", quote(c.Name))
+	fmt.Fprintf(&b, "  graph [label=%s, graphType=%s, duration=%d, timestamp=%d];\n", quote(c.Label), quote(c.GraphType), c.Duration, c.Timestamp)
+	for _, n := range c.Nodes {
+		fmt.Fprintf(&b, "  %s%s;\n", quote(n.ID), attributes(n.Attributes))
+	}
+	for _, e := range c.Edges {
+		fmt.Fprintf(&b, "  %s%s;\n", e.ID, attributes(e.Attributes))
+	}
+	b.WriteString("}\n")
+
+	return b.Bytes(), nil
+}
+
+// NewConfig is required by the graph/ConfigVendor interface
+func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Config) {
+	cytoscapeConfig := cytoscape.NewConfig(trafficMap, o)
+
+	result = Config{
+		Name:      "kiali",
+		Label:     fmt.Sprintf("%s graph", o.GraphType),
+		Nodes:     []Statement{},
+		Edges:     []Statement{},
+		Timestamp: cytoscapeConfig.Timestamp,
+		Duration:  cytoscapeConfig.Duration,
+		GraphType: cytoscapeConfig.GraphType,
+	}
+
+	for _, nw := range cytoscapeConfig.Elements.Nodes {
+		nd := nw.Data
+		if nd.NodeType == graph.NodeTypeBox {
+			continue
+		}
+		result.Nodes = append(result.Nodes, Statement{ID: nd.ID, Attributes: nodeAttributes(nd)})
+	}
+
+	for _, ew := range cytoscapeConfig.Elements.Edges {
+		ed := ew.Data
+		id := fmt.Sprintf("%s -> %s", quote(ed.Source), quote(ed.Target))
+		result.Edges = append(result.Edges, Statement{ID: id, Attributes: edgeAttributes(ed)})
+	}
+
+	return result
+}
+
+func nodeAttributes(nd *cytoscape.NodeData) []Attribute {
+	attrs := []Attribute{
+		{Name: "label", Value: util.NodeLabel(nd)},
+		{Name: "shape", Value: nodeShape(nd.NodeType)},
+		{Name: "nodeType", Value: nd.NodeType},
+		{Name: "cluster", Value: nd.Cluster},
+		{Name: "namespace", Value: nd.Namespace},
+	}
+	attrs = appendIfSet(attrs, "workload", nd.Workload)
+	attrs = appendIfSet(attrs, "app", nd.App)
+	attrs = appendIfSet(attrs, "version", nd.Version)
+	attrs = appendIfSet(attrs, "service", nd.Service)
+	attrs = appendIfSet(attrs, "aggregate", nd.Aggregate)
+	attrs = appendIfSet(attrs, "isAnomalous", nd.IsAnomalous)
+	if nd.IsRoot {
+		attrs = append(attrs, Attribute{Name: "isRoot", Value: "true"})
+	}
+	if nd.IsDead {
+		attrs = append(attrs, Attribute{Name: "isDead", Value: "true"})
+	}
+	if nd.IsFind {
+		attrs = append(attrs, Attribute{Name: "isFind", Value: "true"})
+	}
+	if nd.IsIdle {
+		attrs = append(attrs, Attribute{Name: "isIdle", Value: "true"})
+	}
+	if nd.IsOutside {
+		attrs = append(attrs, Attribute{Name: "isOutside", Value: "true"})
+	}
+	for _, pt := range nd.Traffic {
+		attrs = append(attrs, rateAttributes(pt.Rates)...)
+	}
+
+	return attrs
+}
+
+func edgeAttributes(ed *cytoscape.EdgeData) []Attribute {
+	attrs := []Attribute{
+		{Name: "label", Value: util.EdgeLabel(ed)},
+	}
+	attrs = appendIfSet(attrs, "protocol", ed.Traffic.Protocol)
+	attrs = append(attrs, rateAttributes(ed.Traffic.Rates)...)
+	attrs = appendIfSet(attrs, "responseTime", ed.ResponseTime)
+	attrs = appendPercentiles(attrs, "responseTime", ed.ResponseTimePercentiles)
+	attrs = appendPercentiles(attrs, "requestSize", ed.RequestSize)
+	attrs = appendPercentiles(attrs, "responseSize", ed.ResponseSize)
+	attrs = appendIfSet(attrs, "throughput", ed.Throughput)
+	attrs = appendIfSet(attrs, "isMTLS", ed.IsMTLS)
+	attrs = appendIfSet(attrs, "authorization", ed.Authorization)
+	attrs = appendIfSet(attrs, "isAnomalous", ed.IsAnomalous)
+	if ed.IsFind {
+		attrs = append(attrs, Attribute{Name: "isFind", Value: "true"})
+	}
+
+	return attrs
+}
+
+// rateAttributes returns the rates as attributes, sorted by rate name for a predictable presentation
+func rateAttributes(rates map[string]string) []Attribute {
+	attrs := []Attribute{}
+	for k, v := range rates {
+		attrs = append(attrs, Attribute{Name: k, Value: v})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Name < attrs[j].Name
+	})
+	return attrs
+}
+
+func appendPercentiles(attrs []Attribute, prefix string, percentiles map[string]string) []Attribute {
+	for _, p := range util.Percentiles {
+		if value, ok := percentiles[p]; ok {
+			attrs = append(attrs, Attribute{Name: util.PercentileName(prefix, p), Value: value})
+		}
+	}
+	return attrs
+}
+
+func appendIfSet(attrs []Attribute, name, value string) []Attribute {
+	if value == "" {
+		return attrs
+	}
+	return append(attrs, Attribute{Name: name, Value: value})
+}
+
+func nodeShape(nodeType string) string {
+	switch nodeType {
+	case graph.NodeTypeAggregate:
+		return "diamond"
+	case graph.NodeTypeApp:
+		return "box"
+	case graph.NodeTypeService:
+		return "triangle"
+	case graph.NodeTypeUnknown:
+		return "plaintext"
+	default:
+		return "ellipse"
+	}
+}
+
+func attributes(attrs []Attribute) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	formatted := make([]string, len(attrs))
+	for i, a := range attrs {
+		formatted[i] = fmt.Sprintf("%s=%s", quote(a.Name), quote(a.Value))
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(formatted, ", "))
+}
+
+// quote returns a DOT double-quoted string. Newlines are converted to DOT's centered line break.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return fmt.Sprintf(`"%s"`, s)
+}
@@ -0,0 +1,65 @@
+package dot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+func buildTrafficMap() graph.TrafficMap {
+	trafficMap := graph.NewTrafficMap()
+
+	productpage := graph.NewNode("east", "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	reviews := graph.NewNode("east", "bookinfo", "", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+
+	edge := productpage.AddEdge(&reviews)
+	edge.Metadata[graph.ProtocolKey] = "http"
+	edge.Metadata[graph.ResponseTime] = 20.0
+	graph.AddToMetadata("http", 9.0, "200", "-", "reviews", productpage.Metadata, reviews.Metadata, edge.Metadata)
+	graph.AddToMetadata("http", 1.0, "500", "-", "reviews", productpage.Metadata, reviews.Metadata, edge.Metadata)
+
+	return trafficMap
+}
+
+func TestNewConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	o := graph.ConfigOptions{
+		BoxBy: graph.BoxByNone,
+		CommonOptions: graph.CommonOptions{
+			Duration:  time.Minute,
+			GraphType: graph.GraphTypeVersionedApp,
+			QueryTime: 1523364075,
+		},
+	}
+	config := NewConfig(buildTrafficMap(), o)
+
+	assert.Equal(2, len(config.Nodes))
+	assert.Equal(1, len(config.Edges))
+	assert.Equal(ContentType, config.ContentType())
+
+	raw, err := config.Raw()
+	assert.NoError(err)
+	dot := string(raw)
+
+	assert.True(strings.HasPrefix(dot, "digraph \"kiali\" {\n"))
+	assert.Contains(dot, `graph [label="versionedApp graph", graphType="versionedApp", duration=60, timestamp=1523364075];`)
+	assert.Contains(dot, `"label"="productpage\nv1\n(bookinfo)", "shape"="box", "nodeType"="app", "cluster"="east", "namespace"="bookinfo", "workload"="productpage-v1", "app"="productpage", "version"="v1", "httpOut"="10.00"]`)
+	assert.Contains(dot, `"label"="10.00 rps\n10.0% err\n20ms", "protocol"="http", "http"="10.00", "http5xx"="1.00", "httpPercentErr"="10.0", "httpPercentReq"="100.0", "responseTime"="20"]`)
+	assert.True(strings.HasSuffix(dot, "}\n"))
+}
+
+func TestQuote(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`"foo"`, quote("foo"))
+	assert.Equal(`"foo\nbar"`, quote("foo\nbar"))
+	assert.Equal(`"say \"hi\""`, quote(`say "hi"`))
+	assert.Equal(`"a\\b"`, quote(`a\b`))
+}
@@ -0,0 +1,255 @@
+// Package graphml provides conversion from our graph to the GraphML XML format, as consumed
+// by tools like Gephi, yEd or Cytoscape Desktop.
+//
+// The following links are useful for understanding GraphML:
+//
+// Primer:        http://graphml.graphdrawing.org/primer/graphml-primer.html
+// Specification: http://graphml.graphdrawing.org/specification.html
+//
+// Algorithm: Generate the cytoscape config for the graph, which resolves all of the node and edge
+//            decorations (rates, percentages, response times, etc), and then render each node
+//            and edge as a GraphML element with a data entry for each set value. Every possible data
+//            entry is declared as a GraphML key. Compound (box) nodes are not supported.
+//
+// The package provides the GraphML implementation of graph/ConfigVendor.
+package graphml
+
+import (
+	"encoding/xml"
+	"sort"
+	"strconv"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/config/util"
+)
+
+// ContentType is the media type for GraphML
+const ContentType = "application/graphml+xml; charset=utf-8"
+
+const (
+	graphmlNamespace = "http://graphml.graphdrawing.org/xmlns"
+	typeBoolean      = "boolean"
+	typeDouble       = "double"
+	typeLong         = "long"
+	typeString       = "string"
+	forEdge          = "edge"
+	forGraph         = "graph"
+	forNode          = "node"
+)
+
+// Key declares a GraphML attribute
+type Key struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+// Data holds the value for a declared Key
+type Data struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Node is a GraphML node, with a Data entry for each set value
+type Node struct {
+	ID   string `xml:"id,attr"`
+	Data []Data `xml:"data"`
+}
+
+// Edge is a GraphML edge, with a Data entry for each set value
+type Edge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Data   []Data `xml:"data"`
+}
+
+// Graph is the GraphML graph element, edges are directed by default
+type Graph struct {
+	ID          string `xml:"id,attr"`
+	EdgeDefault string `xml:"edgedefault,attr"`
+	Data        []Data `xml:"data"`
+	Nodes       []Node `xml:"node"`
+	Edges       []Edge `xml:"edge"`
+}
+
+// Config is the GraphML document for the graph. It implements graph/RawConfig.
+type Config struct {
+	XMLName xml.Name `xml:"graphml"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Keys    []Key    `xml:"key"`
+	Graph   Graph    `xml:"graph"`
+}
+
+// ContentType is required by the graph/RawConfig interface
+func (c Config) ContentType() string {
+	return ContentType
+}
+
+// Raw is required by the graph/RawConfig interface
+func (c Config) Raw() ([]byte, error) {
+	doc, err := xml.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(doc, '\n')...), nil
+}
+
+// NewConfig is required by the graph/ConfigVendor interface
+func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Config) {
+	cytoscapeConfig := cytoscape.NewConfig(trafficMap, o)
+
+	result = Config{
+		XMLNS: graphmlNamespace,
+		Keys:  keys(),
+		Graph: Graph{
+			ID:          "kiali",
+			EdgeDefault: "directed",
+			Data: []Data{
+				{Key: "graphType", Value: cytoscapeConfig.GraphType},
+				{Key: "duration", Value: strconv.FormatInt(cytoscapeConfig.Duration, 10)},
+				{Key: "timestamp", Value: strconv.FormatInt(cytoscapeConfig.Timestamp, 10)},
+			},
+			Nodes: []Node{},
+			Edges: []Edge{},
+		},
+	}
+
+	for _, nw := range cytoscapeConfig.Elements.Nodes {
+		nd := nw.Data
+		if nd.NodeType == graph.NodeTypeBox {
+			continue
+		}
+		result.Graph.Nodes = append(result.Graph.Nodes, Node{ID: nd.ID, Data: nodeData(nd)})
+	}
+
+	for _, ew := range cytoscapeConfig.Elements.Edges {
+		ed := ew.Data
+		result.Graph.Edges = append(result.Graph.Edges, Edge{ID: ed.ID, Source: ed.Source, Target: ed.Target, Data: edgeData(ed)})
+	}
+
+	return result
+}
+
+// keys declares every attribute that may be reported for the graph, nodes and edges.
+func keys() []Key {
+	result := []Key{
+		{ID: "graphType", For: forGraph, Name: "graphType", Type: typeString},
+		{ID: "duration", For: forGraph, Name: "duration", Type: typeLong},
+		{ID: "timestamp", For: forGraph, Name: "timestamp", Type: typeLong},
+		{ID: "nodeType", For: forNode, Name: "nodeType", Type: typeString},
+		{ID: "cluster", For: forNode, Name: "cluster", Type: typeString},
+		{ID: "namespace", For: forNode, Name: "namespace", Type: typeString},
+		{ID: "workload", For: forNode, Name: "workload", Type: typeString},
+		{ID: "app", For: forNode, Name: "app", Type: typeString},
+		{ID: "version", For: forNode, Name: "version", Type: typeString},
+		{ID: "service", For: forNode, Name: "service", Type: typeString},
+		{ID: "aggregate", For: forNode, Name: "aggregate", Type: typeString},
+		{ID: "isAnomalous", For: forNode, Name: "isAnomalous", Type: typeDouble},
+		{ID: "isDead", For: forNode, Name: "isDead", Type: typeBoolean},
+		{ID: "isFind", For: forNode, Name: "isFind", Type: typeBoolean},
+		{ID: "isIdle", For: forNode, Name: "isIdle", Type: typeBoolean},
+		{ID: "isOutside", For: forNode, Name: "isOutside", Type: typeBoolean},
+		{ID: "isRoot", For: forNode, Name: "isRoot", Type: typeBoolean},
+		{ID: "protocol", For: forEdge, Name: "protocol", Type: typeString},
+		{ID: "responseTime", For: forEdge, Name: "responseTime", Type: typeDouble},
+		{ID: "throughput", For: forEdge, Name: "throughput", Type: typeDouble},
+		{ID: "isMTLS", For: forEdge, Name: "isMTLS", Type: typeDouble},
+		{ID: "authorization", For: forEdge, Name: "authorization", Type: typeString},
+		{ID: "edgeIsAnomalous", For: forEdge, Name: "isAnomalous", Type: typeDouble},
+		{ID: "edgeIsFind", For: forEdge, Name: "isFind", Type: typeBoolean},
+	}
+	for _, prefix := range []string{"responseTime", "requestSize", "responseSize"} {
+		for _, p := range util.Percentiles {
+			name := util.PercentileName(prefix, p)
+			result = append(result, Key{ID: name, For: forEdge, Name: name, Type: typeDouble})
+		}
+	}
+	for _, p := range graph.Protocols {
+		for _, r := range p.NodeRates {
+			result = append(result, Key{ID: string(r.Name), For: forNode, Name: string(r.Name), Type: typeDouble})
+		}
+		for _, r := range p.EdgeRates {
+			result = append(result, Key{ID: string(r.Name), For: forEdge, Name: string(r.Name), Type: typeDouble})
+		}
+	}
+	return result
+}
+
+func nodeData(nd *cytoscape.NodeData) []Data {
+	data := []Data{
+		{Key: "nodeType", Value: nd.NodeType},
+		{Key: "cluster", Value: nd.Cluster},
+		{Key: "namespace", Value: nd.Namespace},
+	}
+	data = appendIfSet(data, "workload", nd.Workload)
+	data = appendIfSet(data, "app", nd.App)
+	data = appendIfSet(data, "version", nd.Version)
+	data = appendIfSet(data, "service", nd.Service)
+	data = appendIfSet(data, "aggregate", nd.Aggregate)
+	data = appendIfSet(data, "isAnomalous", nd.IsAnomalous)
+	data = appendIfTrue(data, "isDead", nd.IsDead)
+	data = appendIfTrue(data, "isFind", nd.IsFind)
+	data = appendIfTrue(data, "isIdle", nd.IsIdle)
+	data = appendIfTrue(data, "isOutside", nd.IsOutside)
+	data = appendIfTrue(data, "isRoot", nd.IsRoot)
+	for _, pt := range nd.Traffic {
+		data = append(data, rateData(pt.Rates)...)
+	}
+
+	return data
+}
+
+func edgeData(ed *cytoscape.EdgeData) []Data {
+	data := []Data{}
+	data = appendIfSet(data, "protocol", ed.Traffic.Protocol)
+	data = appendIfSet(data, "responseTime", ed.ResponseTime)
+	data = appendPercentiles(data, "responseTime", ed.ResponseTimePercentiles)
+	data = appendPercentiles(data, "requestSize", ed.RequestSize)
+	data = appendPercentiles(data, "responseSize", ed.ResponseSize)
+	data = appendIfSet(data, "throughput", ed.Throughput)
+	data = appendIfSet(data, "isMTLS", ed.IsMTLS)
+	data = appendIfSet(data, "authorization", ed.Authorization)
+	data = appendIfSet(data, "edgeIsAnomalous", ed.IsAnomalous)
+	data = appendIfTrue(data, "edgeIsFind", ed.IsFind)
+	data = append(data, rateData(ed.Traffic.Rates)...)
+
+	return data
+}
+
+// rateData returns the rates as data, sorted by rate name for a predictable presentation
+func rateData(rates map[string]string) []Data {
+	data := []Data{}
+	for k, v := range rates {
+		data = append(data, Data{Key: k, Value: v})
+	}
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Key < data[j].Key
+	})
+	return data
+}
+
+func appendPercentiles(data []Data, prefix string, percentiles map[string]string) []Data {
+	for _, p := range util.Percentiles {
+		if value, ok := percentiles[p]; ok {
+			data = append(data, Data{Key: util.PercentileName(prefix, p), Value: value})
+		}
+	}
+	return data
+}
+
+func appendIfSet(data []Data, key, value string) []Data {
+	if value == "" {
+		return data
+	}
+	return append(data, Data{Key: key, Value: value})
+}
+
+func appendIfTrue(data []Data, key string, value bool) []Data {
+	if !value {
+		return data
+	}
+	return append(data, Data{Key: key, Value: "true"})
+}
@@ -0,0 +1,69 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+func TestNewConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := graph.NewTrafficMap()
+	productpage := graph.NewNode("east", "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeWorkload)
+	reviews := graph.NewNode("east", "bookinfo", "", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeWorkload)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+	edge := productpage.AddEdge(&reviews)
+	edge.Metadata[graph.ProtocolKey] = "http"
+	edge.Metadata[graph.ResponseTime] = 20.0
+	graph.AddToMetadata("http", 10.0, "200", "-", "reviews", productpage.Metadata, reviews.Metadata, edge.Metadata)
+
+	o := graph.ConfigOptions{
+		BoxBy: graph.BoxByNone,
+		CommonOptions: graph.CommonOptions{
+			Duration:  time.Minute,
+			GraphType: graph.GraphTypeWorkload,
+			QueryTime: 1523364075,
+		},
+	}
+	config := NewConfig(trafficMap, o)
+
+	assert.Equal(2, len(config.Graph.Nodes))
+	assert.Equal(1, len(config.Graph.Edges))
+
+	// every data entry must be declared as a key
+	keyIDs := make(map[string]bool)
+	for _, k := range config.Keys {
+		assert.False(keyIDs[k.ID], "duplicate key [%s]", k.ID)
+		keyIDs[k.ID] = true
+	}
+	for _, n := range config.Graph.Nodes {
+		for _, d := range n.Data {
+			assert.True(keyIDs[d.Key], "undeclared key [%s]", d.Key)
+		}
+	}
+	e := config.Graph.Edges[0]
+	for _, d := range e.Data {
+		assert.True(keyIDs[d.Key], "undeclared key [%s]", d.Key)
+	}
+	assert.Contains(e.Data, Data{Key: "protocol", Value: "http"})
+	assert.Contains(e.Data, Data{Key: "responseTime", Value: "20"})
+	assert.Contains(e.Data, Data{Key: "http", Value: "10.00"})
+
+	raw, err := config.Raw()
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(string(raw), xml.Header))
+	assert.Contains(string(raw), `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	assert.Contains(string(raw), `<graph id="kiali" edgedefault="directed">`)
+
+	// make sure the document round-trips
+	var parsed Config
+	assert.NoError(xml.Unmarshal(raw, &parsed))
+	assert.Equal(config.Graph, parsed.Graph)
+}
@@ -0,0 +1,108 @@
+// Package jgf provides conversion from our graph to the JSON Graph Format (JGF, version 2).
+//
+// The following links are useful for understanding JGF:
+//
+// Main page:   https://jsongraphformat.info/
+// JSON schema: https://jsongraphformat.info/v2.0/json-graph-schema.json
+//
+// Algorithm: Generate the cytoscape config for the graph, which resolves all of the node and edge
+//            decorations (rates, percentages, response times, etc), and then provide each node
+//            and edge with the cytoscape data as JGF metadata. Compound (box) nodes are not supported.
+//
+// The package provides the JGF implementation of graph/ConfigVendor.
+package jgf
+
+import (
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/config/util"
+)
+
+// GraphMetadata holds the graph-level information
+type GraphMetadata struct {
+	Duration  int64  `json:"duration"`
+	GraphType string `json:"graphType"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Node is a JGF node, its metadata is the cytoscape node data
+type Node struct {
+	Label    string              `json:"label,omitempty"`
+	Metadata *cytoscape.NodeData `json:"metadata"`
+}
+
+// Edge is a directed JGF edge, its metadata is the cytoscape edge data
+type Edge struct {
+	ID       string              `json:"id"`
+	Source   string              `json:"source"`
+	Target   string              `json:"target"`
+	Relation string              `json:"relation,omitempty"` // the edge protocol
+	Directed bool                `json:"directed"`
+	Label    string              `json:"label,omitempty"`
+	Metadata *cytoscape.EdgeData `json:"metadata"`
+}
+
+// Graph is the JGF graph, nodes are keyed by node ID
+type Graph struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Label    string          `json:"label"`
+	Directed bool            `json:"directed"`
+	Metadata GraphMetadata   `json:"metadata"`
+	Nodes    map[string]Node `json:"nodes"` // key=node ID
+	Edges    []Edge          `json:"edges"`
+}
+
+// Config is the JGF single-graph document
+type Config struct {
+	Graph Graph `json:"graph"`
+}
+
+// NewConfig is required by the graph/ConfigVendor interface
+func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Config) {
+	cytoscapeConfig := cytoscape.NewConfig(trafficMap, o)
+
+	result = Config{
+		Graph: Graph{
+			ID:       "kiali",
+			Type:     "kiali." + cytoscapeConfig.GraphType,
+			Label:    cytoscapeConfig.GraphType + " graph",
+			Directed: true,
+			Metadata: GraphMetadata{
+				Duration:  cytoscapeConfig.Duration,
+				GraphType: cytoscapeConfig.GraphType,
+				Timestamp: cytoscapeConfig.Timestamp,
+			},
+			Nodes: make(map[string]Node),
+			Edges: []Edge{},
+		},
+	}
+
+	for _, nw := range cytoscapeConfig.Elements.Nodes {
+		nd := nw.Data
+		if nd.NodeType == graph.NodeTypeBox {
+			continue
+		}
+		// box nodes are not supported, so parent references are meaningless
+		nd.Parent = ""
+		result.Graph.Nodes[nd.ID] = Node{
+			Label:    util.NodeLabel(nd),
+			Metadata: nd,
+		}
+	}
+
+	for _, ew := range cytoscapeConfig.Elements.Edges {
+		ed := ew.Data
+		result.Graph.Edges = append(result.Graph.Edges, Edge{
+			ID:       ed.ID,
+			Source:   ed.Source,
+			Target:   ed.Target,
+			Relation: ed.Traffic.Protocol,
+			Directed: true,
+			Label:    util.EdgeLabel(ed),
+			Metadata: ed,
+		})
+	}
+
+	return result
+}
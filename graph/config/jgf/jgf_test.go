@@ -0,0 +1,53 @@
+package jgf
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+func TestNewConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := graph.NewTrafficMap()
+	productpage := graph.NewNode("east", "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeApp)
+	reviews := graph.NewNode("east", "bookinfo", "", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeApp)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+	edge := productpage.AddEdge(&reviews)
+	edge.Metadata[graph.ProtocolKey] = "tcp"
+	graph.AddToMetadata("tcp", 150.0, "", "-", "reviews", productpage.Metadata, reviews.Metadata, edge.Metadata)
+
+	o := graph.ConfigOptions{
+		BoxBy: graph.BoxByNone,
+		CommonOptions: graph.CommonOptions{
+			Duration:  time.Minute,
+			GraphType: graph.GraphTypeApp,
+			QueryTime: 1523364075,
+		},
+	}
+	config := NewConfig(trafficMap, o)
+
+	assert.Equal("kiali.app", config.Graph.Type)
+	assert.True(config.Graph.Directed)
+	assert.Equal(int64(60), config.Graph.Metadata.Duration)
+	assert.Equal(int64(1523364075), config.Graph.Metadata.Timestamp)
+	assert.Equal(2, len(config.Graph.Nodes))
+	assert.Equal(1, len(config.Graph.Edges))
+
+	e := config.Graph.Edges[0]
+	source, ok := config.Graph.Nodes[e.Source]
+	assert.True(ok)
+	assert.Equal("productpage\n(bookinfo)", source.Label)
+	assert.Equal("", source.Metadata.Parent)
+	assert.Equal("tcp", e.Relation)
+	assert.Equal("150.00 bps", e.Label)
+	assert.Equal("150.00", e.Metadata.Traffic.Rates["tcp"])
+
+	_, err := json.Marshal(config)
+	assert.NoError(err)
+}
@@ -0,0 +1,73 @@
+// Package util provides functions shared by the config vendors that build on the cytoscape config.
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+)
+
+// NodeLabel returns a human readable label for the node, similar to what is presented in the Kiali UI
+func NodeLabel(nd *cytoscape.NodeData) string {
+	var label string
+	switch nd.NodeType {
+	case graph.NodeTypeAggregate:
+		label = nd.Aggregate
+	case graph.NodeTypeApp:
+		label = nd.App
+		if nd.Version != "" {
+			label = fmt.Sprintf("%s\n%s", label, nd.Version)
+		}
+	case graph.NodeTypeService:
+		label = nd.Service
+	case graph.NodeTypeUnknown:
+		label = graph.Unknown
+	default:
+		label = nd.Workload
+	}
+	if nd.Namespace != "" && nd.Namespace != graph.Unknown {
+		label = fmt.Sprintf("%s\n(%s)", label, nd.Namespace)
+	}
+	return label
+}
+
+// EdgeLabel returns a human readable label for the edge, showing the primary rate and, if applicable,
+// the error percentage and response time.
+func EdgeLabel(ed *cytoscape.EdgeData) string {
+	protocol := ed.Traffic.Protocol
+	if protocol == "" {
+		return ""
+	}
+	parts := []string{}
+	for _, p := range graph.Protocols {
+		if p.Name != protocol {
+			continue
+		}
+		for _, r := range p.EdgeRates {
+			val, ok := ed.Traffic.Rates[string(r.Name)]
+			if !ok {
+				continue
+			}
+			switch {
+			case r.IsTotal:
+				parts = append(parts, fmt.Sprintf("%s %s", val, p.UnitShort))
+			case r.IsPercentErr:
+				parts = append(parts, fmt.Sprintf("%s%% err", val))
+			}
+		}
+	}
+	if ed.ResponseTime != "" {
+		parts = append(parts, fmt.Sprintf("%sms", ed.ResponseTime))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Percentiles are the percentile names that may be reported for an edge (see graph.PercentilesMetadata)
+var Percentiles = []string{"p50", "p95", "p99"}
+
+// PercentileName returns a flat attribute name for a percentile value, e.g. responseTimeP95
+func PercentileName(prefix, percentile string) string {
+	return prefix + strings.ToUpper(percentile[:1]) + percentile[1:]
+}
@@ -0,0 +1,507 @@
+package graph
+
+// Find.go supports server-side find and hide, using the expression grammar of the graph find/hide fields
+// in the Kiali UI (see config.GraphFindOption). An expression is one or more terms joined by AND (&&) or
+// OR (||), but not both. A term is one of:
+//   <attribute> <operator> <value>  where operator is one of: = != < <= > >= *= !*= ^= !^= $= !$=
+//   [!]<boolean attribute>
+// Terms apply to either nodes or edges, an expression can not mix node and edge terms. Attribute names
+// are case insensitive, see findAttributes for the supported attributes and their aliases.
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type findKind int
+
+const (
+	findBool findKind = iota
+	findNumber
+	findString
+)
+
+type findAttribute struct {
+	name   string // canonical name
+	isEdge bool
+	kind   findKind
+}
+
+// findAttributes maps each supported attribute name, or alias, to the attribute
+var findAttributes = map[string]findAttribute{}
+
+func init() {
+	for _, a := range []struct {
+		names  []string
+		isEdge bool
+		kind   findKind
+	}{
+		// node attributes
+		{names: []string{"anomalous"}, kind: findBool},
+		{names: []string{"app"}, kind: findString},
+		{names: []string{"circuitbreaker", "cb"}, kind: findBool},
+		{names: []string{"cluster"}, kind: findString},
+		{names: []string{"dead"}, kind: findBool},
+		{names: []string{"faultinjection", "fi"}, kind: findBool},
+		{names: []string{"grpcin"}, kind: findNumber},
+		{names: []string{"grpcout"}, kind: findNumber},
+		{names: []string{"httpin"}, kind: findNumber},
+		{names: []string{"httpout"}, kind: findNumber},
+		{names: []string{"idle"}, kind: findBool},
+		{names: []string{"inaccessible"}, kind: findBool},
+		{names: []string{"name"}, kind: findString},
+		{names: []string{"namespace", "ns"}, kind: findString},
+		{names: []string{"node"}, kind: findString},
+		{names: []string{"operation", "op"}, kind: findString},
+		{names: []string{"outside", "outsider"}, kind: findBool},
+		{names: []string{"requestrouting", "rr"}, kind: findBool},
+		{names: []string{"requesttimeout", "rto"}, kind: findBool},
+		{names: []string{"service", "svc"}, kind: findString},
+		{names: []string{"serviceentry", "se"}, kind: findBool},
+		{names: []string{"sidecar", "sc"}, kind: findBool},
+		{names: []string{"tcpin"}, kind: findNumber},
+		{names: []string{"tcpout"}, kind: findNumber},
+		{names: []string{"tcptrafficshifting", "tcpts"}, kind: findBool},
+		{names: []string{"trafficshifting", "ts"}, kind: findBool},
+		{names: []string{"trafficsource", "root"}, kind: findBool},
+		{names: []string{"version"}, kind: findString},
+		{names: []string{"virtualservice", "vs"}, kind: findBool},
+		{names: []string{"workload", "wl"}, kind: findString},
+		// edge attributes
+		{names: []string{"%error", "%err"}, isEdge: true, kind: findNumber},
+		{names: []string{"%grpcerror", "%grpcerr"}, isEdge: true, kind: findNumber},
+		{names: []string{"%httperror", "%httperr"}, isEdge: true, kind: findNumber},
+		{names: []string{"authorization", "authz"}, isEdge: true, kind: findString},
+		{names: []string{"grpc"}, isEdge: true, kind: findNumber},
+		{names: []string{"http"}, isEdge: true, kind: findNumber},
+		{names: []string{"mtls"}, isEdge: true, kind: findBool},
+		{names: []string{"protocol"}, isEdge: true, kind: findString},
+		{names: []string{"rpt", "rate", "rps"}, isEdge: true, kind: findNumber},
+		{names: []string{"responsetime", "rt"}, isEdge: true, kind: findNumber},
+		{names: []string{"tcp"}, isEdge: true, kind: findNumber},
+		{names: []string{"throughput", "tp"}, isEdge: true, kind: findNumber},
+		{names: []string{"traffic"}, isEdge: true, kind: findBool},
+	} {
+		for _, name := range a.names {
+			findAttributes[name] = findAttribute{name: a.names[0], isEdge: a.isEdge, kind: a.kind}
+		}
+	}
+}
+
+var (
+	findConnectorRegexp = regexp.MustCompile(`(?i)\s+(and|or)\s+|\s*(&&|\|\|)\s*`)
+	findOperationRegexp = regexp.MustCompile(`^([%\w]+)\s*(!\*=|!\^=|!\$=|\*=|\^=|\$=|!=|<=|>=|=|<|>)\s*(\S.*)$`)
+	findUnaryRegexp     = regexp.MustCompile(`^(!?)\s*([%\w]+)$`)
+)
+
+type findTerm struct {
+	attribute findAttribute
+	negate    bool // for unary terms
+	number    float64
+	operator  string
+	value     string // lower case
+}
+
+// FindExpression is a parsed find or hide expression
+type FindExpression struct {
+	Expression string
+	IsEdge     bool // true if the expression applies to edges, false if it applies to nodes
+	isOr       bool
+	terms      []findTerm
+}
+
+// ParseFindExpression returns the parsed expression, or an error if it is not valid
+func ParseFindExpression(expression string) (*FindExpression, error) {
+	result := &FindExpression{Expression: expression}
+
+	connectors := findConnectorRegexp.FindAllStringSubmatch(expression, -1)
+	for i, c := range connectors {
+		isOr := strings.EqualFold(c[1], "or") || c[2] == "||"
+		if i > 0 && isOr != result.isOr {
+			return nil, fmt.Errorf("Invalid expression [%s], can not mix AND and OR", expression)
+		}
+		result.isOr = isOr
+	}
+
+	for i, termString := range findConnectorRegexp.Split(expression, -1) {
+		term, err := parseFindTerm(strings.TrimSpace(termString))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid expression [%s], %s", expression, err.Error())
+		}
+		if i == 0 {
+			result.IsEdge = term.attribute.isEdge
+		} else if result.IsEdge != term.attribute.isEdge {
+			return nil, fmt.Errorf("Invalid expression [%s], can not mix node and edge attributes", expression)
+		}
+		result.terms = append(result.terms, term)
+	}
+
+	return result, nil
+}
+
+func parseFindTerm(termString string) (findTerm, error) {
+	if match := findOperationRegexp.FindStringSubmatch(termString); match != nil {
+		attribute, err := getFindAttribute(match[1])
+		if err != nil {
+			return findTerm{}, err
+		}
+		term := findTerm{attribute: attribute, operator: match[2], value: strings.ToLower(strings.TrimSpace(match[3]))}
+		switch attribute.kind {
+		case findBool:
+			return term, fmt.Errorf("attribute [%s] does not accept an operator", match[1])
+		case findNumber:
+			switch term.operator {
+			case "=", "!=", "<", "<=", ">", ">=":
+			default:
+				return term, fmt.Errorf("operator [%s] is not supported for numeric attribute [%s]", term.operator, match[1])
+			}
+			number, err := strconv.ParseFloat(term.value, 64)
+			if err != nil {
+				return term, fmt.Errorf("attribute [%s] expects a number, not [%s]", match[1], match[3])
+			}
+			term.number = number
+		case findString:
+			switch term.operator {
+			case "<", "<=", ">", ">=":
+				return term, fmt.Errorf("operator [%s] is not supported for attribute [%s]", term.operator, match[1])
+			}
+			if attribute.name == "node" {
+				term.value = normalizeFindNodeType(term.value)
+			}
+		}
+		return term, nil
+	}
+
+	if match := findUnaryRegexp.FindStringSubmatch(termString); match != nil {
+		attribute, err := getFindAttribute(match[2])
+		if err != nil {
+			return findTerm{}, err
+		}
+		if attribute.kind != findBool {
+			return findTerm{}, fmt.Errorf("attribute [%s] requires an operator and value", match[2])
+		}
+		return findTerm{attribute: attribute, negate: match[1] == "!"}, nil
+	}
+
+	return findTerm{}, fmt.Errorf("can not parse [%s]", termString)
+}
+
+func getFindAttribute(name string) (findAttribute, error) {
+	lowerName := strings.ToLower(name)
+	if attribute, ok := findAttributes[lowerName]; ok {
+		return attribute, nil
+	}
+	if lowerName == "healthy" {
+		return findAttribute{}, fmt.Errorf("attribute [%s] is evaluated only by the UI", name)
+	}
+	return findAttribute{}, fmt.Errorf("unknown attribute [%s]", name)
+}
+
+func normalizeFindNodeType(nodeType string) string {
+	switch nodeType {
+	case "op", "operation":
+		return NodeTypeAggregate
+	case "svc":
+		return NodeTypeService
+	case "wl":
+		return NodeTypeWorkload
+	}
+	return nodeType
+}
+
+// MatchNode returns true if the node matches a node expression. It returns false for an edge expression.
+func (fe *FindExpression) MatchNode(n *Node) bool {
+	if fe.IsEdge {
+		return false
+	}
+	return fe.match(func(t findTerm) bool { return t.matchNode(n) })
+}
+
+// MatchEdge returns true if the edge matches an edge expression. It returns false for a node expression.
+func (fe *FindExpression) MatchEdge(e *Edge) bool {
+	if !fe.IsEdge {
+		return false
+	}
+	return fe.match(func(t findTerm) bool { return t.matchEdge(e) })
+}
+
+func (fe *FindExpression) match(matchTerm func(t findTerm) bool) bool {
+	for _, t := range fe.terms {
+		if matchTerm(t) == fe.isOr {
+			return fe.isOr
+		}
+	}
+	return !fe.isOr
+}
+
+func (t findTerm) matchNode(n *Node) bool {
+	md := n.Metadata
+	switch t.attribute.name {
+	case "anomalous":
+		return t.matchBool(isFindSet(md, IsAnomalous))
+	case "app":
+		return t.matchString(n.App)
+	case "circuitbreaker":
+		return t.matchBool(isFindSet(md, HasCB))
+	case "cluster":
+		return t.matchString(n.Cluster)
+	case "dead":
+		return t.matchBool(isFindSet(md, IsDead))
+	case "faultinjection":
+		return t.matchBool(isFindSet(md, HasFaultInjection))
+	case "grpcin":
+		return t.matchNumber(getFindNumber(md, grpcIn))
+	case "grpcout":
+		return t.matchNumber(getFindNumber(md, grpcOut))
+	case "httpin":
+		return t.matchNumber(getFindNumber(md, httpIn))
+	case "httpout":
+		return t.matchNumber(getFindNumber(md, httpOut))
+	case "idle":
+		return t.matchBool(isFindSet(md, IsIdle))
+	case "inaccessible":
+		return t.matchBool(isFindSet(md, IsInaccessible))
+	case "name":
+		// the name may be any of the node's names, a negative operator requires that none match
+		names := []string{n.App, n.Service, n.Workload}
+		if strings.HasPrefix(t.operator, "!") {
+			for _, name := range names {
+				if name != "" && !t.matchString(name) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, name := range names {
+			if name != "" && t.matchString(name) {
+				return true
+			}
+		}
+		return false
+	case "namespace":
+		return t.matchString(n.Namespace)
+	case "node":
+		return t.matchString(n.NodeType)
+	case "operation":
+		aggregateValue, _ := md[AggregateValue].(string)
+		return t.matchString(aggregateValue)
+	case "outside":
+		return t.matchBool(isFindSet(md, IsOutside))
+	case "requestrouting":
+		return t.matchBool(isFindSet(md, HasRequestRouting))
+	case "requesttimeout":
+		return t.matchBool(isFindSet(md, HasRequestTimeout))
+	case "service":
+		return t.matchString(n.Service)
+	case "serviceentry":
+		return t.matchBool(isFindSet(md, IsServiceEntry))
+	case "sidecar":
+		return t.matchBool(!isFindSet(md, HasMissingSC))
+	case "tcpin":
+		return t.matchNumber(getFindNumber(md, tcpIn))
+	case "tcpout":
+		return t.matchNumber(getFindNumber(md, tcpOut))
+	case "tcptrafficshifting":
+		return t.matchBool(isFindSet(md, HasTCPTrafficShifting))
+	case "trafficshifting":
+		return t.matchBool(isFindSet(md, HasTrafficShifting))
+	case "trafficsource":
+		return t.matchBool(isFindSet(md, IsRoot))
+	case "version":
+		return t.matchString(n.Version)
+	case "virtualservice":
+		return t.matchBool(isFindSet(md, HasVS))
+	case "workload":
+		return t.matchString(n.Workload)
+	}
+	return false
+}
+
+func (t findTerm) matchEdge(e *Edge) bool {
+	md := e.Metadata
+	protocol, _ := md[ProtocolKey].(string)
+	switch t.attribute.name {
+	case "%error":
+		_, percentErr := findEdgeTraffic(e, protocol)
+		return t.matchNumber(percentErr)
+	case "%grpcerror":
+		_, percentErr := findEdgeTraffic(e, grpc)
+		return t.matchNumber(percentErr)
+	case "%httperror":
+		_, percentErr := findEdgeTraffic(e, http)
+		return t.matchNumber(percentErr)
+	case "authorization":
+		authorization, _ := md[Authorization].(string)
+		return t.matchString(authorization)
+	case "grpc":
+		return t.matchNumber(getFindNumber(md, grpc))
+	case "http":
+		return t.matchNumber(getFindNumber(md, http))
+	case "mtls":
+		return t.matchBool(getFindNumber(md, IsMTLS) > 0.0)
+	case "protocol":
+		return t.matchString(protocol)
+	case "rpt":
+		total, _ := findEdgeTraffic(e, protocol)
+		return t.matchNumber(total)
+	case "responsetime":
+		return t.matchNumber(getFindNumber(md, ResponseTime))
+	case "tcp":
+		return t.matchNumber(getFindNumber(md, tcp))
+	case "throughput":
+		return t.matchNumber(getFindNumber(md, Throughput))
+	case "traffic":
+		total, _ := findEdgeTraffic(e, protocol)
+		return t.matchBool(total > 0.0)
+	}
+	return false
+}
+
+func (t findTerm) matchBool(val bool) bool {
+	return val != t.negate
+}
+
+func (t findTerm) matchNumber(val float64) bool {
+	switch t.operator {
+	case "=":
+		return val == t.number
+	case "!=":
+		return val != t.number
+	case "<":
+		return val < t.number
+	case "<=":
+		return val <= t.number
+	case ">":
+		return val > t.number
+	case ">=":
+		return val >= t.number
+	}
+	return false
+}
+
+func (t findTerm) matchString(val string) bool {
+	val = strings.ToLower(val)
+	switch t.operator {
+	case "=":
+		return val == t.value
+	case "!=":
+		return val != t.value
+	case "*=":
+		return strings.Contains(val, t.value)
+	case "!*=":
+		return !strings.Contains(val, t.value)
+	case "^=":
+		return strings.HasPrefix(val, t.value)
+	case "!^=":
+		return !strings.HasPrefix(val, t.value)
+	case "$=":
+		return strings.HasSuffix(val, t.value)
+	case "!$=":
+		return !strings.HasSuffix(val, t.value)
+	}
+	return false
+}
+
+// isFindSet returns true if the metadata value is true, or for a non-boolean value, if it is present
+func isFindSet(md Metadata, k MetadataKey) bool {
+	val, ok := md[k]
+	if !ok {
+		return false
+	}
+	if b, isBool := val.(bool); isBool {
+		return b
+	}
+	return true
+}
+
+func getFindNumber(md Metadata, k MetadataKey) float64 {
+	if val, ok := md[k].(float64); ok {
+		return val
+	}
+	return 0.0
+}
+
+// findEdgeTraffic returns the total rate for the protocol, and the percentage of that traffic in error. The
+// rates are 0 if the edge is not using the protocol.
+func findEdgeTraffic(e *Edge, protocol string) (total, percentErr float64) {
+	if e.Metadata[ProtocolKey] != protocol {
+		return 0.0, 0.0
+	}
+	for _, p := range Protocols {
+		if p.Name != protocol {
+			continue
+		}
+		err := 0.0
+		for _, r := range p.EdgeRates {
+			switch {
+			case r.IsTotal:
+				total = getFindNumber(e.Metadata, r.Name)
+			case r.IsErr:
+				err += getFindNumber(e.Metadata, r.Name)
+			}
+		}
+		if total > 0.0 {
+			percentErr = err / total * 100.0
+		}
+		break
+	}
+	return total, percentErr
+}
+
+// FindHideTrafficMap sets IsFind on the nodes or edges matching the find expression, and removes the nodes or
+// edges matching the hide expression. A removed node takes its edges with it. Nodes left without any edges,
+// because of the removals, are also removed. Either expression may be nil.
+func FindHideTrafficMap(trafficMap TrafficMap, find, hide *FindExpression) {
+	if hide != nil {
+		hasEdges := trafficMapConnectedNodes(trafficMap)
+
+		for id, n := range trafficMap {
+			if hide.MatchNode(n) {
+				delete(trafficMap, id)
+			}
+		}
+		for _, n := range trafficMap {
+			edges := []*Edge{}
+			for _, e := range n.Edges {
+				if _, ok := trafficMap[e.Dest.ID]; ok && !hide.MatchEdge(e) {
+					edges = append(edges, e)
+				}
+			}
+			n.Edges = edges
+		}
+
+		stillHasEdges := trafficMapConnectedNodes(trafficMap)
+		for id := range trafficMap {
+			if hasEdges[id] && !stillHasEdges[id] {
+				delete(trafficMap, id)
+			}
+		}
+	}
+
+	if find != nil {
+		for _, n := range trafficMap {
+			if find.MatchNode(n) {
+				n.Metadata[IsFind] = true
+			}
+			for _, e := range n.Edges {
+				if find.MatchEdge(e) {
+					e.Metadata[IsFind] = true
+				}
+			}
+		}
+	}
+}
+
+// trafficMapConnectedNodes returns the IDs of the nodes with at least one incoming or outgoing edge
+func trafficMapConnectedNodes(trafficMap TrafficMap) map[string]bool {
+	result := map[string]bool{}
+	for id, n := range trafficMap {
+		for _, e := range n.Edges {
+			result[id] = true
+			result[e.Dest.ID] = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFindTestNode(trafficMap TrafficMap, namespace, workload string) *Node {
+	n := NewNode("east", namespace, "", namespace, workload, workload, "v1", GraphTypeWorkload)
+	trafficMap[n.ID] = &n
+	return &n
+}
+
+func addFindTestEdge(source, dest *Node, rate, errRate float64) *Edge {
+	e := source.AddEdge(dest)
+	e.Metadata[ProtocolKey] = "http"
+	AddToMetadata("http", rate-errRate, "200", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+	AddToMetadata("http", errRate, "500", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+	return e
+}
+
+func TestParseFindExpression(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, valid := range []string{"rpt > 100", "%error > 5", "ns = foo", "node = svc", "mtls", "!circuitbreaker", "name *= rev and !sidecar", "http >= 1 || rt > 1000"} {
+		_, err := ParseFindExpression(valid)
+		assert.NoError(err, valid)
+	}
+
+	for _, invalid := range []string{"", "foo = bar", "healthy", "ns > foo", "rt *= 10", "rt > fast", "mtls = true", "rt", "ns = foo and mtls", "ns = a and ns = b or ns = c"} {
+		_, err := ParseFindExpression(invalid)
+		assert.Error(err, invalid)
+	}
+
+	fe, _ := ParseFindExpression("RT > 1000")
+	assert.True(fe.IsEdge)
+	fe, _ = ParseFindExpression("ns = foo")
+	assert.False(fe.IsEdge)
+}
+
+func TestFindExpressionMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := NewTrafficMap()
+	a := newFindTestNode(trafficMap, "foo", "reviews")
+	b := newFindTestNode(trafficMap, "bar", "ratings")
+	b.Metadata[HasCB] = true
+	e := addFindTestEdge(a, b, 200.0, 20.0)
+	e.Metadata[IsMTLS] = 100.0
+	e.Metadata[Authorization] = AuthorizationDeny
+
+	match := func(expression string) bool {
+		fe, err := ParseFindExpression(expression)
+		assert.NoError(err)
+		if fe.IsEdge {
+			return fe.MatchEdge(e)
+		}
+		return fe.MatchNode(b)
+	}
+
+	assert.True(match("rpt > 100"))
+	assert.False(match("rpt > 200"))
+	assert.True(match("%error >= 10"))
+	assert.False(match("%error > 10"))
+	assert.True(match("%httperr = 10"))
+	assert.False(match("%grpcerr > 0"))
+	assert.True(match("mtls"))
+	assert.True(match("authorization = deny"))
+	assert.False(match("authz = allow"))
+	assert.True(match("protocol = HTTP"))
+	assert.True(match("ns = bar"))
+	assert.True(match("namespace != foo"))
+	assert.True(match("node = wl"))
+	assert.True(match("name ^= rat"))
+	assert.True(match("name !*= rev"))
+	assert.True(match("cb"))
+	assert.False(match("!circuitbreaker"))
+	assert.True(match("httpin > 100 AND ns = bar"))
+	assert.False(match("httpin > 300 && ns = bar"))
+	assert.True(match("httpin > 300 OR ns = bar"))
+
+	// node expressions never match edges, and vice versa
+	fe, _ := ParseFindExpression("ns = foo")
+	assert.False(fe.MatchEdge(e))
+	fe, _ = ParseFindExpression("mtls")
+	assert.False(fe.MatchNode(a))
+}
+
+func TestFindHideTrafficMap(t *testing.T) {
+	assert := assert.New(t)
+
+	// a -> b -> c, d -> c, e (no traffic)
+	trafficMap := NewTrafficMap()
+	a := newFindTestNode(trafficMap, "foo", "a")
+	b := newFindTestNode(trafficMap, "foo", "b")
+	c := newFindTestNode(trafficMap, "bar", "c")
+	d := newFindTestNode(trafficMap, "foo", "d")
+	e := newFindTestNode(trafficMap, "foo", "e")
+	addFindTestEdge(a, b, 10.0, 0.0)
+	bc := addFindTestEdge(b, c, 10.0, 5.0)
+	addFindTestEdge(d, c, 10.0, 0.0)
+
+	find, _ := ParseFindExpression("%error > 10")
+	hide, _ := ParseFindExpression("ns = bar")
+	FindHideTrafficMap(trafficMap, find, hide)
+
+	// c is hidden, leaving d orphaned. e was already orphaned.
+	assert.Equal(3, len(trafficMap))
+	assert.Contains(trafficMap, a.ID)
+	assert.Contains(trafficMap, b.ID)
+	assert.Contains(trafficMap, e.ID)
+	assert.Empty(b.Edges)
+	_, found := bc.Metadata[IsFind]
+	assert.False(found)
+
+	// hide edges, and find nodes
+	trafficMap = NewTrafficMap()
+	a = newFindTestNode(trafficMap, "foo", "a")
+	b = newFindTestNode(trafficMap, "foo", "b")
+	c = newFindTestNode(trafficMap, "bar", "c")
+	addFindTestEdge(a, b, 10.0, 0.0)
+	addFindTestEdge(b, c, 10.0, 5.0)
+
+	find, _ = ParseFindExpression("ns = foo")
+	hide, _ = ParseFindExpression("%error > 10")
+	FindHideTrafficMap(trafficMap, find, hide)
+
+	assert.Equal(2, len(trafficMap))
+	assert.Equal(true, a.Metadata[IsFind])
+	assert.Equal(true, b.Metadata[IsFind])
+	assert.Empty(b.Edges)
+}
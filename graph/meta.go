@@ -13,32 +13,46 @@ func NewMetadata() Metadata {
 
 // Metadata keys to be used instead of literal strings
 const (
-	Aggregate             MetadataKey = "aggregate" // the prom attribute used for aggregation
-	AggregateValue        MetadataKey = "aggregateValue"
-	DestPrincipal         MetadataKey = "destPrincipal"
-	DestServices          MetadataKey = "destServices"
-	HasCB                 MetadataKey = "hasCB"
-	HasFaultInjection     MetadataKey = "hasFaultInjection"
-	HasHealthConfig       MetadataKey = "hasHealthConfig"
-	HasMissingSC          MetadataKey = "hasMissingSC"
-	HasTCPTrafficShifting MetadataKey = "hasTCPTrafficShifting"
-	HasTrafficShifting    MetadataKey = "hasTrafficShifting"
-	HasRequestRouting     MetadataKey = "hasRequestRouting"
-	HasRequestTimeout     MetadataKey = "hasRequestTimeout"
-	HasVS                 MetadataKey = "hasVS"
-	IsDead                MetadataKey = "isDead"
-	IsEgressCluster       MetadataKey = "isEgressCluster"  // PassthroughCluster or BlackHoleCluster
-	IsIngressGateway      MetadataKey = "isIngressGateway" // Identifies a node that is an Istio ingress gateway
-	IsIdle                MetadataKey = "isIdle"
-	IsInaccessible        MetadataKey = "isInaccessible"
-	IsMTLS                MetadataKey = "isMTLS"
-	IsOutside             MetadataKey = "isOutside"
-	IsRoot                MetadataKey = "isRoot"
-	IsServiceEntry        MetadataKey = "isServiceEntry"
-	ProtocolKey           MetadataKey = "protocol"
-	ResponseTime          MetadataKey = "responseTime"
-	SourcePrincipal       MetadataKey = "sourcePrincipal"
-	Throughput            MetadataKey = "throughput"
+	Aggregate               MetadataKey = "aggregate" // the prom attribute used for aggregation
+	AggregateValue          MetadataKey = "aggregateValue"
+	Authorization           MetadataKey = "authorization"         // edge AuthorizationPolicy result, one of the Authorization values
+	AuthorizationPolicies   MetadataKey = "authorizationPolicies" // []string, the namespace/name of the policies deciding the edge authorization
+	BoxLabel                MetadataKey = "boxLabel"              // value of the boxBy label, set only when boxing by label
+	DestPrincipal           MetadataKey = "destPrincipal"
+	DestServices            MetadataKey = "destServices"
+	Diff                    MetadataKey = "diff"        // set on diff graphs, *DiffMetadata
+	EntryPoints             MetadataKey = "entryPoints" // []string, hostnames of the ingress gateways reaching the node, set on entry point graphs
+	External                MetadataKey = "external"    // ExternalMetadata, set by external appenders
+	HasCB                   MetadataKey = "hasCB"
+	HasFaultInjection       MetadataKey = "hasFaultInjection"
+	HasHealthConfig         MetadataKey = "hasHealthConfig"
+	HasMissingSC            MetadataKey = "hasMissingSC"
+	HasTCPTrafficShifting   MetadataKey = "hasTCPTrafficShifting"
+	HasTrafficShifting      MetadataKey = "hasTrafficShifting"
+	HasRequestRouting       MetadataKey = "hasRequestRouting"
+	HasRequestTimeout       MetadataKey = "hasRequestTimeout"
+	HasVS                   MetadataKey = "hasVS"
+	IsAnomalous             MetadataKey = "isAnomalous" // float64 anomaly score, set only when >= 1
+	IsDead                  MetadataKey = "isDead"
+	IsEgressCluster         MetadataKey = "isEgressCluster"  // PassthroughCluster or BlackHoleCluster
+	IsFind                  MetadataKey = "isFind"           // true if the node or edge matches the find expression
+	IsIngressGateway        MetadataKey = "isIngressGateway" // Identifies a node that is an Istio ingress gateway
+	IsIdle                  MetadataKey = "isIdle"
+	IsInaccessible          MetadataKey = "isInaccessible"
+	IsMTLS                  MetadataKey = "isMTLS"
+	IsOutside               MetadataKey = "isOutside"
+	IsRoot                  MetadataKey = "isRoot"
+	IsServiceEntry          MetadataKey = "isServiceEntry"
+	ProtocolKey             MetadataKey = "protocol"
+	RequestSize             MetadataKey = "requestSize"  // PercentilesMetadata, in bytes
+	ResponseSize            MetadataKey = "responseSize" // PercentilesMetadata, in bytes
+	ResponseTime            MetadataKey = "responseTime"
+	ResponseTimePercentiles MetadataKey = "responseTimePercentiles" // PercentilesMetadata, in millis
+	RootCauses              MetadataKey = "rootCauses"              // []RootCause, set on edges with failed requests
+	SourcePrincipal         MetadataKey = "sourcePrincipal"
+	Throughput              MetadataKey = "throughput"
+	TimeSeriesKey           MetadataKey = "timeSeries"    // TimeSeriesMetadata, set only when time series are requested
+	WorkloadGroup           MetadataKey = "workloadGroup" // WorkloadGroup name, set only when boxing by workloadGroup
 )
 
 // DestServicesMetadata key=Service.Key()
@@ -55,5 +69,71 @@ func (dsm DestServicesMetadata) Add(key string, service ServiceName) DestService
 	return dsm
 }
 
+// PercentilesMetadata key=percentile name (e.g. p95)
+type PercentilesMetadata map[string]float64
+
+// NewPercentilesMetadata returns an empty PercentilesMetadata map
+func NewPercentilesMetadata() PercentilesMetadata {
+	return make(map[string]float64)
+}
+
+// TimeSeries holds request traffic values for consecutive, equal-length time periods. Point i covers the
+// period ending at Start+(i*Step).
+type TimeSeries struct {
+	Start    int64     // unix time in seconds, end of the first period
+	Step     int64     // in seconds
+	Rates    []float64 // total request rate
+	ErrRates []float64 // error request rate
+}
+
+// TimeSeriesMetadata key=protocol. Node time series reflect incoming traffic.
+type TimeSeriesMetadata map[string]*TimeSeries
+
+// ExternalMetadata maps an external appender name to the data it returned for a node or edge
+type ExternalMetadata map[string]interface{}
+
 type GatewaysMetadata map[string][]string
 type VirtualServicesMetadata map[string][]string
+
+// Authorization values
+const (
+	AuthorizationAllow string = "allow" // allowed by an ALLOW policy
+	AuthorizationDeny  string = "deny"  // denied by a DENY policy, or not allowed by any ALLOW policy
+	AuthorizationNone  string = "none"  // no ALLOW policy applies, the destination is not protected
+)
+
+// RootCause values, the likely causes of failed requests
+const (
+	RootCauseCircuitBreaker  string = "circuitBreaker"         // circuit breaker open or hosts ejected by outlier detection
+	RootCauseFaultInjected   string = "faultInjected"          // fault injected by a VirtualService
+	RootCauseNoRoute         string = "noRoute"                // no route or cluster configured for the request
+	RootCauseRateLimited     string = "rateLimited"            // request rate limited
+	RootCauseTimeout         string = "timeout"                // request or stream timed out
+	RootCauseUpstreamConnect string = "upstreamConnectFailure" // upstream connection failure, reset or termination
+)
+
+// RootCause is a likely cause of the failed requests of an edge, derived from the Envoy response flags
+type RootCause struct {
+	Cause      string   // one of the RootCause values
+	Config     []string // the Istio config possibly responsible, kind:namespace/name, sorted
+	Flags      []string // the Envoy response flags attributed to the cause, sorted
+	Percentage float64  // percentage of the edge requests
+}
+
+// Diff status values
+const (
+	DiffAdded     string = "added"
+	DiffChanged   string = "changed"
+	DiffRemoved   string = "removed"
+	DiffUnchanged string = "unchanged"
+)
+
+// DiffMetadata describes how a node or edge differs from the same node or edge in a baseline graph. The
+// deltas are current-baseline and are set only for edges.
+type DiffMetadata struct {
+	Status            string
+	ErrorRateDelta    float64 // delta for the percentage of requests in error, in percentage points
+	RequestRateDelta  float64 // delta for the total traffic rate, in protocol units (e.g. rps)
+	ResponseTimeDelta float64 // delta for the response time, in millis
+	HasResponseTime   bool    // true if response time was reported for both graphs
+}
@@ -22,7 +22,11 @@ import (
 // The supported vendors
 const (
 	VendorCytoscape        string = "cytoscape"
+	VendorDot              string = "dot"
+	VendorGraphML          string = "graphml"
 	VendorIstio            string = "istio"
+	VendorJaeger           string = "jaeger"
+	VendorJGF              string = "jgf"
 	defaultConfigVendor    string = VendorCytoscape
 	defaultTelemetryVendor string = VendorIstio
 )
@@ -30,8 +34,10 @@ const (
 const (
 	BoxByApp                  string = "app"
 	BoxByCluster              string = "cluster"
+	BoxByLabel                string = "label" // requested as label:<labelName>
 	BoxByNamespace            string = "namespace"
 	BoxByNone                 string = "none"
+	BoxByWorkloadGroup        string = "workloadGroup"
 	NamespaceIstio            string = "istio-system"
 	RateNone                  string = "none"
 	RateReceived              string = "received" // tcp bytes received, grpc response messages, etc
@@ -46,6 +52,9 @@ const (
 	defaultRateGrpc           string = RateRequests
 	defaultRateHttp           string = RateRequests
 	defaultRateTcp            string = RateSent
+	defaultTimeSeriesPoints   int    = 30
+	maxTimeSeriesPoints       int    = 120
+	minTimeSeriesStep                = time.Minute
 )
 
 const (
@@ -94,10 +103,13 @@ type RequestedRates struct {
 type TelemetryOptions struct {
 	AccessibleNamespaces map[string]time.Time
 	Appenders            RequestedAppenders // requested appenders, nil if param not supplied
+	BoxByLabel           string             // the label used for boxing, nodes are decorated with the label value
+	BoxByWorkloadGroup   bool               // nodes are decorated with their WorkloadGroup
 	IncludeIdleEdges     bool               // include edges with request rates of 0
 	InjectServiceNodes   bool               // inject destination service nodes between source and destination nodes.
 	Namespaces           NamespaceInfoMap
 	Rates                RequestedRates
+	TimeSeriesStep       time.Duration // step between time series points, 0 if time series are not requested
 	CommonOptions
 	NodeOptions
 }
@@ -105,6 +117,8 @@ type TelemetryOptions struct {
 // Options comprises all available options
 type Options struct {
 	ConfigVendor    string
+	Find            *FindExpression // nodes or edges to flag, nil if not requested
+	Hide            *FindExpression // nodes or edges to remove, nil if not requested
 	TelemetryVendor string
 	ConfigOptions
 	TelemetryOptions
@@ -124,15 +138,20 @@ func NewOptions(r *net_http.Request) Options {
 	// query params
 	params := r.URL.Query()
 	var duration model.Duration
+	var find, hide *FindExpression
 	var includeIdleEdges bool
 	var injectServiceNodes bool
 	var queryTime int64
 	appenders := RequestedAppenders{All: true}
 	boxBy := params.Get("boxBy")
+	boxByLabel := ""
+	boxByWorkloadGroup := false
 	cluster := params.Get("cluster")
 	configVendor := params.Get("configVendor")
 	durationString := params.Get("duration")
+	findString := params.Get("find")
 	graphType := params.Get("graphType")
+	hideString := params.Get("hide")
 	includeIdleEdgesString := params.Get("includeIdleEdges")
 	injectServiceNodesString := params.Get("injectServiceNodes")
 	namespaces := params.Get("namespaces") // csl of namespaces
@@ -141,6 +160,8 @@ func NewOptions(r *net_http.Request) Options {
 	rateHttp := params.Get("rateHttp")
 	rateTcp := params.Get("rateTcp")
 	telemetryVendor := params.Get("telemetryVendor")
+	timeSeriesString := params.Get("timeSeries")
+	timeSeriesStepString := params.Get("timeSeriesStep")
 
 	if _, ok := params["appenders"]; ok {
 		appenderNames := strings.Split(params.Get("appenders"), ",")
@@ -155,8 +176,13 @@ func NewOptions(r *net_http.Request) Options {
 	}
 	if configVendor == "" {
 		configVendor = defaultConfigVendor
-	} else if configVendor != VendorCytoscape {
-		BadRequest(fmt.Sprintf("Invalid configVendor [%s]", configVendor))
+	} else {
+		switch configVendor {
+		case VendorCytoscape, VendorDot, VendorGraphML, VendorJGF:
+			// valid
+		default:
+			BadRequest(fmt.Sprintf("Invalid configVendor [%s]", configVendor))
+		}
 	}
 	if durationString == "" {
 		duration, _ = model.ParseDuration(defaultDuration)
@@ -167,6 +193,13 @@ func NewOptions(r *net_http.Request) Options {
 			BadRequest(fmt.Sprintf("Invalid duration [%s]", durationString))
 		}
 	}
+	if findString != "" {
+		var findErr error
+		find, findErr = ParseFindExpression(findString)
+		if findErr != nil {
+			BadRequest(fmt.Sprintf("Invalid find: %s", findErr.Error()))
+		}
+	}
 	if graphType == "" {
 		graphType = defaultGraphType
 	} else if graphType != GraphTypeApp && graphType != GraphTypeService && graphType != GraphTypeVersionedApp && graphType != GraphTypeWorkload {
@@ -179,18 +212,36 @@ func NewOptions(r *net_http.Request) Options {
 	if boxBy == "" {
 		boxBy = defaultBoxBy
 	} else {
+		// the box label name is removed from boxBy, leaving only the box types
+		boxes := []string{}
 		for _, box := range strings.Split(boxBy, ",") {
-			switch strings.TrimSpace(box) {
-			case BoxByApp:
-				continue
-			case BoxByCluster:
-				continue
-			case BoxByNamespace:
-				continue
+			box = strings.TrimSpace(box)
+			switch {
+			case box == BoxByApp || box == BoxByCluster || box == BoxByNamespace:
+				boxes = append(boxes, box)
+			case box == BoxByWorkloadGroup:
+				boxByWorkloadGroup = true
+				boxes = append(boxes, box)
+			case strings.HasPrefix(box, BoxByLabel+":") && boxByLabel == "":
+				if boxByLabel = strings.TrimSpace(strings.TrimPrefix(box, BoxByLabel+":")); boxByLabel == "" {
+					BadRequest(fmt.Sprintf("Invalid boxBy [%s], expecting label:<labelName>", boxBy))
+				}
+				boxes = append(boxes, BoxByLabel)
 			default:
 				BadRequest(fmt.Sprintf("Invalid boxBy [%s]", boxBy))
 			}
 		}
+		if boxByLabel != "" && boxByWorkloadGroup {
+			BadRequest(fmt.Sprintf("Invalid boxBy [%s], label and workloadGroup boxing can not be combined", boxBy))
+		}
+		boxBy = strings.Join(boxes, ",")
+	}
+	if hideString != "" {
+		var hideErr error
+		hide, hideErr = ParseFindExpression(hideString)
+		if hideErr != nil {
+			BadRequest(fmt.Sprintf("Invalid hide: %s", hideErr.Error()))
+		}
 	}
 	if includeIdleEdgesString == "" {
 		includeIdleEdges = defaultIncludeIdleEdges
@@ -221,9 +272,10 @@ func NewOptions(r *net_http.Request) Options {
 	}
 	if telemetryVendor == "" {
 		telemetryVendor = defaultTelemetryVendor
-	} else if telemetryVendor != VendorIstio {
+	} else if telemetryVendor != VendorIstio && telemetryVendor != VendorJaeger {
 		BadRequest(fmt.Sprintf("Invalid telemetryVendor [%s]", telemetryVendor))
 	}
+	timeSeriesStep := getTimeSeriesStep(timeSeriesString, timeSeriesStepString, time.Duration(duration))
 
 	// Process namespaces options:
 	namespaceMap := NewNamespaceInfoMap()
@@ -324,6 +376,8 @@ func NewOptions(r *net_http.Request) Options {
 
 	options := Options{
 		ConfigVendor:    configVendor,
+		Find:            find,
+		Hide:            hide,
 		TelemetryVendor: telemetryVendor,
 		ConfigOptions: ConfigOptions{
 			BoxBy: boxBy,
@@ -337,10 +391,13 @@ func NewOptions(r *net_http.Request) Options {
 		TelemetryOptions: TelemetryOptions{
 			AccessibleNamespaces: accessibleNamespaces,
 			Appenders:            appenders,
+			BoxByLabel:           boxByLabel,
+			BoxByWorkloadGroup:   boxByWorkloadGroup,
 			IncludeIdleEdges:     includeIdleEdges,
 			InjectServiceNodes:   injectServiceNodes,
 			Namespaces:           namespaceMap,
 			Rates:                rates,
+			TimeSeriesStep:       timeSeriesStep,
 			CommonOptions: CommonOptions{
 				Duration:  time.Duration(duration),
 				GraphType: graphType,
@@ -363,6 +420,134 @@ func NewOptions(r *net_http.Request) Options {
 	return options
 }
 
+// getTimeSeriesStep returns the step between time series points, or 0 if time series are not requested. By default
+// the duration is split into defaultTimeSeriesPoints, but the step is never less than minTimeSeriesStep, to ensure
+// each point has enough samples to calculate a rate.
+func getTimeSeriesStep(timeSeriesString, timeSeriesStepString string, duration time.Duration) time.Duration {
+	if timeSeriesString == "" {
+		return 0
+	}
+	timeSeries, err := strconv.ParseBool(timeSeriesString)
+	if err != nil {
+		BadRequest(fmt.Sprintf("Invalid timeSeries [%s]", timeSeriesString))
+	}
+	if !timeSeries {
+		return 0
+	}
+
+	step := duration / time.Duration(defaultTimeSeriesPoints)
+	if timeSeriesStepString != "" {
+		modelStep, err := model.ParseDuration(timeSeriesStepString)
+		if err != nil {
+			BadRequest(fmt.Sprintf("Invalid timeSeriesStep [%s]", timeSeriesStepString))
+		}
+		step = time.Duration(modelStep)
+		if step < minTimeSeriesStep {
+			BadRequest(fmt.Sprintf("Invalid timeSeriesStep [%s], must be at least %v", timeSeriesStepString, minTimeSeriesStep))
+		}
+		if int(duration/step) > maxTimeSeriesPoints {
+			BadRequest(fmt.Sprintf("Invalid timeSeriesStep [%s], must produce at most %d points for duration %v", timeSeriesStepString, maxTimeSeriesPoints, duration))
+		}
+	}
+	if step < minTimeSeriesStep {
+		step = minTimeSeriesStep
+	}
+	return step.Truncate(time.Second)
+}
+
+// DiffOptions are the options for a diff graph request, comparing the requested graph to a baseline graph
+type DiffOptions struct {
+	Baseline Options // same as the requested options, but with QueryTime set to the compareTime
+	Options
+}
+
+// NewDiffOptions returns the options for a diff graph request. In addition to the standard graph options
+// it supports the compareTime query param, the unix time (seconds) of the baseline graph. The default
+// compareTime is queryTime-duration, meaning the requested time period is compared to the time period
+// immediately preceding it.
+func NewDiffOptions(r *net_http.Request) DiffOptions {
+	o := NewOptions(r)
+
+	var compareTime int64
+	compareTimeString := o.TelemetryOptions.Params.Get("compareTime")
+	if compareTimeString == "" {
+		compareTime = o.TelemetryOptions.QueryTime - int64(o.TelemetryOptions.Duration.Seconds())
+	} else {
+		var compareTimeErr error
+		compareTime, compareTimeErr = strconv.ParseInt(compareTimeString, 10, 64)
+		if compareTimeErr != nil {
+			BadRequest(fmt.Sprintf("Invalid compareTime [%s]", compareTimeString))
+		}
+	}
+	if compareTime >= o.TelemetryOptions.QueryTime {
+		BadRequest(fmt.Sprintf("Invalid compareTime [%d], it must precede queryTime [%d]", compareTime, o.TelemetryOptions.QueryTime))
+	}
+
+	// the baseline options are identical to the requested options, other than the query time and the
+	// safe namespace durations, which are based on the query time. Time series are provided only for
+	// the requested time period.
+	baseline := o
+	baseline.ConfigOptions.QueryTime = compareTime
+	baseline.TelemetryOptions.QueryTime = compareTime
+	baseline.TelemetryOptions.TimeSeriesStep = 0
+	baseline.TelemetryOptions.Namespaces = NewNamespaceInfoMap()
+	for name, namespaceInfo := range o.TelemetryOptions.Namespaces {
+		namespaceInfo.Duration = getSafeNamespaceDuration(name, o.AccessibleNamespaces[name], o.TelemetryOptions.Duration, compareTime)
+		baseline.TelemetryOptions.Namespaces[name] = namespaceInfo
+	}
+
+	return DiffOptions{
+		Baseline: baseline,
+		Options:  o,
+	}
+}
+
+// NewSimulationOptions returns the options for a traffic simulation graph request. The simulated traffic is
+// redistributed among the workloads of the services, so service nodes are always injected and only versionedApp
+// and workload graphs are supported.
+func NewSimulationOptions(r *net_http.Request) Options {
+	o := NewOptions(r)
+
+	if o.TelemetryOptions.GraphType != GraphTypeVersionedApp && o.TelemetryOptions.GraphType != GraphTypeWorkload {
+		BadRequest(fmt.Sprintf("Invalid graphType [%s]. A simulation graph supports only graphType versionedApp or workload.", o.TelemetryOptions.GraphType))
+	}
+	o.InjectServiceNodes = true
+
+	return o
+}
+
+// NewDependenciesOptions returns the options for a node dependencies request. Dependencies may cross namespaces,
+// so unlike a node graph, the namespaces query param can supply namespaces to analyze in addition to the node
+// namespace.
+func NewDependenciesOptions(r *net_http.Request) Options {
+	o := NewOptions(r)
+
+	if namespaces := o.TelemetryOptions.Params.Get("namespaces"); namespaces != "" {
+		for _, namespaceToken := range strings.Split(namespaces, ",") {
+			namespaceToken = strings.TrimSpace(namespaceToken)
+			if _, found := o.TelemetryOptions.Namespaces[namespaceToken]; found {
+				continue
+			}
+			if creationTime, found := o.AccessibleNamespaces[namespaceToken]; found {
+				o.TelemetryOptions.Namespaces[namespaceToken] = NamespaceInfo{
+					Name:     namespaceToken,
+					Duration: getSafeNamespaceDuration(namespaceToken, creationTime, o.TelemetryOptions.Duration, o.TelemetryOptions.QueryTime),
+					IsIstio:  config.IsIstioNamespace(namespaceToken),
+				}
+			} else {
+				Forbidden(fmt.Sprintf("Requested namespace [%s] is not accessible.", namespaceToken))
+			}
+		}
+	}
+
+	// a service node is only present in the traffic map when service nodes are injected
+	if o.NodeOptions.Service != "" {
+		o.InjectServiceNodes = true
+	}
+
+	return o
+}
+
 // GetGraphKind will return the kind of graph represented by the options.
 func (o *TelemetryOptions) GetGraphKind() string {
 	if o.NodeOptions.App != "" ||
@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"encoding/json"
+
+	core_v1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+)
+
+const (
+	configMapDataKey = "snapshot.json"
+	configMapLabel   = "kiali.io/graph-snapshot"
+	configMapPrefix  = "kiali-graph-snapshot-"
+)
+
+// configMapStore stores every snapshot as a ConfigMap in a namespace, named configMapPrefix<name>. Note that
+// ConfigMaps are limited to 1MiB, saving the snapshot of a very large graph fails.
+type configMapStore struct {
+	k8s       kubernetes.K8SClientInterface
+	namespace string
+}
+
+// NewConfigMapStore returns a Store saving the snapshots as ConfigMaps in the namespace
+func NewConfigMapStore(k8s kubernetes.K8SClientInterface, namespace string) Store {
+	return &configMapStore{k8s: k8s, namespace: namespace}
+}
+
+// Delete implements Store
+func (in *configMapStore) Delete(name string) error {
+	err := in.k8s.DeleteConfigMap(in.namespace, configMapPrefix+name)
+	if k8s_errors.IsNotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Get implements Store
+func (in *configMapStore) Get(name string) (*Snapshot, error) {
+	configMap, err := in.k8s.GetConfigMap(in.namespace, configMapPrefix+name)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if configMap.Labels[configMapLabel] != "true" {
+		return nil, ErrNotFound
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal([]byte(configMap.Data[configMapDataKey]), snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List implements Store
+func (in *configMapStore) List() ([]Info, error) {
+	configMaps, err := in.k8s.GetConfigMaps(in.namespace, configMapLabel+"=true")
+	if err != nil {
+		return nil, err
+	}
+	infos := []Info{}
+	for _, configMap := range configMaps {
+		// the graph is ignored when unmarshalling the info only
+		info := Info{}
+		if err := json.Unmarshal([]byte(configMap.Data[configMapDataKey]), &info); err != nil {
+			log.Warningf("Ignoring invalid graph snapshot ConfigMap [%s]: %v", configMap.Name, err)
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sortInfos(infos)
+	return infos, nil
+}
+
+// Save implements Store
+func (in *configMapStore) Save(snapshot *Snapshot) error {
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	configMap := &core_v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      configMapPrefix + snapshot.Name,
+			Namespace: in.namespace,
+			Labels:    map[string]string{configMapLabel: "true"},
+		},
+		Data: map[string]string{configMapDataKey: string(content)},
+	}
+	_, err = in.k8s.CreateConfigMap(in.namespace, configMap)
+	if k8s_errors.IsAlreadyExists(err) {
+		return ErrExists
+	}
+	return err
+}
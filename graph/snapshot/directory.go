@@ -0,0 +1,106 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kiali/kiali/log"
+)
+
+const fileSuffix = ".json"
+
+// directoryStore stores every snapshot as a JSON file in a directory, typically a mounted PVC
+type directoryStore struct {
+	directory string
+}
+
+// NewDirectoryStore returns a Store writing the snapshots to the directory, created on first save
+func NewDirectoryStore(directory string) Store {
+	return &directoryStore{directory: directory}
+}
+
+func (in *directoryStore) path(name string) string {
+	return filepath.Join(in.directory, name+fileSuffix)
+}
+
+// Delete implements Store
+func (in *directoryStore) Delete(name string) error {
+	err := os.Remove(in.path(name))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Get implements Store
+func (in *directoryStore) Get(name string) (*Snapshot, error) {
+	content, err := ioutil.ReadFile(in.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List implements Store
+func (in *directoryStore) List() ([]Info, error) {
+	infos := []Info{}
+	files, err := ioutil.ReadDir(in.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return infos, nil
+		}
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), fileSuffix) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(in.directory, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		// the graph is ignored when unmarshalling the info only
+		info := Info{}
+		if err := json.Unmarshal(content, &info); err != nil {
+			log.Warningf("Ignoring invalid graph snapshot file [%s]: %v", f.Name(), err)
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sortInfos(infos)
+	return infos, nil
+}
+
+// Save implements Store
+func (in *directoryStore) Save(snapshot *Snapshot) error {
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(in.directory, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(in.path(snapshot.Name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return ErrExists
+		}
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,160 @@
+// Package snapshot persists generated namespaces graphs as named snapshots, which can be listed and
+// replayed later. A snapshot stores the cytoscape config of the graph, the format returned to the UI,
+// along with the user, time and options of the graph request. The TrafficMap itself is not stored, its
+// metadata can't be faithfully round-tripped through JSON, and the config is what the UI renders anyway.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// Storage options, see config.GraphSnapshotConfig
+const (
+	StorageConfigMap = "configmap"
+	StorageDirectory = "directory"
+	StorageNone      = "none"
+)
+
+// snapshot names are used as file and ConfigMap names, they are restricted to DNS labels short enough
+// to be prefixed with configMapPrefix.
+const maxNameLength = 40
+
+var nameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+var (
+	ErrDisabled = errors.New("graph snapshots are disabled, see the graph.snapshot.storage configuration")
+	ErrExists   = errors.New("graph snapshot already exists")
+	ErrNotFound = errors.New("graph snapshot not found")
+)
+
+// Options records the graph.Options used to generate a snapshot
+type Options struct {
+	Appenders          []string   `json:"appenders"`
+	BoxBy              string     `json:"boxBy,omitempty"`
+	Duration           int64      `json:"duration"` // seconds
+	Find               string     `json:"find,omitempty"`
+	GraphType          string     `json:"graphType"`
+	Hide               string     `json:"hide,omitempty"`
+	IncludeIdleEdges   bool       `json:"includeIdleEdges"`
+	InjectServiceNodes bool       `json:"injectServiceNodes"`
+	Namespaces         []string   `json:"namespaces"`
+	Params             url.Values `json:"params"`    // the raw query params of the graph request
+	QueryTime          int64      `json:"queryTime"` // unix time in seconds
+	RateGrpc           string     `json:"rateGrpc"`
+	RateHttp           string     `json:"rateHttp"`
+	RateTcp            string     `json:"rateTcp"`
+	TelemetryVendor    string     `json:"telemetryVendor"`
+}
+
+// Info describes a snapshot, without its graph
+type Info struct {
+	Created time.Time `json:"created"`
+	Name    string    `json:"name"`
+	Options Options   `json:"options"`
+	User    string    `json:"user"`
+}
+
+// Snapshot is a saved namespaces graph
+type Snapshot struct {
+	Info
+	Graph cytoscape.Config `json:"graph"`
+}
+
+// Store persists snapshots. Save fails with ErrExists if the name is in use, Get and Delete fail with
+// ErrNotFound for unknown names.
+type Store interface {
+	Delete(name string) error
+	Get(name string) (*Snapshot, error)
+	List() ([]Info, error)
+	Save(snapshot *Snapshot) error
+}
+
+// NewOptions returns the record of the options used to generate a graph
+func NewOptions(o graph.Options) Options {
+	namespaces := make([]string, 0, len(o.TelemetryOptions.Namespaces))
+	for namespace := range o.TelemetryOptions.Namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	result := Options{
+		Appenders:          o.Appenders.AppenderNames,
+		BoxBy:              o.BoxBy,
+		Duration:           int64(o.TelemetryOptions.Duration.Seconds()),
+		GraphType:          o.TelemetryOptions.GraphType,
+		IncludeIdleEdges:   o.IncludeIdleEdges,
+		InjectServiceNodes: o.InjectServiceNodes,
+		Namespaces:         namespaces,
+		Params:             o.TelemetryOptions.Params,
+		QueryTime:          o.TelemetryOptions.QueryTime,
+		RateGrpc:           o.Rates.Grpc,
+		RateHttp:           o.Rates.Http,
+		RateTcp:            o.Rates.Tcp,
+		TelemetryVendor:    o.TelemetryVendor,
+	}
+	if o.Find != nil {
+		result.Find = o.Find.Expression
+	}
+	if o.Hide != nil {
+		result.Hide = o.Hide.Expression
+	}
+	return result
+}
+
+// ValidateName returns an error if the name can't be used for a snapshot
+func ValidateName(name string) error {
+	if len(name) > maxNameLength || !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid graph snapshot name [%s], expecting at most %d lower case alphanumeric characters or '-', starting and ending with an alphanumeric character", name, maxNameLength)
+	}
+	return nil
+}
+
+// GetStore returns the configured snapshot store, or ErrDisabled
+func GetStore() (Store, error) {
+	cfg := config.Get()
+	switch cfg.Graph.Snapshot.Storage {
+	case StorageDirectory:
+		return NewDirectoryStore(cfg.Graph.Snapshot.Directory), nil
+	case StorageConfigMap:
+		// snapshots are owned by Kiali, not by the users, so they are managed with the Kiali SA
+		clientFactory, err := kubernetes.GetClientFactory()
+		if err != nil {
+			return nil, err
+		}
+		kialiToken, err := kubernetes.GetKialiToken()
+		if err != nil {
+			return nil, err
+		}
+		k8s, err := clientFactory.GetClient(&api.AuthInfo{Token: kialiToken})
+		if err != nil {
+			return nil, err
+		}
+		return NewConfigMapStore(k8s, cfg.Deployment.Namespace), nil
+	case StorageNone, "":
+		return nil, ErrDisabled
+	default:
+		return nil, fmt.Errorf("graph snapshot storage [%s] not supported", cfg.Graph.Snapshot.Storage)
+	}
+}
+
+// sortInfos sorts the snapshots from the newest to the oldest
+func sortInfos(infos []Info) {
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].Created.Equal(infos[j].Created) {
+			return infos[i].Created.After(infos[j].Created)
+		}
+		return infos[i].Name < infos[j].Name
+	})
+}
@@ -0,0 +1,129 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	core_v1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+func newSnapshot(name string, created time.Time) *Snapshot {
+	return &Snapshot{
+		Info: Info{
+			Created: created,
+			Name:    name,
+			Options: Options{Duration: 600, GraphType: graph.GraphTypeWorkload, Namespaces: []string{"bookinfo"}},
+			User:    "alice",
+		},
+		Graph: cytoscape.Config{Timestamp: created.Unix(), Duration: 600, GraphType: graph.GraphTypeWorkload},
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(ValidateName("before-upgrade-1"))
+	assert.Error(ValidateName(""))
+	assert.Error(ValidateName("Before"))
+	assert.Error(ValidateName("-before"))
+	assert.Error(ValidateName("../before"))
+	assert.Error(ValidateName("a1234567890123456789012345678901234567890"))
+}
+
+func TestNewOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	o := graph.Options{TelemetryVendor: graph.VendorIstio}
+	o.TelemetryOptions.Namespaces = graph.NamespaceInfoMap{"tutorial": {Name: "tutorial"}, "bookinfo": {Name: "bookinfo"}}
+	o.TelemetryOptions.Duration = 10 * time.Minute
+	o.TelemetryOptions.GraphType = graph.GraphTypeApp
+	o.Find = &graph.FindExpression{Expression: "rt > 100"}
+
+	options := NewOptions(o)
+	assert.Equal([]string{"bookinfo", "tutorial"}, options.Namespaces)
+	assert.Equal(int64(600), options.Duration)
+	assert.Equal(graph.GraphTypeApp, options.GraphType)
+	assert.Equal("rt > 100", options.Find)
+	assert.Equal("", options.Hide)
+}
+
+func TestDirectoryStore(t *testing.T) {
+	assert := assert.New(t)
+
+	directory, err := ioutil.TempDir("", "snapshots")
+	assert.NoError(err)
+	defer os.RemoveAll(directory)
+
+	// the directory is created on first save
+	store := NewDirectoryStore(directory + "/graphs")
+	infos, err := store.List()
+	assert.NoError(err)
+	assert.Empty(infos)
+
+	created := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(store.Save(newSnapshot("first", created)))
+	assert.NoError(store.Save(newSnapshot("second", created.Add(time.Hour))))
+	assert.Equal(ErrExists, store.Save(newSnapshot("first", created)))
+
+	infos, err = store.List()
+	assert.NoError(err)
+	assert.Equal([]Info{newSnapshot("second", created.Add(time.Hour)).Info, newSnapshot("first", created).Info}, infos)
+
+	s, err := store.Get("first")
+	assert.NoError(err)
+	assert.Equal(newSnapshot("first", created), s)
+
+	assert.NoError(store.Delete("first"))
+	_, err = store.Get("first")
+	assert.Equal(ErrNotFound, err)
+	assert.Equal(ErrNotFound, store.Delete("first"))
+}
+
+func TestConfigMapStore(t *testing.T) {
+	assert := assert.New(t)
+
+	created := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	content, err := json.Marshal(newSnapshot("first", created))
+	assert.NoError(err)
+	configMap := core_v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "kiali-graph-snapshot-first", Labels: map[string]string{"kiali.io/graph-snapshot": "true"}},
+		Data:       map[string]string{"snapshot.json": string(content)},
+	}
+	notFound := k8s_errors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "kiali-graph-snapshot-second")
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetConfigMaps", "istio-system", "kiali.io/graph-snapshot=true").Return([]core_v1.ConfigMap{configMap}, nil)
+	k8s.On("GetConfigMap", "istio-system", "kiali-graph-snapshot-first").Return(&configMap, nil)
+	k8s.On("GetConfigMap", "istio-system", "kiali-graph-snapshot-second").Return(&core_v1.ConfigMap{}, notFound)
+	k8s.On("CreateConfigMap", "istio-system", mock.AnythingOfType("*v1.ConfigMap")).Return(&configMap, nil)
+	k8s.On("DeleteConfigMap", "istio-system", "kiali-graph-snapshot-second").Return(notFound)
+
+	store := NewConfigMapStore(k8s, "istio-system")
+	infos, err := store.List()
+	assert.NoError(err)
+	assert.Equal([]Info{newSnapshot("first", created).Info}, infos)
+
+	s, err := store.Get("first")
+	assert.NoError(err)
+	assert.Equal(newSnapshot("first", created), s)
+	_, err = store.Get("second")
+	assert.Equal(ErrNotFound, err)
+	assert.Equal(ErrNotFound, store.Delete("second"))
+
+	assert.NoError(store.Save(newSnapshot("second", created)))
+	saved := k8s.Calls[len(k8s.Calls)-1].Arguments.Get(1).(*core_v1.ConfigMap)
+	assert.Equal("kiali-graph-snapshot-second", saved.Name)
+	assert.Equal("true", saved.Labels["kiali.io/graph-snapshot"])
+}
@@ -0,0 +1,301 @@
+package telemetry
+
+import (
+	"sort"
+
+	"github.com/kiali/kiali/graph"
+)
+
+const (
+	DependencyDownstream string = "downstream"
+	DependencyUpstream   string = "upstream"
+	// MaxDependencyPaths limits the paths reported for each direction, the number of paths can grow
+	// exponentially with the size of the graph.
+	MaxDependencyPaths int = 100
+)
+
+// DependencyPath is a chain of nodes, in the direction of the traffic. Downstream paths start at a root node
+// and upstream paths end at a root node. ResponseTime is the sum of the edge response times along the path.
+type DependencyPath struct {
+	Direction    string   `json:"direction"`
+	Nodes        []string `json:"nodes"`
+	ResponseTime float64  `json:"responseTime"`
+}
+
+// Dependencies is the result of a dependency analysis. Node IDs are TrafficMap keys.
+type Dependencies struct {
+	Roots        []string         // the analyzed nodes
+	Upstream     []string         // nodes that send traffic, directly or transitively, to a root
+	Downstream   []string         // nodes that receive traffic, directly or transitively, from a root
+	Cycles       [][]string       // each cycle starts at its lowest node ID, the last node sends to the first
+	CriticalPath *DependencyPath  // the path with the highest ResponseTime, nil if there are no paths
+	Paths        []DependencyPath // upstream paths first, then downstream paths
+	Truncated    bool             // true if MaxDependencyPaths was reached, Paths and Cycles may be incomplete
+}
+
+// MatchNodes returns the IDs of the trafficMap nodes addressed by the node options, sorted. The namespace
+// must match and, when set, the cluster must match. Depending on the options the node is a service, a
+// workload, or an app (optionally limited to a version). An app may match several nodes in a versionedApp
+// graph, and any node may match several nodes in a multi-cluster graph.
+func MatchNodes(trafficMap graph.TrafficMap, o graph.NodeOptions) []string {
+	ids := []string{}
+	for id, n := range trafficMap {
+		if n.Namespace != o.Namespace || (graph.IsOK(o.Cluster) && n.Cluster != o.Cluster) {
+			continue
+		}
+		var match bool
+		switch {
+		case o.Service != "":
+			match = n.NodeType == graph.NodeTypeService && n.Service == o.Service
+		case o.Workload != "":
+			match = n.NodeType != graph.NodeTypeService && n.Workload == o.Workload
+		case o.App != "":
+			match = n.NodeType == graph.NodeTypeApp && n.App == o.App && (o.Version == "" || n.Version == o.Version)
+		}
+		if match {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// AnalyzeDependencies computes the transitive upstream and downstream dependencies of the root nodes, the
+// cycles they are involved in, and the dependency paths. The path latency is based on the graph.ResponseTime
+// edge metadata, so the responseTime appender should be applied to the trafficMap. Edges without a response
+// time (e.g. TCP) add no latency. When two nodes are connected by several (protocol) edges the highest response
+// time is used.
+func AnalyzeDependencies(trafficMap graph.TrafficMap, roots []string) *Dependencies {
+	d := &Dependencies{
+		Roots:      roots,
+		Upstream:   []string{},
+		Downstream: []string{},
+		Cycles:     [][]string{},
+		Paths:      []DependencyPath{},
+	}
+
+	outgoing, incoming := dependencyAdjacency(trafficMap)
+	isRoot := make(map[string]bool, len(roots))
+	for _, root := range roots {
+		isRoot[root] = true
+	}
+	d.Upstream = reachable(incoming, roots, isRoot)
+	d.Downstream = reachable(outgoing, roots, isRoot)
+
+	w := dependencyWalker{
+		cycles:   map[string][]string{},
+		incoming: incoming,
+		outgoing: outgoing,
+	}
+	for _, root := range roots {
+		w.walk(DependencyUpstream, []string{root}, map[string]bool{root: true})
+	}
+	w.paths = 0
+	for _, root := range roots {
+		w.walk(DependencyDownstream, []string{root}, map[string]bool{root: true})
+	}
+
+	for _, path := range w.result {
+		responseTime := 0.0
+		for i := 1; i < len(path.Nodes); i++ {
+			responseTime += outgoing[path.Nodes[i-1]][path.Nodes[i]]
+		}
+		path.ResponseTime = responseTime
+		d.Paths = append(d.Paths, path)
+	}
+	for i := range d.Paths {
+		if d.CriticalPath == nil || d.Paths[i].ResponseTime > d.CriticalPath.ResponseTime {
+			d.CriticalPath = &d.Paths[i]
+		}
+	}
+
+	cycleKeys := make([]string, 0, len(w.cycles))
+	for k := range w.cycles {
+		cycleKeys = append(cycleKeys, k)
+	}
+	sort.Strings(cycleKeys)
+	for _, k := range cycleKeys {
+		d.Cycles = append(d.Cycles, w.cycles[k])
+	}
+	d.Truncated = w.truncated
+
+	return d
+}
+
+// FilterDependencies returns a new TrafficMap holding only the root, upstream and downstream nodes, and the edges
+// on the dependency paths. The nodes are shallow copies, the trafficMap is not modified.
+func FilterDependencies(trafficMap graph.TrafficMap, d *Dependencies) graph.TrafficMap {
+	isRoot := make(map[string]bool, len(d.Roots))
+	isUpstream := make(map[string]bool, len(d.Upstream)+len(d.Roots))
+	isDownstream := make(map[string]bool, len(d.Downstream)+len(d.Roots))
+	for _, id := range d.Roots {
+		isRoot[id] = true
+		isUpstream[id] = true
+		isDownstream[id] = true
+	}
+	for _, id := range d.Upstream {
+		isUpstream[id] = true
+	}
+	for _, id := range d.Downstream {
+		isDownstream[id] = true
+	}
+
+	result := graph.NewTrafficMap()
+	for id, n := range trafficMap {
+		if !isUpstream[id] && !isDownstream[id] {
+			continue
+		}
+		filteredNode := *n
+		filteredNode.Edges = []*graph.Edge{}
+		for _, e := range n.Edges {
+			// an upstream edge leads toward a root, a downstream edge leads away from a root. Anything
+			// else is just traffic between dependencies.
+			toRoot := isUpstream[e.Source.ID] && isUpstream[e.Dest.ID]
+			fromRoot := isDownstream[e.Source.ID] && isDownstream[e.Dest.ID] && !isRoot[e.Dest.ID]
+			if toRoot || fromRoot {
+				filteredNode.Edges = append(filteredNode.Edges, e)
+			}
+		}
+		result[id] = &filteredNode
+	}
+	return result
+}
+
+// dependencyAdjacency returns, for each node, the nodes it sends to and receives from, mapped to the highest
+// response time of the edges between them.
+func dependencyAdjacency(trafficMap graph.TrafficMap) (outgoing, incoming map[string]map[string]float64) {
+	outgoing = make(map[string]map[string]float64, len(trafficMap))
+	incoming = make(map[string]map[string]float64, len(trafficMap))
+	for id, n := range trafficMap {
+		for _, e := range n.Edges {
+			responseTime := 0.0
+			if val, ok := e.Metadata[graph.ResponseTime]; ok {
+				responseTime = val.(float64)
+			}
+			if _, ok := outgoing[id]; !ok {
+				outgoing[id] = map[string]float64{}
+			}
+			if _, ok := incoming[e.Dest.ID]; !ok {
+				incoming[e.Dest.ID] = map[string]float64{}
+			}
+			if responseTime >= outgoing[id][e.Dest.ID] {
+				outgoing[id][e.Dest.ID] = responseTime
+				incoming[e.Dest.ID][id] = responseTime
+			}
+		}
+	}
+	return outgoing, incoming
+}
+
+// reachable returns the sorted IDs of the nodes reachable from the roots, roots excluded
+func reachable(adjacency map[string]map[string]float64, roots []string, isRoot map[string]bool) []string {
+	visited := map[string]bool{}
+	queue := append([]string{}, roots...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for next := range adjacency[id] {
+			if visited[next] || isRoot[next] {
+				continue
+			}
+			visited[next] = true
+			queue = append(queue, next)
+		}
+	}
+
+	result := make([]string, 0, len(visited))
+	for id := range visited {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// dependencyWalker enumerates the simple paths from the roots, collecting the cycles found along the way. Every
+// elementary cycle reachable from a root is found, because it is reached by some simple path.
+type dependencyWalker struct {
+	cycles    map[string][]string
+	incoming  map[string]map[string]float64
+	outgoing  map[string]map[string]float64
+	paths     int
+	result    []DependencyPath
+	truncated bool
+}
+
+// walk extends the path, which is in walk order (i.e. reversed for upstream), until it can not be extended
+func (w *dependencyWalker) walk(direction string, path []string, onPath map[string]bool) {
+	if w.paths >= MaxDependencyPaths {
+		w.truncated = true
+		return
+	}
+
+	adjacency := w.outgoing
+	if direction == DependencyUpstream {
+		adjacency = w.incoming
+	}
+	next := make([]string, 0, len(adjacency[path[len(path)-1]]))
+	for id := range adjacency[path[len(path)-1]] {
+		next = append(next, id)
+	}
+	sort.Strings(next)
+
+	extended := false
+	for _, id := range next {
+		if onPath[id] {
+			w.addCycle(direction, path, id)
+			continue
+		}
+		extended = true
+		onPath[id] = true
+		w.walk(direction, append(path, id), onPath)
+		delete(onPath, id)
+	}
+
+	if !extended && len(path) > 1 {
+		if w.paths >= MaxDependencyPaths {
+			w.truncated = true
+			return
+		}
+		w.paths++
+		nodes := make([]string, len(path))
+		copy(nodes, path)
+		if direction == DependencyUpstream {
+			reverse(nodes)
+		}
+		w.result = append(w.result, DependencyPath{Direction: direction, Nodes: nodes})
+	}
+}
+
+// addCycle records the cycle formed by returning to the node id, which is already on the path
+func (w *dependencyWalker) addCycle(direction string, path []string, id string) {
+	start := len(path) - 1
+	for path[start] != id {
+		start--
+	}
+	cycle := make([]string, len(path)-start)
+	copy(cycle, path[start:])
+	if direction == DependencyUpstream {
+		reverse(cycle)
+	}
+
+	// rotate to start at the lowest ID, to report each cycle once
+	lowest := 0
+	for i, cycleID := range cycle {
+		if cycleID < cycle[lowest] {
+			lowest = i
+		}
+	}
+	cycle = append(cycle[lowest:], cycle[:lowest]...)
+
+	key := ""
+	for _, cycleID := range cycle {
+		key += cycleID + " "
+	}
+	w.cycles[key] = cycle
+}
+
+func reverse(ids []string) {
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+}
@@ -0,0 +1,114 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+// dependenciesTestTraffic returns:
+//
+//	a -> b -> c -> d
+//	     b -> e -> c
+//	          c -> b (cycle)
+//	x -> y          (unrelated)
+func dependenciesTestTraffic() (graph.TrafficMap, map[string]*graph.Node) {
+	trafficMap := graph.NewTrafficMap()
+	nodes := map[string]*graph.Node{}
+	for _, wl := range []string{"a", "b", "c", "d", "e", "x", "y"} {
+		nodes[wl] = newTestNode(trafficMap, wl)
+	}
+	edge := func(source, dest string, responseTime float64) {
+		e := addHTTPEdge(nodes[source], nodes[dest], 10.0, 0.0)
+		e.Metadata[graph.ResponseTime] = responseTime
+	}
+	edge("a", "b", 10.0)
+	edge("b", "c", 20.0)
+	edge("c", "d", 30.0)
+	edge("b", "e", 40.0)
+	edge("e", "c", 50.0)
+	edge("c", "b", 5.0)
+	edge("x", "y", 1000.0)
+	return trafficMap, nodes
+}
+
+func TestMatchNodes(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap, nodes := dependenciesTestTraffic()
+
+	assert.Equal([]string{nodes["b"].ID}, MatchNodes(trafficMap, graph.NodeOptions{Namespace: "bookinfo", Workload: "b", Cluster: graph.Unknown}))
+	assert.Equal([]string{nodes["b"].ID}, MatchNodes(trafficMap, graph.NodeOptions{Namespace: "bookinfo", Workload: "b", Cluster: "east"}))
+	assert.Empty(MatchNodes(trafficMap, graph.NodeOptions{Namespace: "bookinfo", Workload: "b", Cluster: "west"}))
+	assert.Empty(MatchNodes(trafficMap, graph.NodeOptions{Namespace: "other", Workload: "b"}))
+	assert.Empty(MatchNodes(trafficMap, graph.NodeOptions{Namespace: "bookinfo", Service: "b"}))
+}
+
+func TestAnalyzeDependencies(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap, nodes := dependenciesTestTraffic()
+	id := func(wls ...string) []string {
+		ids := []string{}
+		for _, wl := range wls {
+			ids = append(ids, nodes[wl].ID)
+		}
+		return ids
+	}
+
+	d := AnalyzeDependencies(trafficMap, id("c"))
+
+	// c is reachable from b, which it also sends to
+	assert.Equal(id("a", "b", "e"), d.Upstream)
+	assert.Equal(id("b", "d", "e"), d.Downstream)
+	assert.Equal([][]string{id("b", "c"), id("b", "e", "c")}, d.Cycles)
+	assert.False(d.Truncated)
+
+	paths := map[string]float64{}
+	for _, p := range d.Paths {
+		key := p.Direction
+		for _, n := range p.Nodes {
+			key += " " + trafficMap[n].Workload
+		}
+		paths[key] = p.ResponseTime
+	}
+	assert.Equal(map[string]float64{
+		"upstream a b c":   30.0,
+		"upstream a b e c": 100.0,
+		"downstream c b e": 45.0,
+		"downstream c d":   30.0,
+	}, paths)
+	assert.Equal(DependencyPath{Direction: DependencyUpstream, Nodes: id("a", "b", "e", "c"), ResponseTime: 100.0}, *d.CriticalPath)
+
+	filtered := FilterDependencies(trafficMap, d)
+	assert.Equal(5, len(filtered))
+	_, ok := filtered[nodes["x"].ID]
+	assert.False(ok)
+	edges := 0
+	for _, n := range filtered {
+		edges += len(n.Edges)
+	}
+	assert.Equal(6, edges)
+	// the original traffic map is unchanged
+	assert.Equal(7, len(trafficMap))
+}
+
+func TestAnalyzeDependenciesNoTraffic(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap, nodes := dependenciesTestTraffic()
+	d := AnalyzeDependencies(trafficMap, []string{nodes["d"].ID})
+
+	assert.Equal(4, len(d.Upstream))
+	assert.Empty(d.Downstream)
+	// the b, c, e cycles are upstream of d
+	assert.Equal(2, len(d.Cycles))
+	assert.NotNil(d.CriticalPath)
+	assert.Equal(DependencyUpstream, d.CriticalPath.Direction)
+
+	d = AnalyzeDependencies(trafficMap, []string{})
+	assert.Empty(d.Paths)
+	assert.Nil(d.CriticalPath)
+}
@@ -0,0 +1,194 @@
+package telemetry
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kiali/kiali/graph"
+)
+
+// Thresholds used to decide whether a node or edge present in both graphs has changed. Small fluctuations
+// are expected between any two time periods and should not be flagged.
+const (
+	DiffErrorRateThreshold    = 1.0 // absolute, in percentage points
+	DiffRequestRateThreshold  = 0.1 // relative to the baseline value
+	DiffResponseTimeThreshold = 0.1 // relative to the baseline value
+)
+
+// DiffTrafficMaps compares trafficMap to baselineTrafficMap and returns trafficMap, decorated with graph.Diff
+// metadata for every node and edge. Nodes and edges found only in the baseline are added to the returned map
+// with status graph.DiffRemoved. They carry no traffic, only the negative delta.
+func DiffTrafficMaps(trafficMap, baselineTrafficMap graph.TrafficMap) graph.TrafficMap {
+	// first, add any removed nodes, we need them in place before processing edges
+	for id, baselineNode := range baselineTrafficMap {
+		if _, ok := trafficMap[id]; ok {
+			continue
+		}
+		removedNode := *baselineNode
+		removedNode.Edges = []*graph.Edge{}
+		removedNode.Metadata = graph.NewMetadata()
+		for k, v := range baselineNode.Metadata {
+			removedNode.Metadata[k] = v
+		}
+		resetNodeTraffic(removedNode.Metadata)
+		removedNode.Metadata[graph.Diff] = &graph.DiffMetadata{Status: graph.DiffRemoved}
+		trafficMap[id] = &removedNode
+	}
+
+	for id, n := range trafficMap {
+		if _, isRemoved := n.Metadata[graph.Diff]; isRemoved {
+			// removed edges for removed nodes are handled below
+			continue
+		}
+		baselineNode, ok := baselineTrafficMap[id]
+		if !ok {
+			n.Metadata[graph.Diff] = &graph.DiffMetadata{Status: graph.DiffAdded}
+			for _, e := range n.Edges {
+				total, percentErr := edgeTraffic(e)
+				e.Metadata[graph.Diff] = &graph.DiffMetadata{Status: graph.DiffAdded, RequestRateDelta: total, ErrorRateDelta: percentErr}
+			}
+			continue
+		}
+
+		nodeChanged := false
+		baselineEdges := make(map[string]*graph.Edge, len(baselineNode.Edges))
+		for _, be := range baselineNode.Edges {
+			baselineEdges[edgeKey(be)] = be
+		}
+		for _, e := range n.Edges {
+			key := edgeKey(e)
+			diff := diffEdge(e, baselineEdges[key])
+			e.Metadata[graph.Diff] = diff
+			nodeChanged = nodeChanged || diff.Status != graph.DiffUnchanged
+			delete(baselineEdges, key)
+		}
+		// whatever remains has been removed
+		for _, be := range baselineEdges {
+			dest := trafficMap[be.Dest.ID]
+			total, percentErr := edgeTraffic(be)
+			e := n.AddEdge(dest)
+			e.Metadata[graph.ProtocolKey] = be.Metadata[graph.ProtocolKey]
+			e.Metadata[graph.Diff] = &graph.DiffMetadata{Status: graph.DiffRemoved, RequestRateDelta: -total, ErrorRateDelta: -percentErr}
+			nodeChanged = true
+		}
+
+		if nodeChanged || nodeTrafficChanged(n, baselineNode) {
+			n.Metadata[graph.Diff] = &graph.DiffMetadata{Status: graph.DiffChanged}
+		} else {
+			n.Metadata[graph.Diff] = &graph.DiffMetadata{Status: graph.DiffUnchanged}
+		}
+	}
+
+	// removed nodes may also have removed outgoing edges
+	for id, baselineNode := range baselineTrafficMap {
+		n := trafficMap[id]
+		if n.Metadata[graph.Diff].(*graph.DiffMetadata).Status != graph.DiffRemoved {
+			continue
+		}
+		for _, be := range baselineNode.Edges {
+			total, percentErr := edgeTraffic(be)
+			e := n.AddEdge(trafficMap[be.Dest.ID])
+			e.Metadata[graph.ProtocolKey] = be.Metadata[graph.ProtocolKey]
+			e.Metadata[graph.Diff] = &graph.DiffMetadata{Status: graph.DiffRemoved, RequestRateDelta: -total, ErrorRateDelta: -percentErr}
+		}
+	}
+
+	return trafficMap
+}
+
+func diffEdge(e, baselineEdge *graph.Edge) *graph.DiffMetadata {
+	total, percentErr := edgeTraffic(e)
+	if baselineEdge == nil {
+		return &graph.DiffMetadata{Status: graph.DiffAdded, RequestRateDelta: total, ErrorRateDelta: percentErr}
+	}
+
+	baselineTotal, baselinePercentErr := edgeTraffic(baselineEdge)
+	diff := &graph.DiffMetadata{
+		Status:           graph.DiffUnchanged,
+		ErrorRateDelta:   percentErr - baselinePercentErr,
+		RequestRateDelta: total - baselineTotal,
+	}
+	changed := isRelativeChange(diff.RequestRateDelta, baselineTotal, DiffRequestRateThreshold) ||
+		math.Abs(diff.ErrorRateDelta) >= DiffErrorRateThreshold
+
+	responseTime, hasResponseTime := e.Metadata[graph.ResponseTime]
+	baselineResponseTime, hasBaselineResponseTime := baselineEdge.Metadata[graph.ResponseTime]
+	if hasResponseTime && hasBaselineResponseTime {
+		diff.HasResponseTime = true
+		diff.ResponseTimeDelta = responseTime.(float64) - baselineResponseTime.(float64)
+		changed = changed || isRelativeChange(diff.ResponseTimeDelta, baselineResponseTime.(float64), DiffResponseTimeThreshold)
+	}
+
+	if changed {
+		diff.Status = graph.DiffChanged
+	}
+	return diff
+}
+
+// nodeTrafficChanged returns true if any of the node's rates changed beyond the threshold. This catches
+// changes in incoming traffic, which are not reflected in the node's own (outgoing) edges.
+func nodeTrafficChanged(n, baselineNode *graph.Node) bool {
+	for _, p := range graph.Protocols {
+		for _, r := range p.NodeRates {
+			val := getRate(n.Metadata, r.Name)
+			baselineVal := getRate(baselineNode.Metadata, r.Name)
+			if isRelativeChange(val-baselineVal, baselineVal, DiffRequestRateThreshold) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// edgeTraffic returns the total rate for the edge protocol, and the percentage of that traffic in error
+func edgeTraffic(e *graph.Edge) (total, percentErr float64) {
+	protocol, ok := e.Metadata[graph.ProtocolKey]
+	if !ok {
+		return 0.0, 0.0
+	}
+	for _, p := range graph.Protocols {
+		if p.Name != protocol {
+			continue
+		}
+		err := 0.0
+		for _, r := range p.EdgeRates {
+			switch {
+			case r.IsTotal:
+				total = getRate(e.Metadata, r.Name)
+			case r.IsErr:
+				err += getRate(e.Metadata, r.Name)
+			}
+		}
+		if total > 0.0 {
+			percentErr = err / total * 100.0
+		}
+		break
+	}
+	return total, percentErr
+}
+
+func resetNodeTraffic(md graph.Metadata) {
+	for _, p := range graph.Protocols {
+		for _, r := range p.NodeRates {
+			delete(md, r.Name)
+		}
+	}
+}
+
+func isRelativeChange(delta, baseline, threshold float64) bool {
+	if baseline == 0.0 {
+		return delta != 0.0
+	}
+	return math.Abs(delta/baseline) > threshold
+}
+
+func getRate(md graph.Metadata, k graph.MetadataKey) float64 {
+	if rate, ok := md[k]; ok {
+		return rate.(float64)
+	}
+	return 0.0
+}
+
+func edgeKey(e *graph.Edge) string {
+	return fmt.Sprintf("%s %s %v", e.Source.ID, e.Dest.ID, e.Metadata[graph.ProtocolKey])
+}
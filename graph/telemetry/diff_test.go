@@ -0,0 +1,115 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+// addHTTPEdge adds an http edge with the given total rate, of which errRate is 5xx
+func addHTTPEdge(source, dest *graph.Node, rate, errRate float64) *graph.Edge {
+	e := source.AddEdge(dest)
+	e.Metadata[graph.ProtocolKey] = "http"
+	graph.AddToMetadata("http", rate-errRate, "200", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+	graph.AddToMetadata("http", errRate, "500", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+	return e
+}
+
+func newTestNode(trafficMap graph.TrafficMap, workload string) *graph.Node {
+	n := graph.NewNode("east", "bookinfo", "", "bookinfo", workload, workload, "v1", graph.GraphTypeWorkload)
+	trafficMap[n.ID] = &n
+	return &n
+}
+
+func diffOf(md graph.Metadata) *graph.DiffMetadata {
+	return md[graph.Diff].(*graph.DiffMetadata)
+}
+
+func TestDiffTrafficMaps(t *testing.T) {
+	assert := assert.New(t)
+
+	// baseline: a -> b, a -> c, d -> b
+	baseline := graph.NewTrafficMap()
+	ba := newTestNode(baseline, "a")
+	bb := newTestNode(baseline, "b")
+	bc := newTestNode(baseline, "c")
+	bd := newTestNode(baseline, "d")
+	baEdge := addHTTPEdge(ba, bb, 10.0, 0.0)
+	baEdge.Metadata[graph.ResponseTime] = 100.0
+	addHTTPEdge(ba, bc, 10.0, 0.0)
+	addHTTPEdge(bd, bb, 5.0, 0.0)
+
+	// current: a -> b (more errors, slower), a -> e (new), d is gone, c is idle
+	current := graph.NewTrafficMap()
+	a := newTestNode(current, "a")
+	b := newTestNode(current, "b")
+	c := newTestNode(current, "c")
+	e := newTestNode(current, "e")
+	abEdge := addHTTPEdge(a, b, 10.0, 2.0)
+	abEdge.Metadata[graph.ResponseTime] = 150.0
+	addHTTPEdge(a, e, 4.0, 0.0)
+
+	result := DiffTrafficMaps(current, baseline)
+
+	assert.Equal(5, len(result))
+	assert.Equal(graph.DiffChanged, diffOf(result[a.ID].Metadata).Status)
+	assert.Equal(graph.DiffChanged, diffOf(result[b.ID].Metadata).Status)
+	assert.Equal(graph.DiffChanged, diffOf(result[c.ID].Metadata).Status)
+	assert.Equal(graph.DiffAdded, diffOf(result[e.ID].Metadata).Status)
+	assert.Equal(graph.DiffRemoved, diffOf(result[bd.ID].Metadata).Status)
+
+	// removed nodes carry no traffic
+	_, hasTraffic := result[bd.ID].Metadata["httpOut"]
+	assert.False(hasTraffic)
+
+	edges := make(map[string]*graph.Edge)
+	for _, n := range result {
+		for _, e := range n.Edges {
+			edges[edgeKey(e)] = e
+		}
+	}
+	assert.Equal(4, len(edges))
+
+	ab := diffOf(edges[edgeKey(abEdge)].Metadata)
+	assert.Equal(graph.DiffChanged, ab.Status)
+	assert.Equal(0.0, ab.RequestRateDelta)
+	assert.Equal(20.0, ab.ErrorRateDelta)
+	assert.True(ab.HasResponseTime)
+	assert.Equal(50.0, ab.ResponseTimeDelta)
+
+	ae := diffOf(edges[a.ID+" "+e.ID+" http"].Metadata)
+	assert.Equal(graph.DiffAdded, ae.Status)
+	assert.Equal(4.0, ae.RequestRateDelta)
+
+	ac := diffOf(edges[a.ID+" "+c.ID+" http"].Metadata)
+	assert.Equal(graph.DiffRemoved, ac.Status)
+	assert.Equal(-10.0, ac.RequestRateDelta)
+
+	db := edges[bd.ID+" "+b.ID+" http"]
+	assert.Equal(graph.DiffRemoved, diffOf(db.Metadata).Status)
+	assert.Equal(-5.0, diffOf(db.Metadata).RequestRateDelta)
+	assert.Equal(result[b.ID], db.Dest)
+}
+
+func TestDiffTrafficMapsUnchanged(t *testing.T) {
+	assert := assert.New(t)
+
+	baseline := graph.NewTrafficMap()
+	addHTTPEdge(newTestNode(baseline, "a"), newTestNode(baseline, "b"), 10.0, 0.0)
+
+	// a small fluctuation is not a change
+	current := graph.NewTrafficMap()
+	a := newTestNode(current, "a")
+	b := newTestNode(current, "b")
+	e := addHTTPEdge(a, b, 10.5, 0.0)
+
+	DiffTrafficMaps(current, baseline)
+
+	assert.Equal(graph.DiffUnchanged, diffOf(a.Metadata).Status)
+	assert.Equal(graph.DiffUnchanged, diffOf(b.Metadata).Status)
+	assert.Equal(graph.DiffUnchanged, diffOf(e.Metadata).Status)
+	assert.InDelta(0.5, diffOf(e.Metadata).RequestRateDelta, 0.0001)
+	assert.False(diffOf(e.Metadata).HasResponseTime)
+}
@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"sort"
+
+	"github.com/kiali/kiali/graph"
+)
+
+// FilterEntryPoints returns a new TrafficMap holding only the paths through the external entry points of
+// the mesh: the nodes reachable from the ingress gateways, and the nodes reaching the egress nodes (the
+// PassthroughCluster and BlackHoleCluster nodes, and the MESH_EXTERNAL service entries). The ingress gateways
+// are identified by the istio appender and the service entries by the serviceEntry appender. The nodes
+// reachable from an ingress gateway are annotated with the gateway hostnames, see graph.EntryPoints. The
+// nodes are copies, the trafficMap is not modified.
+func FilterEntryPoints(trafficMap graph.TrafficMap) graph.TrafficMap {
+	outgoing, incoming := dependencyAdjacency(trafficMap)
+
+	ingress := []string{}
+	egress := []string{}
+	for id, n := range trafficMap {
+		if _, ok := n.Metadata[graph.IsIngressGateway]; ok {
+			ingress = append(ingress, id)
+		}
+		if isEgressNode(n) {
+			egress = append(egress, id)
+		}
+	}
+	sort.Strings(ingress)
+	sort.Strings(egress)
+
+	// nodes downstream of an ingress gateway, mapped to the hostnames of the gateways reaching them
+	fromIngress := make(map[string]map[string]bool)
+	for _, id := range ingress {
+		hostnames := make(map[string]bool)
+		for _, hosts := range trafficMap[id].Metadata[graph.IsIngressGateway].(graph.GatewaysMetadata) {
+			for _, host := range hosts {
+				hostnames[host] = true
+			}
+		}
+		for _, reached := range append(reachable(outgoing, []string{id}, map[string]bool{id: true}), id) {
+			if _, ok := fromIngress[reached]; !ok {
+				fromIngress[reached] = make(map[string]bool)
+			}
+			// the gateway itself already reports its hostnames
+			if reached == id {
+				continue
+			}
+			for host := range hostnames {
+				fromIngress[reached][host] = true
+			}
+		}
+	}
+
+	// nodes upstream of an egress node
+	toEgress := make(map[string]bool)
+	for _, id := range egress {
+		toEgress[id] = true
+	}
+	for _, id := range reachable(incoming, egress, toEgress) {
+		toEgress[id] = true
+	}
+
+	result := graph.NewTrafficMap()
+	for id, n := range trafficMap {
+		_, isFromIngress := fromIngress[id]
+		if !isFromIngress && !toEgress[id] {
+			continue
+		}
+		filteredNode := *n
+		filteredNode.Metadata = graph.NewMetadata()
+		for k, v := range n.Metadata {
+			filteredNode.Metadata[k] = v
+		}
+		if len(fromIngress[id]) > 0 {
+			hostnames := make([]string, 0, len(fromIngress[id]))
+			for host := range fromIngress[id] {
+				hostnames = append(hostnames, host)
+			}
+			sort.Strings(hostnames)
+			filteredNode.Metadata[graph.EntryPoints] = hostnames
+		}
+		filteredNode.Edges = []*graph.Edge{}
+		for _, e := range n.Edges {
+			_, sourceFromIngress := fromIngress[e.Source.ID]
+			_, destFromIngress := fromIngress[e.Dest.ID]
+			if (sourceFromIngress && destFromIngress) || (toEgress[e.Source.ID] && toEgress[e.Dest.ID]) {
+				filteredNode.Edges = append(filteredNode.Edges, e)
+			}
+		}
+		result[id] = &filteredNode
+	}
+	return result
+}
+
+// isEgressNode returns true if the node represents traffic leaving the mesh
+func isEgressNode(n *graph.Node) bool {
+	if n.Metadata[graph.IsEgressCluster] == true {
+		return true
+	}
+	if se, ok := n.Metadata[graph.IsServiceEntry].(*graph.SEInfo); ok {
+		return se.Location == "MESH_EXTERNAL"
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+// entryPointsTestTraffic returns:
+//
+//	ingress -> a -> b -> passthrough
+//	           a -> c
+//	      x -> b
+//	      y -> external (MESH_EXTERNAL)
+//	      y -> internal (MESH_INTERNAL)
+//	      z -> a
+func entryPointsTestTraffic() (graph.TrafficMap, map[string]*graph.Node) {
+	trafficMap := graph.NewTrafficMap()
+	nodes := map[string]*graph.Node{}
+	for _, wl := range []string{"ingress", "a", "b", "c", "x", "y", "z", "passthrough", "external", "internal"} {
+		nodes[wl] = newTestNode(trafficMap, wl)
+	}
+	nodes["ingress"].Metadata[graph.IsIngressGateway] = graph.GatewaysMetadata{
+		"bookinfo-gateway": {"bookinfo.example.com", "*.example.com"},
+		"other-gateway":    {"bookinfo.example.com"},
+	}
+	nodes["passthrough"].Metadata[graph.IsEgressCluster] = true
+	nodes["external"].Metadata[graph.IsServiceEntry] = &graph.SEInfo{Location: "MESH_EXTERNAL"}
+	nodes["internal"].Metadata[graph.IsServiceEntry] = &graph.SEInfo{Location: "MESH_INTERNAL"}
+
+	edge := func(source, dest string) {
+		addHTTPEdge(nodes[source], nodes[dest], 10.0, 0.0)
+	}
+	edge("ingress", "a")
+	edge("a", "b")
+	edge("a", "c")
+	edge("b", "passthrough")
+	edge("x", "b")
+	edge("y", "external")
+	edge("y", "internal")
+	edge("z", "a")
+	return trafficMap, nodes
+}
+
+func TestFilterEntryPoints(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap, nodes := entryPointsTestTraffic()
+	filtered := FilterEntryPoints(trafficMap)
+
+	ids := []string{}
+	for _, wl := range []string{"ingress", "a", "b", "c", "x", "y", "z", "passthrough", "external"} {
+		ids = append(ids, nodes[wl].ID)
+	}
+	assert.ElementsMatch(ids, keysOf(filtered))
+
+	// the internal service entry is not an entry point
+	_, ok := filtered[nodes["internal"].ID]
+	assert.False(ok)
+	assert.Equal(1, len(filtered[nodes["y"].ID].Edges))
+
+	// z reaches the egress through a, but the ingress edges of a are not on an egress path
+	assert.Equal(1, len(filtered[nodes["z"].ID].Edges))
+	assert.Equal(2, len(filtered[nodes["a"].ID].Edges))
+
+	// the nodes reachable from the ingress gateway are annotated with its hostnames
+	hostnames := []string{"*.example.com", "bookinfo.example.com"}
+	for _, wl := range []string{"a", "b", "c", "passthrough"} {
+		assert.Equal(hostnames, filtered[nodes[wl].ID].Metadata[graph.EntryPoints], wl)
+	}
+	for _, wl := range []string{"ingress", "x", "y", "z", "external"} {
+		_, ok := filtered[nodes[wl].ID].Metadata[graph.EntryPoints]
+		assert.False(ok, wl)
+	}
+
+	// the trafficMap is not modified
+	assert.Equal(10, len(trafficMap))
+	_, ok = nodes["a"].Metadata[graph.EntryPoints]
+	assert.False(ok)
+	assert.Equal(2, len(nodes["y"].Edges))
+}
+
+func TestFilterEntryPointsNone(t *testing.T) {
+	trafficMap := graph.NewTrafficMap()
+	addHTTPEdge(newTestNode(trafficMap, "a"), newTestNode(trafficMap, "b"), 10.0, 0.0)
+
+	assert.Empty(t, FilterEntryPoints(trafficMap))
+}
+
+func keysOf(trafficMap graph.TrafficMap) []string {
+	keys := []string{}
+	for id := range trafficMap {
+		keys = append(keys, id)
+	}
+	return keys
+}
@@ -0,0 +1,292 @@
+package appender
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry/istio/util"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+)
+
+const (
+	// AnomalyAppenderName uniquely identifies the appender: anomaly
+	AnomalyAppenderName = "anomaly"
+
+	defaultAnomalyBaseline              = time.Hour
+	defaultAnomalyErrorThreshold        = 5.0 // percentage points
+	defaultAnomalyResponseTimeThreshold = 0.5 // 50% slower than the baseline
+)
+
+// AnomalyAppender is responsible for flagging edges whose error percentage or average response time
+// deviates from a baseline. The baseline window is the BaselineDuration immediately preceding the
+// requested (current) window. Each measure is given a score of deviation/threshold, and the edge is
+// anomalous if its highest score is >= 1. Anomalous edges are marked with their score, and the
+// destination node of an anomalous edge is marked with the highest score of its anomalous incoming edges.
+// Edges without baseline traffic are not scored, they are new and not comparable.
+// Only request traffic (not TCP or gRPC-message traffic) is considered.
+// Name: anomaly
+type AnomalyAppender struct {
+	BaselineDuration      time.Duration
+	ErrorThreshold        float64 // increase in error percentage, in percentage points
+	GraphType             string
+	InjectServiceNodes    bool
+	Namespaces            graph.NamespaceInfoMap
+	QueryTime             int64 // unix time in seconds
+	Rates                 graph.RequestedRates
+	ResponseTimeThreshold float64 // relative increase in average response time (e.g. 0.5 = 50% slower)
+}
+
+// anomalyStats holds the request traffic measured for an edge in a single time window
+type anomalyStats struct {
+	errRate      float64
+	rate         float64
+	responseTime float64 // average, in millis. 0 if not reported.
+}
+
+func (s *anomalyStats) percentErr() float64 {
+	if s.rate == 0.0 {
+		return 0.0
+	}
+	return s.errRate / s.rate * 100.0
+}
+
+// Name implements Appender
+func (a AnomalyAppender) Name() string {
+	return AnomalyAppenderName
+}
+
+// AppendGraph implements Appender
+func (a AnomalyAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	if a.Rates.Grpc != graph.RateRequests && a.Rates.Http != graph.RateRequests {
+		return
+	}
+
+	if globalInfo.PromClient == nil {
+		var err error
+		globalInfo.PromClient, err = prometheus.NewClient()
+		graph.CheckError(err)
+	}
+
+	a.appendGraph(trafficMap, namespaceInfo.Namespace, globalInfo.PromClient)
+}
+
+func (a AnomalyAppender) appendGraph(trafficMap graph.TrafficMap, namespace string, client *prometheus.Client) {
+	duration := a.Namespaces[namespace].Duration
+	log.Tracef("Generating anomalies; namespace = %v, baseline = %v", namespace, a.BaselineDuration)
+
+	// the baseline window ends where the current window begins
+	currentStats := a.queryStats(namespace, duration, time.Unix(a.QueryTime, 0), client)
+	baselineStats := a.queryStats(namespace, a.BaselineDuration, time.Unix(a.QueryTime, 0).Add(-duration), client)
+
+	a.applyAnomalies(trafficMap, currentStats, baselineStats)
+}
+
+// queryStats returns the request traffic stats, keyed by edge, for the window of length duration ending at queryTime
+func (a AnomalyAppender) queryStats(namespace string, duration time.Duration, queryTime time.Time, client *prometheus.Client) map[string]*anomalyStats {
+	statsMap := make(map[string]*anomalyStats)
+	groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol"
+
+	// query prometheus for the request and response time info, each in two queries:
+	// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
+	// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
+	// note - the query order is important as both queries may have overlapping results for edges within
+	//        the namespace.  The first reported value is preferred, so destination telemetry must come first.
+	for _, selector := range []string{
+		fmt.Sprintf(`reporter="destination",destination_service_namespace="%s"`, namespace),
+		fmt.Sprintf(`reporter="source",source_workload_namespace="%s"`, namespace),
+	} {
+		query := fmt.Sprintf(`sum(rate(%s{%s}[%vs])) by (%s,response_code,grpc_response_status) > 0`,
+			"istio_requests_total",
+			selector,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		vector := promQuery(query, queryTime, client.GetContext(), client.API(), a)
+		a.populateStatsMap(statsMap, &vector, false)
+
+		query = fmt.Sprintf(`sum(rate(%s{%s}[%vs])) by (%s) / sum(rate(%s{%s}[%vs])) by (%s) > 0`,
+			"istio_request_duration_milliseconds_sum",
+			selector,
+			int(duration.Seconds()), // range duration for the query
+			groupBy,
+			"istio_request_duration_milliseconds_count",
+			selector,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		vector = promQuery(query, queryTime, client.GetContext(), client.API(), a)
+		a.populateStatsMap(statsMap, &vector, true)
+	}
+
+	return statsMap
+}
+
+func (a AnomalyAppender) populateStatsMap(statsMap map[string]*anomalyStats, vector *model.Vector, isResponseTime bool) {
+	skipRequestsGrpc := a.Rates.Grpc != graph.RateRequests
+	skipRequestsHttp := a.Rates.Http != graph.RateRequests
+
+	// requests for an edge are reported once for each response code, so we need to track which edges have
+	// been reported by an earlier query (i.e. destination telemetry) to avoid double counting.
+	reportedByThisQuery := make(map[string]bool)
+
+	for _, s := range *vector {
+		m := s.Metric
+		lSourceCluster, sourceClusterOk := m["source_cluster"]
+		lSourceWlNs, sourceWlNsOk := m["source_workload_namespace"]
+		lSourceWl, sourceWlOk := m["source_workload"]
+		lSourceApp, sourceAppOk := m["source_canonical_service"]
+		lSourceVer, sourceVerOk := m["source_canonical_revision"]
+		lDestCluster, destClusterOk := m["destination_cluster"]
+		lDestSvcNs, destSvcNsOk := m["destination_service_namespace"]
+		lDestSvc, destSvcOk := m["destination_service"]
+		lDestSvcName, destSvcNameOk := m["destination_service_name"]
+		lDestWlNs, destWlNsOk := m["destination_workload_namespace"]
+		lDestWl, destWlOk := m["destination_workload"]
+		lDestApp, destAppOk := m["destination_canonical_service"]
+		lDestVer, destVerOk := m["destination_canonical_revision"]
+		lProtocol, protocolOk := m["request_protocol"]
+		lCode, codeOk := m["response_code"]
+		lGrpc, grpcOk := m["grpc_response_status"]
+
+		if !sourceWlNsOk || !sourceWlOk || !sourceAppOk || !sourceVerOk || !destSvcNsOk || !destSvcNameOk || !destSvcOk || !destWlNsOk || !destWlOk || !destAppOk || !destVerOk || !protocolOk || (!isResponseTime && !codeOk) {
+			log.Warningf("populateStatsMap: Skipping %s, missing expected labels", m.String())
+			continue
+		}
+
+		sourceWlNs := string(lSourceWlNs)
+		sourceWl := string(lSourceWl)
+		sourceApp := string(lSourceApp)
+		sourceVer := string(lSourceVer)
+		destSvc := string(lDestSvc)
+		protocol := string(lProtocol)
+
+		if (skipRequestsHttp && protocol == graph.HTTP.Name) || (skipRequestsGrpc && protocol == graph.GRPC.Name) {
+			continue
+		}
+
+		// handle clusters
+		sourceCluster, destCluster := util.HandleClusters(lSourceCluster, sourceClusterOk, lDestCluster, destClusterOk)
+
+		if util.IsBadSourceTelemetry(sourceCluster, sourceClusterOk, sourceWlNs, sourceWl, sourceApp) {
+			continue
+		}
+
+		val := float64(s.Value)
+
+		// handle unusual destinations
+		destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, _ := util.HandleDestination(sourceCluster, sourceWlNs, sourceWl, destCluster, string(lDestSvcNs), string(lDestSvc), string(lDestSvcName), string(lDestWlNs), string(lDestWl), string(lDestApp), string(lDestVer))
+
+		if util.IsBadDestTelemetry(destCluster, destClusterOk, destSvcNs, destSvc, destSvcName, destWl) {
+			continue
+		}
+
+		// Should not happen but if NaN for any reason, Just skip it
+		if math.IsNaN(val) {
+			continue
+		}
+
+		// don't inject a service node if destSvcName is not set or the dest node is already a service node.
+		inject := false
+		if a.InjectServiceNodes && graph.IsOK(destSvcName) {
+			_, destNodeType := graph.Id(destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, a.GraphType)
+			inject = (graph.NodeTypeService != destNodeType)
+		}
+
+		keys := []string{}
+		if inject {
+			// Only set response time on the outgoing edge. On the incoming edge, we can't validly aggregate response times of the outgoing edges (kiali-2297)
+			if !isResponseTime {
+				keys = append(keys, a.edgeKey(protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, "", "", "", ""))
+			}
+			keys = append(keys, a.edgeKey(protocol, destCluster, destSvcNs, destSvcName, "", "", "", destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer))
+		} else {
+			keys = append(keys, a.edgeKey(protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer))
+		}
+
+		for _, key := range keys {
+			stats, found := statsMap[key]
+			if !found {
+				stats = &anomalyStats{}
+				statsMap[key] = stats
+			}
+
+			if isResponseTime {
+				// We assume here the first reported value is preferred (i.e. defer to query order)
+				if stats.responseTime == 0.0 {
+					stats.responseTime = val
+				}
+				continue
+			}
+
+			if stats.rate > 0.0 && !reportedByThisQuery[key] {
+				continue
+			}
+			reportedByThisQuery[key] = true
+
+			stats.rate += val
+			if isRequestErr(protocol, util.HandleResponseCode(protocol, string(lCode), grpcOk, string(lGrpc))) {
+				stats.errRate += val
+			}
+		}
+	}
+}
+
+func (a AnomalyAppender) edgeKey(protocol, sourceCluster, sourceNs, sourceSvc, sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer string) string {
+	sourceID, _ := graph.Id(sourceCluster, sourceNs, sourceSvc, sourceNs, sourceWl, sourceApp, sourceVer, a.GraphType)
+	destID, _ := graph.Id(destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer, a.GraphType)
+	return fmt.Sprintf("%s %s %s", sourceID, destID, protocol)
+}
+
+func (a AnomalyAppender) applyAnomalies(trafficMap graph.TrafficMap, currentStats, baselineStats map[string]*anomalyStats) {
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			key := fmt.Sprintf("%s %s %s", e.Source.ID, e.Dest.ID, e.Metadata[graph.ProtocolKey].(string))
+			current, currentOk := currentStats[key]
+			baseline, baselineOk := baselineStats[key]
+			if !currentOk || !baselineOk || baseline.rate == 0.0 {
+				continue
+			}
+
+			score := a.score(current, baseline)
+			if score < 1.0 {
+				continue
+			}
+			e.Metadata[graph.IsAnomalous] = score
+			if nodeScore, ok := e.Dest.Metadata[graph.IsAnomalous]; !ok || nodeScore.(float64) < score {
+				e.Dest.Metadata[graph.IsAnomalous] = score
+			}
+		}
+	}
+}
+
+// score returns the highest deviation from the baseline, relative to the threshold for the measure
+func (a AnomalyAppender) score(current, baseline *anomalyStats) float64 {
+	score := 0.0
+	if a.ErrorThreshold > 0.0 {
+		score = (current.percentErr() - baseline.percentErr()) / a.ErrorThreshold
+	}
+	if a.ResponseTimeThreshold > 0.0 && current.responseTime > 0.0 && baseline.responseTime > 0.0 {
+		score = math.Max(score, (current.responseTime-baseline.responseTime)/baseline.responseTime/a.ResponseTimeThreshold)
+	}
+	return score
+}
+
+// isRequestErr returns true if the response code indicates a failed request, consistent with the graph's
+// error rates (see graph.AddToMetadata). No response is considered an error.
+func isRequestErr(protocol, code string) bool {
+	switch {
+	case code == "-":
+		return true
+	case protocol == graph.GRPC.Name && len(code) != 3:
+		return graph.IsGRPCErr(code)
+	default:
+		return graph.IsHTTPErr(code)
+	}
+}
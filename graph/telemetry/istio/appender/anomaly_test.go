@@ -0,0 +1,191 @@
+package appender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+)
+
+func anomalyMetric(sourceWl, sourceApp, sourceVer, destWl, destApp, destVer string) model.Metric {
+	return model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "bookinfo",
+		"source_workload":                model.LabelValue(sourceWl),
+		"source_canonical_service":       model.LabelValue(sourceApp),
+		"source_canonical_revision":      model.LabelValue(sourceVer),
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            model.LabelValue(destApp + ".bookinfo.svc.cluster.local"),
+		"destination_service_name":       model.LabelValue(destApp),
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           model.LabelValue(destWl),
+		"destination_canonical_service":  model.LabelValue(destApp),
+		"destination_canonical_revision": model.LabelValue(destVer),
+		"request_protocol":               "http"}
+}
+
+func withCode(m model.Metric, code string) model.Metric {
+	result := m.Clone()
+	result["response_code"] = model.LabelValue(code)
+	result["grpc_response_status"] = ""
+	return result
+}
+
+func TestAnomaly(t *testing.T) {
+	assert := assert.New(t)
+
+	groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol"
+	requestsQuery := func(selector, duration string) string {
+		return `round(sum(rate(istio_requests_total{` + selector + `}[` + duration + `])) by (` + groupBy + `,response_code,grpc_response_status) > 0,0.001)`
+	}
+	responseTimeQuery := func(selector, duration string) string {
+		return `round(sum(rate(istio_request_duration_milliseconds_sum{` + selector + `}[` + duration + `])) by (` + groupBy + `) / sum(rate(istio_request_duration_milliseconds_count{` + selector + `}[` + duration + `])) by (` + groupBy + `) > 0,0.001)`
+	}
+	incoming := `reporter="destination",destination_service_namespace="bookinfo"`
+	outgoing := `reporter="source",source_workload_namespace="bookinfo"`
+
+	ppReviewsV1 := anomalyMetric("productpage-v1", "productpage", "v1", "reviews-v1", "reviews", "v1")
+	ppReviewsV2 := anomalyMetric("productpage-v1", "productpage", "v1", "reviews-v2", "reviews", "v2")
+	reviewsRatings := anomalyMetric("reviews-v1", "reviews", "v1", "ratings-v1", "ratings", "v1")
+
+	// current window: reviews-v1 is failing 20% of requests, ratings is much slower, reviews-v2 slightly slower
+	currentRequests := model.Vector{
+		&model.Sample{Metric: withCode(ppReviewsV1, "200"), Value: 8.0},
+		&model.Sample{Metric: withCode(ppReviewsV1, "500"), Value: 2.0},
+		&model.Sample{Metric: withCode(ppReviewsV2, "200"), Value: 10.0},
+		&model.Sample{Metric: withCode(reviewsRatings, "200"), Value: 10.0},
+	}
+	currentResponseTime := model.Vector{
+		&model.Sample{Metric: ppReviewsV1, Value: 20.0},
+		&model.Sample{Metric: ppReviewsV2, Value: 25.0},
+		&model.Sample{Metric: reviewsRatings, Value: 50.0},
+	}
+	// the source proxy reports the same traffic, it should not be counted twice
+	currentOutgoingRequests := model.Vector{
+		&model.Sample{Metric: withCode(ppReviewsV1, "200"), Value: 8.0},
+		&model.Sample{Metric: withCode(ppReviewsV1, "500"), Value: 2.0},
+	}
+
+	// baseline window: all healthy
+	baselineRequests := model.Vector{
+		&model.Sample{Metric: withCode(ppReviewsV1, "200"), Value: 10.0},
+		&model.Sample{Metric: withCode(ppReviewsV2, "200"), Value: 10.0},
+		&model.Sample{Metric: withCode(reviewsRatings, "200"), Value: 10.0},
+	}
+	baselineResponseTime := model.Vector{
+		&model.Sample{Metric: ppReviewsV1, Value: 20.0},
+		&model.Sample{Metric: ppReviewsV2, Value: 20.0},
+		&model.Sample{Metric: reviewsRatings, Value: 20.0},
+	}
+	empty := model.Vector{}
+
+	client, api, err := setupMocked()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	mockQuery(api, requestsQuery(incoming, "60s"), &currentRequests)
+	mockQuery(api, responseTimeQuery(incoming, "60s"), &currentResponseTime)
+	mockQuery(api, requestsQuery(outgoing, "60s"), &currentOutgoingRequests)
+	mockQuery(api, responseTimeQuery(outgoing, "60s"), &empty)
+	mockQuery(api, requestsQuery(incoming, "3600s"), &baselineRequests)
+	mockQuery(api, responseTimeQuery(incoming, "3600s"), &baselineResponseTime)
+	mockQuery(api, requestsQuery(outgoing, "3600s"), &empty)
+	mockQuery(api, responseTimeQuery(outgoing, "3600s"), &empty)
+
+	trafficMap := anomalyTestTraffic()
+
+	duration, _ := time.ParseDuration("60s")
+	appender := AnomalyAppender{
+		BaselineDuration: time.Hour,
+		ErrorThreshold:   defaultAnomalyErrorThreshold,
+		GraphType:        graph.GraphTypeVersionedApp,
+		Namespaces: map[string]graph.NamespaceInfo{
+			"bookinfo": {
+				Name:     "bookinfo",
+				Duration: duration,
+			},
+		},
+		QueryTime: time.Now().Unix(),
+		Rates: graph.RequestedRates{
+			Grpc: graph.RateRequests,
+			Http: graph.RateRequests,
+			Tcp:  graph.RateSent,
+		},
+		ResponseTimeThreshold: defaultAnomalyResponseTimeThreshold,
+	}
+
+	appender.appendGraph(trafficMap, "bookinfo", client)
+
+	productpageID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	productpage := trafficMap[productpageID]
+	assert.Nil(productpage.Metadata[graph.IsAnomalous])
+	assert.Equal(2, len(productpage.Edges))
+	for _, e := range productpage.Edges {
+		switch e.Dest.Version {
+		case "v1":
+			// 20% errors, 20 percentage points over the baseline
+			assert.Equal(4.0, e.Metadata[graph.IsAnomalous])
+			assert.Equal(4.0, e.Dest.Metadata[graph.IsAnomalous])
+		case "v2":
+			// 25% slower, under the 50% threshold
+			assert.Nil(e.Metadata[graph.IsAnomalous])
+			assert.Nil(e.Dest.Metadata[graph.IsAnomalous])
+		}
+	}
+
+	reviewsID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	reviews := trafficMap[reviewsID]
+	assert.Equal(1, len(reviews.Edges))
+	// 150% slower
+	assert.Equal(3.0, reviews.Edges[0].Metadata[graph.IsAnomalous])
+	assert.Equal(3.0, reviews.Edges[0].Dest.Metadata[graph.IsAnomalous])
+}
+
+func TestAnomalySkipRates(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := anomalyTestTraffic()
+
+	appender := AnomalyAppender{
+		BaselineDuration: time.Hour,
+		GraphType:        graph.GraphTypeVersionedApp,
+		QueryTime:        time.Now().Unix(),
+		Rates: graph.RequestedRates{
+			Grpc: graph.RateTotal,
+			Http: graph.RateNone,
+			Tcp:  graph.RateSent,
+		},
+	}
+
+	// no request traffic requested, so no queries should be issued
+	appender.AppendGraph(trafficMap, nil, graph.NewAppenderNamespaceInfo("bookinfo"))
+
+	for _, n := range trafficMap {
+		assert.Nil(n.Metadata[graph.IsAnomalous])
+	}
+}
+
+func anomalyTestTraffic() graph.TrafficMap {
+	productpage := graph.NewNode(business.DefaultClusterID, "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	reviewsV1 := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	reviewsV2 := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews-v2", "reviews", "v2", graph.GraphTypeVersionedApp)
+	ratings := graph.NewNode(business.DefaultClusterID, "bookinfo", "ratings", "bookinfo", "ratings-v1", "ratings", "v1", graph.GraphTypeVersionedApp)
+	trafficMap := graph.NewTrafficMap()
+
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviewsV1.ID] = &reviewsV1
+	trafficMap[reviewsV2.ID] = &reviewsV2
+	trafficMap[ratings.ID] = &ratings
+
+	productpage.AddEdge(&reviewsV1).Metadata[graph.ProtocolKey] = "http"
+	productpage.AddEdge(&reviewsV2).Metadata[graph.ProtocolKey] = "http"
+	reviewsV1.AddEdge(&ratings).Metadata[graph.ProtocolKey] = "http"
+
+	return trafficMap
+}
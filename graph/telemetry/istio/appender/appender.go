@@ -2,7 +2,10 @@ package appender
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/models"
@@ -23,6 +26,10 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 			switch appenderName {
 			case AggregateNodeAppenderName:
 				requestedAppenders[AggregateNodeAppenderName] = true
+			case AnomalyAppenderName:
+				requestedAppenders[AnomalyAppenderName] = true
+			case AuthorizationPolicyAppenderName:
+				requestedAppenders[AuthorizationPolicyAppenderName] = true
 			case DeadNodeAppenderName:
 				requestedAppenders[DeadNodeAppenderName] = true
 			case HealthConfigAppenderName:
@@ -31,8 +38,16 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 				requestedAppenders[IdleNodeAppenderName] = true
 			case IstioAppenderName:
 				requestedAppenders[IstioAppenderName] = true
+			case RequestSizeAppenderName:
+				requestedAppenders[RequestSizeAppenderName] = true
+			case ResponseSizeAppenderName:
+				requestedAppenders[ResponseSizeAppenderName] = true
 			case ResponseTimeAppenderName:
 				requestedAppenders[ResponseTimeAppenderName] = true
+			case ResponseTimePercentilesAppenderName:
+				requestedAppenders[ResponseTimePercentilesAppenderName] = true
+			case RootCauseAppenderName:
+				requestedAppenders[RootCauseAppenderName] = true
 			case SecurityPolicyAppenderName:
 				requestedAppenders[SecurityPolicyAppenderName] = true
 			case ServiceEntryAppenderName:
@@ -44,7 +59,10 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 			case "":
 				// skip
 			default:
-				graph.BadRequest(fmt.Sprintf("Invalid appender [%s]", appenderName))
+				if !IsExternalAppender(appenderName) {
+					graph.BadRequest(fmt.Sprintf("Invalid appender [%s]", appenderName))
+				}
+				requestedAppenders[appenderName] = true
 			}
 		}
 	}
@@ -96,7 +114,15 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 		}
 		appenders = append(appenders, a)
 	}
-	if _, ok := requestedAppenders[SecurityPolicyAppenderName]; ok || o.Appenders.All {
+	// The percentiles appenders each run six queries, so they must be explicitly requested
+	for _, name := range []string{ResponseTimePercentilesAppenderName, RequestSizeAppenderName, ResponseSizeAppenderName} {
+		if _, ok := requestedAppenders[name]; ok {
+			appenders = append(appenders, NewPercentilesAppender(name, o))
+		}
+	}
+	// The authorizationPolicy appender requires the principals reported by the securityPolicy appender
+	_, authorizationPolicyRequested := requestedAppenders[AuthorizationPolicyAppenderName]
+	if _, ok := requestedAppenders[SecurityPolicyAppenderName]; ok || o.Appenders.All || authorizationPolicyRequested {
 		a := SecurityPolicyAppender{
 			GraphType:          o.GraphType,
 			InjectServiceNodes: o.InjectServiceNodes,
@@ -106,6 +132,13 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 		}
 		appenders = append(appenders, a)
 	}
+	// The authorizationPolicy appender fetches the policies of every destination namespace, so it must be explicitly requested
+	if authorizationPolicyRequested {
+		a := AuthorizationPolicyAppender{
+			AccessibleNamespaces: o.AccessibleNamespaces,
+		}
+		appenders = append(appenders, a)
+	}
 	if _, ok := requestedAppenders[ThroughputAppenderName]; ok || o.Appenders.All {
 		throughputType := o.Params.Get("throughputType")
 		if throughputType != "" {
@@ -125,6 +158,34 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 		}
 		appenders = append(appenders, a)
 	}
+	// The anomaly appender runs twice the queries of the responseTime appender, so it must be explicitly requested
+	if _, ok := requestedAppenders[AnomalyAppenderName]; ok {
+		baselineDuration := defaultAnomalyBaseline
+		if baselineString := o.Params.Get("anomalyBaseline"); baselineString != "" {
+			var err error
+			if baselineDuration, err = time.ParseDuration(baselineString); err != nil || baselineDuration <= 0 {
+				graph.BadRequest(fmt.Sprintf("Invalid anomalyBaseline, expecting a positive duration [%s]", baselineString))
+			}
+		}
+		a := AnomalyAppender{
+			BaselineDuration:      baselineDuration,
+			ErrorThreshold:        parseAnomalyThreshold(o, "anomalyErrorThreshold", defaultAnomalyErrorThreshold),
+			GraphType:             o.GraphType,
+			InjectServiceNodes:    o.InjectServiceNodes,
+			Namespaces:            o.Namespaces,
+			QueryTime:             o.QueryTime,
+			Rates:                 o.Rates,
+			ResponseTimeThreshold: parseAnomalyThreshold(o, "anomalyResponseTimeThreshold", defaultAnomalyResponseTimeThreshold),
+		}
+		appenders = append(appenders, a)
+	}
+	// The rootCause appender fetches the config of every destination namespace, so it must be explicitly requested
+	if _, ok := requestedAppenders[RootCauseAppenderName]; ok {
+		a := RootCauseAppender{
+			AccessibleNamespaces: o.AccessibleNamespaces,
+		}
+		appenders = append(appenders, a)
+	}
 	if _, ok := requestedAppenders[AggregateNodeAppenderName]; ok || o.Appenders.All {
 		aggregate := o.NodeOptions.Aggregate
 		if aggregate == "" {
@@ -169,13 +230,50 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 		}
 		appenders = append(appenders, a)
 	}
+	// External appenders may use the decorations of the built-in appenders. They call webhooks, so they must be
+	// explicitly requested
+	for _, c := range getExternalAppenderConfigs() {
+		if _, ok := requestedAppenders[c.Name]; ok {
+			a := ExternalAppender{
+				Config:     c,
+				GraphType:  o.GraphType,
+				Namespaces: o.Namespaces,
+				QueryTime:  o.QueryTime,
+			}
+			appenders = append(appenders, a)
+		}
+	}
+	// Boxing by label or workloadGroup requires the node decoration, run it after any nodes are added
+	if o.BoxByLabel != "" || o.BoxByWorkloadGroup {
+		a := BoxByAppender{
+			AccessibleNamespaces: o.AccessibleNamespaces,
+			Label:                o.BoxByLabel,
+			WorkloadGroup:        o.BoxByWorkloadGroup,
+		}
+		appenders = append(appenders, a)
+	}
 
 	return appenders
 }
 
+func parseAnomalyThreshold(o graph.TelemetryOptions, param string, defaultThreshold float64) float64 {
+	thresholdString := o.Params.Get(param)
+	if thresholdString == "" {
+		return defaultThreshold
+	}
+	threshold, err := strconv.ParseFloat(thresholdString, 64)
+	if err != nil || threshold <= 0.0 {
+		graph.BadRequest(fmt.Sprintf("Invalid %s, expecting a positive number [%s]", param, thresholdString))
+	}
+	return threshold
+}
+
 const (
+	authorizationPoliciesKey = "authorizationPoliciesKey" // global vendor info map[namespace]authorizationPolicies
+	rootCauseIstioConfigKey  = "rootCauseIstioConfigKey"  // global vendor info map[namespace]IstioConfigList, DestinationRules and VirtualServices only
 	serviceDefinitionListKey = "serviceDefinitionListKey" // global vendor info map[namespace]serviceDefinitionList
 	serviceEntryHostsKey     = "serviceEntryHostsKey"     // global vendor info service entries for all accessible namespaces
+	workloadGroupsKey        = "workloadGroupsKey"        // global vendor info map[namespace]workloadGroups
 	workloadListKey          = "workloadListKey"          // global vendor info map[namespace]workloadListKey
 )
 
@@ -209,7 +307,25 @@ func (seh serviceEntryHosts) addHost(host string, se *serviceEntry) {
 	se.hosts = append(se.hosts, host)
 }
 
+// resolveHomeCluster sets the globalInfo HomeCluster, if not already set
+func resolveHomeCluster(gi *graph.AppenderGlobalInfo) {
+	gi.Lock()
+	defer gi.Unlock()
+
+	if gi.HomeCluster == "" {
+		gi.HomeCluster = business.DefaultClusterID
+		c, err := gi.Business.Mesh.ResolveKialiControlPlaneCluster(nil)
+		graph.CheckError(err)
+		if c != nil {
+			gi.HomeCluster = c.Name
+		}
+	}
+}
+
 func getServiceDefinitionList(namespace string, gi *graph.AppenderGlobalInfo) *models.ServiceDefinitionList {
+	gi.Lock()
+	defer gi.Unlock()
+
 	var serviceDefinitionListMap map[string]*models.ServiceDefinitionList
 	if existingServiceDefinitionMap, ok := gi.Vendor[serviceDefinitionListKey]; ok {
 		serviceDefinitionListMap = existingServiceDefinitionMap.(map[string]*models.ServiceDefinitionList)
@@ -241,6 +357,7 @@ func getServiceDefinition(namespace, serviceName string, gi *graph.AppenderGloba
 	return nil, false
 }
 
+// getServiceEntryHosts returns the cached service entry hosts, the caller must hold the globalInfo lock
 func getServiceEntryHosts(gi *graph.AppenderGlobalInfo) (serviceEntryHosts, bool) {
 	if seHosts, ok := gi.Vendor[serviceEntryHostsKey]; ok {
 		return seHosts.(serviceEntryHosts), true
@@ -249,6 +366,9 @@ func getServiceEntryHosts(gi *graph.AppenderGlobalInfo) (serviceEntryHosts, bool
 }
 
 func getWorkloadList(namespace string, gi *graph.AppenderGlobalInfo) *models.WorkloadList {
+	gi.Lock()
+	defer gi.Unlock()
+
 	var workloadListMap map[string]*models.WorkloadList
 	if existingWorkloadMap, ok := gi.Vendor[workloadListKey]; ok {
 		workloadListMap = existingWorkloadMap.(map[string]*models.WorkloadList)
@@ -0,0 +1,358 @@
+package appender
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/models"
+)
+
+const AuthorizationPolicyAppenderName = "authorizationPolicy"
+
+// AuthorizationPolicyAppender evaluates the edges into workload and app nodes against the AuthorizationPolicies
+// applied to the destination workloads, and marks the edges as allowed, denied or not protected by any
+// ALLOW policy. The edges reference the deciding policies. It uses the source principals reported by the
+// securityPolicy appender, which runs whenever this appender is requested.
+//
+// Only the source principal and namespace are known for observed traffic, both are unset for plaintext
+// traffic. Rule conditions on any other attribute (e.g. request methods or paths, ports, ipBlocks) are
+// assumed to match for ALLOW policies and to not match for DENY policies, so an edge is never reported as
+// denied due to an attribute we can't evaluate.
+// CUSTOM and AUDIT policies are ignored. The appender must be explicitly requested.
+// Name: authorizationPolicy
+type AuthorizationPolicyAppender struct {
+	AccessibleNamespaces map[string]time.Time
+}
+
+// authorizationSource holds the request attributes known for an edge
+type authorizationSource struct {
+	principal string // Istio principal format, e.g. cluster.local/ns/bookinfo/sa/bookinfo-productpage, empty if not mTLS
+	namespace string // derived from the principal, empty if not mTLS
+}
+
+// authorizationResult is the result of evaluating a request against the policies of a workload
+type authorizationResult struct {
+	action   string // graph.AuthorizationAllow | graph.AuthorizationDeny | graph.AuthorizationNone
+	policies []string
+}
+
+// Name implements Appender
+func (a AuthorizationPolicyAppender) Name() string {
+	return AuthorizationPolicyAppenderName
+}
+
+// AppendGraph implements Appender
+func (a AuthorizationPolicyAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	rootNamespace := config.Get().IstioNamespace
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			dest := e.Dest
+			if _, ok := a.AccessibleNamespaces[dest.Namespace]; !ok {
+				continue
+			}
+
+			// the policies are enforced by the destination workloads, service nodes are not enforcement points
+			var workloads []models.WorkloadListItem
+			switch dest.NodeType {
+			case graph.NodeTypeWorkload:
+				if workload, found := getWorkload(dest.Namespace, dest.Workload, globalInfo); found {
+					workloads = append(workloads, *workload)
+				}
+			case graph.NodeTypeApp:
+				workloads = getAppWorkloads(dest.Namespace, dest.App, dest.Version, globalInfo)
+			default:
+				continue
+			}
+			if len(workloads) == 0 {
+				continue
+			}
+
+			source := newAuthorizationSource(e.Metadata[graph.SourcePrincipal])
+			var policies models.AuthorizationPolicies
+			policies = append(policies, getAuthorizationPolicies(dest.Namespace, globalInfo)...)
+			if rootNamespace != dest.Namespace {
+				if _, ok := a.AccessibleNamespaces[rootNamespace]; ok {
+					policies = append(policies, getAuthorizationPolicies(rootNamespace, globalInfo)...)
+				}
+			}
+
+			results := make([]authorizationResult, len(workloads))
+			for i, workload := range workloads {
+				results[i] = evaluateAuthorizationPolicies(source, workload.Labels, policies)
+			}
+			result := mergeAuthorizationResults(results)
+			e.Metadata[graph.Authorization] = result.action
+			if len(result.policies) > 0 {
+				e.Metadata[graph.AuthorizationPolicies] = result.policies
+			}
+		}
+	}
+}
+
+func newAuthorizationSource(principal interface{}) authorizationSource {
+	source := authorizationSource{}
+	if p, ok := principal.(string); ok && p != "" && p != graph.Unknown {
+		source.principal = strings.TrimPrefix(p, "spiffe://")
+		// the principal has the form <trustDomain>/ns/<namespace>/sa/<serviceAccount>
+		parts := strings.Split(source.principal, "/")
+		for i := 0; i < len(parts)-1; i++ {
+			if parts[i] == "ns" {
+				source.namespace = parts[i+1]
+				break
+			}
+		}
+	}
+	return source
+}
+
+// evaluateAuthorizationPolicies returns the result of a request from source to the workload with the given labels
+func evaluateAuthorizationPolicies(source authorizationSource, workloadLabels map[string]string, policies models.AuthorizationPolicies) authorizationResult {
+	var allowPolicies, allowMatches, denyMatches []string
+	for _, policy := range policies {
+		if !authorizationPolicyApplies(policy, workloadLabels) {
+			continue
+		}
+		name := fmt.Sprintf("%s/%s", policy.Metadata.Namespace, policy.Metadata.Name)
+		switch authorizationPolicyAction(policy) {
+		case "ALLOW":
+			allowPolicies = append(allowPolicies, name)
+			if authorizationRulesMatch(policy.Spec.Rules, source, true) {
+				allowMatches = append(allowMatches, name)
+			}
+		case "DENY":
+			if authorizationRulesMatch(policy.Spec.Rules, source, false) {
+				denyMatches = append(denyMatches, name)
+			}
+		}
+	}
+
+	switch {
+	case len(denyMatches) > 0:
+		return authorizationResult{action: graph.AuthorizationDeny, policies: denyMatches}
+	case len(allowPolicies) == 0:
+		return authorizationResult{action: graph.AuthorizationNone}
+	case len(allowMatches) > 0:
+		return authorizationResult{action: graph.AuthorizationAllow, policies: allowMatches}
+	default:
+		// no ALLOW policy matches the request
+		return authorizationResult{action: graph.AuthorizationDeny, policies: allowPolicies}
+	}
+}
+
+// mergeAuthorizationResults combines the results for the workloads backing a node. The edge is denied if
+// any workload denies the request, otherwise it is not protected if any workload has no ALLOW policy.
+func mergeAuthorizationResults(results []authorizationResult) authorizationResult {
+	merged := authorizationResult{action: graph.AuthorizationAllow}
+	rank := map[string]int{graph.AuthorizationAllow: 0, graph.AuthorizationNone: 1, graph.AuthorizationDeny: 2}
+	for _, r := range results {
+		if rank[r.action] > rank[merged.action] {
+			merged = authorizationResult{action: r.action}
+		}
+		if r.action == merged.action {
+			merged.policies = append(merged.policies, r.policies...)
+		}
+	}
+
+	// de-duplicate the policy names
+	if len(merged.policies) > 0 {
+		names := make(map[string]bool)
+		policies := []string{}
+		for _, p := range merged.policies {
+			if !names[p] {
+				names[p] = true
+				policies = append(policies, p)
+			}
+		}
+		sort.Strings(policies)
+		merged.policies = policies
+	}
+	return merged
+}
+
+func authorizationPolicyAction(policy models.AuthorizationPolicy) string {
+	if action, ok := policy.Spec.Action.(string); ok && action != "" {
+		return action
+	}
+	return "ALLOW"
+}
+
+// authorizationPolicyApplies returns true if the policy selector, if any, matches the workload labels
+func authorizationPolicyApplies(policy models.AuthorizationPolicy, workloadLabels map[string]string) bool {
+	selector, ok := policy.Spec.Selector.(map[string]interface{})
+	if !ok {
+		return true
+	}
+	matchLabels, ok := selector["matchLabels"].(map[string]interface{})
+	if !ok {
+		return true
+	}
+	for k, v := range matchLabels {
+		if value, ok := v.(string); !ok || workloadLabels[k] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// authorizationRulesMatch returns true if any rule matches the source. A policy without rules matches nothing.
+// assumeUnknown is the result for conditions on attributes that can't be evaluated.
+func authorizationRulesMatch(rules interface{}, source authorizationSource, assumeUnknown bool) bool {
+	ruleList, ok := rules.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, r := range ruleList {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if authorizationRuleMatches(rule, source, assumeUnknown) {
+			return true
+		}
+	}
+	return false
+}
+
+func authorizationRuleMatches(rule map[string]interface{}, source authorizationSource, assumeUnknown bool) bool {
+	if from, ok := rule["from"].([]interface{}); ok && len(from) > 0 {
+		match := false
+		for _, f := range from {
+			if fromMap, ok := f.(map[string]interface{}); ok {
+				if sourceMap, ok := fromMap["source"].(map[string]interface{}); ok && sourceMatches(sourceMap, source, assumeUnknown) {
+					match = true
+					break
+				}
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	// operations can't be evaluated for observed traffic
+	if to, ok := rule["to"].([]interface{}); ok && len(to) > 0 && !assumeUnknown {
+		return false
+	}
+	if when, ok := rule["when"].([]interface{}); ok {
+		for _, w := range when {
+			if condition, ok := w.(map[string]interface{}); ok && !conditionMatches(condition, source, assumeUnknown) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sourceMatches(sourceMap map[string]interface{}, source authorizationSource, assumeUnknown bool) bool {
+	for field, values := range sourceMap {
+		switch field {
+		case "principals":
+			if !stringsMatch(source.principal, values) {
+				return false
+			}
+		case "notPrincipals":
+			if stringsMatch(source.principal, values) {
+				return false
+			}
+		case "namespaces":
+			if !stringsMatch(source.namespace, values) {
+				return false
+			}
+		case "notNamespaces":
+			if stringsMatch(source.namespace, values) {
+				return false
+			}
+		default:
+			if !assumeUnknown {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func conditionMatches(condition map[string]interface{}, source authorizationSource, assumeUnknown bool) bool {
+	var value string
+	switch condition["key"] {
+	case "source.principal":
+		value = source.principal
+	case "source.namespace":
+		value = source.namespace
+	default:
+		return assumeUnknown
+	}
+	if values, ok := condition["values"]; ok && !stringsMatch(value, values) {
+		return false
+	}
+	if notValues, ok := condition["notValues"]; ok && stringsMatch(value, notValues) {
+		return false
+	}
+	return true
+}
+
+// stringsMatch returns true if value matches any of the patterns. Istio supports exact, prefix ("abc*"),
+// suffix ("*abc") and presence ("*") matches. An empty value (i.e. plaintext source) matches nothing.
+func stringsMatch(value string, patterns interface{}) bool {
+	if value == "" {
+		return false
+	}
+	patternList, ok := patterns.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, p := range patternList {
+		pattern, ok := p.(string)
+		if !ok {
+			continue
+		}
+		switch {
+		case pattern == "*":
+			return true
+		case strings.HasPrefix(pattern, "*"):
+			if strings.HasSuffix(value, strings.TrimPrefix(pattern, "*")) {
+				return true
+			}
+		case strings.HasSuffix(pattern, "*"):
+			if strings.HasPrefix(value, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		case pattern == value:
+			return true
+		}
+	}
+	return false
+}
+
+func getAuthorizationPolicies(namespace string, gi *graph.AppenderGlobalInfo) models.AuthorizationPolicies {
+	gi.Lock()
+	defer gi.Unlock()
+
+	var authorizationPoliciesMap map[string]models.AuthorizationPolicies
+	if existingAuthorizationPoliciesMap, ok := gi.Vendor[authorizationPoliciesKey]; ok {
+		authorizationPoliciesMap = existingAuthorizationPoliciesMap.(map[string]models.AuthorizationPolicies)
+	} else {
+		authorizationPoliciesMap = make(map[string]models.AuthorizationPolicies)
+		gi.Vendor[authorizationPoliciesKey] = authorizationPoliciesMap
+	}
+
+	if authorizationPolicies, ok := authorizationPoliciesMap[namespace]; ok {
+		return authorizationPolicies
+	}
+
+	istioCfg, err := gi.Business.IstioConfig.GetIstioConfigList(business.IstioConfigCriteria{
+		IncludeAuthorizationPolicies: true,
+		Namespace:                    namespace,
+	})
+	graph.CheckError(err)
+	authorizationPoliciesMap[namespace] = istioCfg.AuthorizationPolicies
+
+	return istioCfg.AuthorizationPolicies
+}
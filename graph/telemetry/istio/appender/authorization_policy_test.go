@@ -0,0 +1,140 @@
+package appender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/models"
+)
+
+func newAuthorizationPolicy(namespace, name, action string, selector map[string]interface{}, rules []interface{}) models.AuthorizationPolicy {
+	ap := models.AuthorizationPolicy{}
+	ap.Metadata.Namespace = namespace
+	ap.Metadata.Name = name
+	if action != "" {
+		ap.Spec.Action = action
+	}
+	if selector != nil {
+		ap.Spec.Selector = map[string]interface{}{"matchLabels": selector}
+	}
+	if rules != nil {
+		ap.Spec.Rules = rules
+	}
+	return ap
+}
+
+func fromRule(sourceField string, values ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"from": []interface{}{
+			map[string]interface{}{"source": map[string]interface{}{sourceField: values}},
+		},
+	}
+}
+
+func TestNewAuthorizationSource(t *testing.T) {
+	assert := assert.New(t)
+
+	source := newAuthorizationSource("spiffe://cluster.local/ns/bookinfo/sa/bookinfo-productpage")
+	assert.Equal("cluster.local/ns/bookinfo/sa/bookinfo-productpage", source.principal)
+	assert.Equal("bookinfo", source.namespace)
+
+	assert.Equal(authorizationSource{}, newAuthorizationSource(graph.Unknown))
+	assert.Equal(authorizationSource{}, newAuthorizationSource(nil))
+}
+
+func TestStringsMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	patterns := []interface{}{"cluster.local/ns/bookinfo/*", "*/sa/sleep", "cluster.local/ns/default/sa/curl"}
+	assert.True(stringsMatch("cluster.local/ns/bookinfo/sa/reviews", patterns))
+	assert.True(stringsMatch("cluster.local/ns/foo/sa/sleep", patterns))
+	assert.True(stringsMatch("cluster.local/ns/default/sa/curl", patterns))
+	assert.False(stringsMatch("cluster.local/ns/default/sa/httpbin", patterns))
+	assert.True(stringsMatch("anything", []interface{}{"*"}))
+	assert.False(stringsMatch("", []interface{}{"*"}))
+}
+
+func TestEvaluateAuthorizationPolicies(t *testing.T) {
+	assert := assert.New(t)
+
+	productpage := newAuthorizationSource("spiffe://cluster.local/ns/bookinfo/sa/bookinfo-productpage")
+	sleep := newAuthorizationSource("spiffe://cluster.local/ns/foo/sa/sleep")
+	plaintext := newAuthorizationSource(graph.Unknown)
+	reviews := map[string]string{"app": "reviews", "version": "v1"}
+
+	// no policies
+	result := evaluateAuthorizationPolicies(productpage, reviews, models.AuthorizationPolicies{})
+	assert.Equal(graph.AuthorizationNone, result.action)
+	assert.Empty(result.policies)
+
+	policies := models.AuthorizationPolicies{
+		newAuthorizationPolicy("bookinfo", "allow-bookinfo", "", map[string]interface{}{"app": "reviews"}, []interface{}{
+			fromRule("namespaces", "bookinfo"),
+		}),
+		newAuthorizationPolicy("bookinfo", "ratings-only", "ALLOW", map[string]interface{}{"app": "ratings"}, []interface{}{
+			map[string]interface{}{},
+		}),
+	}
+	result = evaluateAuthorizationPolicies(productpage, reviews, policies)
+	assert.Equal(graph.AuthorizationAllow, result.action)
+	assert.Equal([]string{"bookinfo/allow-bookinfo"}, result.policies)
+
+	result = evaluateAuthorizationPolicies(sleep, reviews, policies)
+	assert.Equal(graph.AuthorizationDeny, result.action)
+	assert.Equal([]string{"bookinfo/allow-bookinfo"}, result.policies)
+
+	// the ALLOW policy for ratings does not apply to reviews
+	result = evaluateAuthorizationPolicies(sleep, map[string]string{"app": "details"}, policies)
+	assert.Equal(graph.AuthorizationNone, result.action)
+
+	// a mesh-wide policy denying plaintext traffic
+	policies = append(policies, newAuthorizationPolicy("istio-system", "require-mtls", "DENY", nil, []interface{}{
+		fromRule("notPrincipals", "*"),
+	}))
+	result = evaluateAuthorizationPolicies(plaintext, map[string]string{"app": "details"}, policies)
+	assert.Equal(graph.AuthorizationDeny, result.action)
+	assert.Equal([]string{"istio-system/require-mtls"}, result.policies)
+	result = evaluateAuthorizationPolicies(sleep, map[string]string{"app": "details"}, policies)
+	assert.Equal(graph.AuthorizationNone, result.action)
+
+	// operations can't be evaluated, they match for ALLOW policies but not for DENY policies
+	policies = models.AuthorizationPolicies{
+		newAuthorizationPolicy("bookinfo", "deny-delete", "DENY", nil, []interface{}{
+			map[string]interface{}{"to": []interface{}{map[string]interface{}{"operation": map[string]interface{}{"methods": []interface{}{"DELETE"}}}}},
+		}),
+		newAuthorizationPolicy("bookinfo", "allow-get", "ALLOW", nil, []interface{}{
+			map[string]interface{}{"to": []interface{}{map[string]interface{}{"operation": map[string]interface{}{"methods": []interface{}{"GET"}}}}},
+		}),
+	}
+	result = evaluateAuthorizationPolicies(sleep, reviews, policies)
+	assert.Equal(graph.AuthorizationAllow, result.action)
+	assert.Equal([]string{"bookinfo/allow-get"}, result.policies)
+
+	// when conditions
+	policies = models.AuthorizationPolicies{
+		newAuthorizationPolicy("bookinfo", "deny-foo", "DENY", nil, []interface{}{
+			map[string]interface{}{"when": []interface{}{map[string]interface{}{"key": "source.namespace", "values": []interface{}{"foo"}}}},
+		}),
+	}
+	assert.Equal(graph.AuthorizationDeny, evaluateAuthorizationPolicies(sleep, reviews, policies).action)
+	assert.Equal(graph.AuthorizationNone, evaluateAuthorizationPolicies(productpage, reviews, policies).action)
+
+	// an ALLOW policy without rules allows nothing
+	policies = models.AuthorizationPolicies{newAuthorizationPolicy("bookinfo", "allow-nothing", "ALLOW", nil, nil)}
+	assert.Equal(graph.AuthorizationDeny, evaluateAuthorizationPolicies(productpage, reviews, policies).action)
+}
+
+func TestMergeAuthorizationResults(t *testing.T) {
+	assert := assert.New(t)
+
+	allowA := authorizationResult{action: graph.AuthorizationAllow, policies: []string{"ns/b", "ns/a"}}
+	allowB := authorizationResult{action: graph.AuthorizationAllow, policies: []string{"ns/a"}}
+	none := authorizationResult{action: graph.AuthorizationNone}
+	deny := authorizationResult{action: graph.AuthorizationDeny, policies: []string{"ns/c"}}
+
+	assert.Equal(authorizationResult{action: graph.AuthorizationAllow, policies: []string{"ns/a", "ns/b"}}, mergeAuthorizationResults([]authorizationResult{allowA, allowB}))
+	assert.Equal(none, mergeAuthorizationResults([]authorizationResult{allowA, none}))
+	assert.Equal(deny, mergeAuthorizationResults([]authorizationResult{allowA, deny, none}))
+}
@@ -0,0 +1,175 @@
+package appender
+
+import (
+	"time"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/models"
+)
+
+const BoxByAppenderName = "boxBy"
+
+// BoxByAppender decorates nodes with the information required for boxing by label or by WorkloadGroup. It
+// is not requested via the appenders param, it runs whenever the boxBy param requests label or
+// workloadGroup boxing. Workload and app nodes use the labels of their backing workloads, service
+// nodes use the service labels. A node backed by workloads with different label values is not decorated.
+// Name: boxBy
+type BoxByAppender struct {
+	AccessibleNamespaces map[string]time.Time
+	Label                string // if set, decorate nodes with the value of this label
+	WorkloadGroup        bool   // if true, decorate nodes with the WorkloadGroup of their workloads
+}
+
+// Name implements Appender
+func (a BoxByAppender) Name() string {
+	return BoxByAppenderName
+}
+
+// AppendGraph implements Appender
+func (a BoxByAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	for _, n := range trafficMap {
+		// nodes are decorated once, but may be processed for each graph namespace
+		if _, ok := n.Metadata[graph.BoxLabel]; ok {
+			continue
+		}
+		if _, ok := n.Metadata[graph.WorkloadGroup]; ok {
+			continue
+		}
+		if _, ok := a.AccessibleNamespaces[n.Namespace]; !ok {
+			continue
+		}
+
+		var workloads []models.WorkloadListItem
+		var labels []map[string]string
+		switch n.NodeType {
+		case graph.NodeTypeWorkload:
+			if workload, found := getWorkload(n.Namespace, n.Workload, globalInfo); found {
+				workloads = append(workloads, *workload)
+			}
+		case graph.NodeTypeApp:
+			workloads = getAppWorkloads(n.Namespace, n.App, n.Version, globalInfo)
+		case graph.NodeTypeService:
+			if a.Label != "" {
+				if svc, found := getServiceDefinition(n.Namespace, n.Service, globalInfo); found {
+					labels = append(labels, svc.Labels)
+				}
+			}
+		default:
+			continue
+		}
+		for _, workload := range workloads {
+			labels = append(labels, workload.Labels)
+		}
+
+		if a.Label != "" {
+			if value, ok := commonLabelValue(a.Label, labels); ok {
+				n.Metadata[graph.BoxLabel] = value
+			}
+		}
+		if a.WorkloadGroup && len(workloads) > 0 {
+			if workloadGroup, ok := commonWorkloadGroup(workloads, getWorkloadGroups(n.Namespace, globalInfo)); ok {
+				n.Metadata[graph.WorkloadGroup] = workloadGroup
+			}
+		}
+	}
+}
+
+// commonLabelValue returns the label value, if every label set has the same value for the label
+func commonLabelValue(label string, labels []map[string]string) (string, bool) {
+	value := ""
+	for _, l := range labels {
+		v, ok := l[label]
+		if !ok || (value != "" && v != value) {
+			return "", false
+		}
+		value = v
+	}
+	return value, value != ""
+}
+
+// commonWorkloadGroup returns the WorkloadGroup name, if every workload belongs to the same WorkloadGroup
+func commonWorkloadGroup(workloads []models.WorkloadListItem, workloadGroups models.WorkloadGroups) (string, bool) {
+	name := ""
+	for _, workload := range workloads {
+		wgName, ok := matchWorkloadGroup(workload, workloadGroups)
+		if !ok || (name != "" && wgName != name) {
+			return "", false
+		}
+		name = wgName
+	}
+	return name, name != ""
+}
+
+// matchWorkloadGroup returns the name of the WorkloadGroup of the workload. A workload belongs to a
+// WorkloadGroup with the same name (as reported for auto-registered WorkloadEntries), or to a WorkloadGroup
+// whose metadata labels are all set on the workload.
+func matchWorkloadGroup(workload models.WorkloadListItem, workloadGroups models.WorkloadGroups) (string, bool) {
+	for _, wg := range workloadGroups {
+		if wg.Metadata.Name == workload.Name {
+			return wg.Metadata.Name, true
+		}
+	}
+	for _, wg := range workloadGroups {
+		wgLabels := workloadGroupLabels(wg)
+		if len(wgLabels) == 0 {
+			continue
+		}
+		match := true
+		for k, v := range wgLabels {
+			if workload.Labels[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			return wg.Metadata.Name, true
+		}
+	}
+	return "", false
+}
+
+// workloadGroupLabels returns the labels applied by the WorkloadGroup to its workload entries (spec.metadata.labels)
+func workloadGroupLabels(wg models.WorkloadGroup) map[string]string {
+	labels := make(map[string]string)
+	if metadata, ok := wg.Spec.Metadata.(map[string]interface{}); ok {
+		if metadataLabels, ok := metadata["labels"].(map[string]interface{}); ok {
+			for k, v := range metadataLabels {
+				if value, ok := v.(string); ok {
+					labels[k] = value
+				}
+			}
+		}
+	}
+	return labels
+}
+
+func getWorkloadGroups(namespace string, gi *graph.AppenderGlobalInfo) models.WorkloadGroups {
+	gi.Lock()
+	defer gi.Unlock()
+
+	var workloadGroupsMap map[string]models.WorkloadGroups
+	if existingWorkloadGroupsMap, ok := gi.Vendor[workloadGroupsKey]; ok {
+		workloadGroupsMap = existingWorkloadGroupsMap.(map[string]models.WorkloadGroups)
+	} else {
+		workloadGroupsMap = make(map[string]models.WorkloadGroups)
+		gi.Vendor[workloadGroupsKey] = workloadGroupsMap
+	}
+
+	if workloadGroups, ok := workloadGroupsMap[namespace]; ok {
+		return workloadGroups
+	}
+
+	istioCfg, err := gi.Business.IstioConfig.GetIstioConfigList(business.IstioConfigCriteria{
+		IncludeWorkloadGroups: true,
+		Namespace:             namespace,
+	})
+	graph.CheckError(err)
+	workloadGroupsMap[namespace] = istioCfg.WorkloadGroups
+
+	return istioCfg.WorkloadGroups
+}
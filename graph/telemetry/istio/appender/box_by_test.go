@@ -0,0 +1,68 @@
+package appender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/models"
+)
+
+func TestCommonLabelValue(t *testing.T) {
+	assert := assert.New(t)
+
+	value, ok := commonLabelValue("team", []map[string]string{{"team": "payments"}, {"team": "payments", "tier": "db"}})
+	assert.True(ok)
+	assert.Equal("payments", value)
+
+	_, ok = commonLabelValue("team", []map[string]string{{"team": "payments"}, {"team": "search"}})
+	assert.False(ok)
+	_, ok = commonLabelValue("team", []map[string]string{{"team": "payments"}, {"tier": "db"}})
+	assert.False(ok)
+	_, ok = commonLabelValue("team", []map[string]string{})
+	assert.False(ok)
+}
+
+func TestMatchWorkloadGroup(t *testing.T) {
+	assert := assert.New(t)
+
+	newWorkloadGroup := func(name string, labels map[string]interface{}) models.WorkloadGroup {
+		wg := models.WorkloadGroup{}
+		wg.Metadata.Name = name
+		if labels != nil {
+			wg.Spec.Metadata = map[string]interface{}{"labels": labels}
+		}
+		return wg
+	}
+	workloadGroups := models.WorkloadGroups{
+		newWorkloadGroup("ratings-vm", nil),
+		newWorkloadGroup("reviews-vm", map[string]interface{}{"app": "reviews", "class": "vm"}),
+	}
+	newWorkload := func(name string, labels map[string]string) models.WorkloadListItem {
+		return models.WorkloadListItem{Name: name, Labels: labels}
+	}
+
+	name, ok := matchWorkloadGroup(newWorkload("ratings-vm", nil), workloadGroups)
+	assert.True(ok)
+	assert.Equal("ratings-vm", name)
+
+	name, ok = matchWorkloadGroup(newWorkload("reviews-v1", map[string]string{"app": "reviews", "class": "vm", "version": "v1"}), workloadGroups)
+	assert.True(ok)
+	assert.Equal("reviews-vm", name)
+
+	_, ok = matchWorkloadGroup(newWorkload("reviews-v2", map[string]string{"app": "reviews", "version": "v2"}), workloadGroups)
+	assert.False(ok)
+
+	// all of the workloads must be in the same group
+	_, ok = commonWorkloadGroup([]models.WorkloadListItem{
+		newWorkload("ratings-vm", nil),
+		newWorkload("reviews-v1", map[string]string{"app": "reviews", "class": "vm"}),
+	}, workloadGroups)
+	assert.False(ok)
+	name, ok = commonWorkloadGroup([]models.WorkloadListItem{
+		newWorkload("reviews-v1", map[string]string{"app": "reviews", "class": "vm"}),
+		newWorkload("reviews-v2", map[string]string{"app": "reviews", "class": "vm"}),
+	}, workloadGroups)
+	assert.True(ok)
+	assert.Equal("reviews-vm", name)
+}
@@ -1,7 +1,6 @@
 package appender
 
 import (
-	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/log"
 )
@@ -29,14 +28,7 @@ func (a DeadNodeAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *g
 		return
 	}
 
-	if globalInfo.HomeCluster == "" {
-		globalInfo.HomeCluster = business.DefaultClusterID
-		c, err := globalInfo.Business.Mesh.ResolveKialiControlPlaneCluster(nil)
-		graph.CheckError(err)
-		if c != nil {
-			globalInfo.HomeCluster = c.Name
-		}
-	}
+	resolveHomeCluster(globalInfo)
 
 	// Apply dead node removal iteratively until no dead nodes are found.  Removal of dead nodes may
 	// alter the graph such that new nodes qualify for dead-ness by being orphaned, lack required
@@ -0,0 +1,237 @@
+package appender
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/util/httputil"
+)
+
+const defaultExternalAppenderTimeout = 5 // seconds
+
+// ExternalAppender is responsible for adding the data of an external appender, an HTTP webhook registered in
+// the graph.external_appenders config, to the graph. For each namespace the webhook receives the traffic map
+// (see externalRequest) and returns the data of the nodes and edges to decorate (see externalResponse). The
+// data is added to the node and edge metadata, under the appender name. A webhook failure is logged and the
+// graph is generated without the data. External appenders only run when requested by name, they are not part
+// of the default appenders.
+// Name: <the configured name>
+type ExternalAppender struct {
+	Config     config.GraphExternalAppender
+	GraphType  string
+	Namespaces graph.NamespaceInfoMap
+	QueryTime  int64 // unix time in seconds
+}
+
+// externalRequest is the body POSTed to the webhook
+type externalRequest struct {
+	Duration  int64          `json:"duration"` // seconds
+	Edges     []externalEdge `json:"edges"`
+	GraphType string         `json:"graphType"`
+	Namespace string         `json:"namespace"`
+	Nodes     []externalNode `json:"nodes"`
+	QueryTime int64          `json:"queryTime"` // unix time in seconds
+}
+
+type externalNode struct {
+	ID        string         `json:"id"`
+	NodeType  string         `json:"nodeType"`
+	Cluster   string         `json:"cluster"`
+	Namespace string         `json:"namespace"`
+	Workload  string         `json:"workload,omitempty"`
+	App       string         `json:"app,omitempty"`
+	Version   string         `json:"version,omitempty"`
+	Service   string         `json:"service,omitempty"`
+	Metadata  graph.Metadata `json:"metadata"`
+}
+
+type externalEdge struct {
+	Source   string         `json:"source"`
+	Dest     string         `json:"dest"`
+	Protocol string         `json:"protocol"`
+	Metadata graph.Metadata `json:"metadata"`
+}
+
+// externalResponse is the body returned by the webhook, nodes are keyed by ID and edges are identified by
+// source, dest and protocol. Unknown nodes and edges are ignored.
+type externalResponse struct {
+	Edges []struct {
+		Source   string      `json:"source"`
+		Dest     string      `json:"dest"`
+		Protocol string      `json:"protocol"`
+		Data     interface{} `json:"data"`
+	} `json:"edges"`
+	Nodes map[string]interface{} `json:"nodes"`
+}
+
+// builtInAppenderNames holds the names that can't be given to an external appender
+var builtInAppenderNames = map[string]bool{
+	AggregateNodeAppenderName:           true,
+	AnomalyAppenderName:                 true,
+	AuthorizationPolicyAppenderName:     true,
+	BoxByAppenderName:                   true,
+	DeadNodeAppenderName:                true,
+	HealthConfigAppenderName:            true,
+	IdleNodeAppenderName:                true,
+	IstioAppenderName:                   true,
+	RequestSizeAppenderName:             true,
+	ResponseSizeAppenderName:            true,
+	ResponseTimeAppenderName:            true,
+	ResponseTimePercentilesAppenderName: true,
+	RootCauseAppenderName:               true,
+	SecurityPolicyAppenderName:          true,
+	ServiceEntryAppenderName:            true,
+	SidecarsCheckAppenderName:           true,
+	ThroughputAppenderName:              true,
+}
+
+// IsExternalAppender returns true if name is the name of a registered external appender. An external appender
+// named after a built-in appender is rejected.
+func IsExternalAppender(name string) bool {
+	for _, c := range getExternalAppenderConfigs() {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getExternalAppenderConfigs returns the configs of the registered external appenders, skipping the ones named
+// after a built-in appender
+func getExternalAppenderConfigs() []config.GraphExternalAppender {
+	configs := []config.GraphExternalAppender{}
+	for _, c := range config.Get().Graph.ExternalAppenders {
+		if builtInAppenderNames[c.Name] {
+			log.Errorf("External appender [%s] is ignored, its name is the name of a built-in appender", c.Name)
+			continue
+		}
+		configs = append(configs, c)
+	}
+	return configs
+}
+
+// Name implements Appender
+func (a ExternalAppender) Name() string {
+	return a.Config.Name
+}
+
+// AppendGraph implements Appender
+func (a ExternalAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	response, err := a.call(a.newRequest(trafficMap, namespaceInfo.Namespace))
+	if err != nil {
+		log.Warningf("External appender [%s] failed for namespace [%s], ignoring its data: %v", a.Config.Name, namespaceInfo.Namespace, err)
+		return
+	}
+	a.merge(trafficMap, response)
+}
+
+func (a ExternalAppender) newRequest(trafficMap graph.TrafficMap, namespace string) externalRequest {
+	request := externalRequest{
+		Edges:     []externalEdge{},
+		GraphType: a.GraphType,
+		Namespace: namespace,
+		Nodes:     []externalNode{},
+		QueryTime: a.QueryTime,
+	}
+	if namespaceInfo, ok := a.Namespaces[namespace]; ok {
+		request.Duration = int64(namespaceInfo.Duration.Seconds())
+	}
+
+	ids := make([]string, 0, len(trafficMap))
+	for id := range trafficMap {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		n := trafficMap[id]
+		request.Nodes = append(request.Nodes, externalNode{
+			ID:        n.ID,
+			NodeType:  n.NodeType,
+			Cluster:   n.Cluster,
+			Namespace: n.Namespace,
+			Workload:  n.Workload,
+			App:       n.App,
+			Version:   n.Version,
+			Service:   n.Service,
+			Metadata:  n.Metadata,
+		})
+		for _, e := range n.Edges {
+			protocol, _ := e.Metadata[graph.ProtocolKey].(string)
+			request.Edges = append(request.Edges, externalEdge{
+				Source:   e.Source.ID,
+				Dest:     e.Dest.ID,
+				Protocol: protocol,
+				Metadata: e.Metadata,
+			})
+		}
+	}
+	return request
+}
+
+func (a ExternalAppender) call(request externalRequest) (*externalResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := a.Config.Timeout
+	if timeout <= 0 {
+		timeout = defaultExternalAppenderTimeout
+	}
+	auth := a.Config.Auth
+	resp, code, err := httputil.HttpPost(a.Config.URL, &auth, body, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response code [%d]", code)
+	}
+
+	response := &externalResponse{}
+	if err := json.Unmarshal(resp, response); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	return response, nil
+}
+
+func (a ExternalAppender) merge(trafficMap graph.TrafficMap, response *externalResponse) {
+	for id, data := range response.Nodes {
+		if n, ok := trafficMap[id]; ok {
+			addExternalMetadata(n.Metadata, a.Config.Name, data)
+		}
+	}
+	for _, edgeData := range response.Edges {
+		n, ok := trafficMap[edgeData.Source]
+		if !ok {
+			continue
+		}
+		for _, e := range n.Edges {
+			if protocol, _ := e.Metadata[graph.ProtocolKey].(string); e.Dest.ID == edgeData.Dest && protocol == edgeData.Protocol {
+				addExternalMetadata(e.Metadata, a.Config.Name, edgeData.Data)
+			}
+		}
+	}
+}
+
+func addExternalMetadata(metadata graph.Metadata, name string, data interface{}) {
+	if data == nil {
+		return
+	}
+	external, ok := metadata[graph.External].(graph.ExternalMetadata)
+	if !ok {
+		external = graph.ExternalMetadata{}
+		metadata[graph.External] = external
+	}
+	external[name] = data
+}
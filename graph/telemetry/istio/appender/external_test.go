@@ -0,0 +1,126 @@
+package appender
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+)
+
+func externalTestTraffic() (graph.TrafficMap, *graph.Node, *graph.Node) {
+	trafficMap := graph.NewTrafficMap()
+	source := graph.NewNode(graph.Unknown, "", "", "testNamespace", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	dest := graph.NewNode(graph.Unknown, "", "", "testNamespace", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	trafficMap[source.ID] = &source
+	trafficMap[dest.ID] = &dest
+	e := source.AddEdge(&dest)
+	e.Metadata[graph.ProtocolKey] = "http"
+	return trafficMap, &source, &dest
+}
+
+func TestExternalAppender(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap, source, dest := externalTestTraffic()
+
+	var request externalRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(http.MethodPost, r.Method)
+		assert.NoError(json.NewDecoder(r.Body).Decode(&request))
+		_, _ = w.Write([]byte(`{
+			"nodes": {"` + dest.ID + `": {"team": "reviewers"}, "unknown": {"team": "nobody"}},
+			"edges": [
+				{"source": "` + source.ID + `", "dest": "` + dest.ID + `", "protocol": "http", "data": {"cost": 0.5}},
+				{"source": "` + source.ID + `", "dest": "` + dest.ID + `", "protocol": "tcp", "data": {"cost": 1}}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	a := ExternalAppender{
+		Config:     config.GraphExternalAppender{Name: "owners", URL: server.URL},
+		GraphType:  graph.GraphTypeVersionedApp,
+		Namespaces: graph.NamespaceInfoMap{"testNamespace": graph.NamespaceInfo{Name: "testNamespace", Duration: time.Minute}},
+		QueryTime:  1000,
+	}
+	a.AppendGraph(trafficMap, graph.NewAppenderGlobalInfo(), graph.NewAppenderNamespaceInfo("testNamespace"))
+
+	assert.Equal("testNamespace", request.Namespace)
+	assert.Equal(int64(60), request.Duration)
+	assert.Equal(int64(1000), request.QueryTime)
+	assert.Equal(2, len(request.Nodes))
+	assert.Equal(1, len(request.Edges))
+	assert.Equal("http", request.Edges[0].Protocol)
+
+	_, ok := source.Metadata[graph.External]
+	assert.False(ok)
+	assert.Equal(graph.ExternalMetadata{"owners": map[string]interface{}{"team": "reviewers"}}, dest.Metadata[graph.External])
+	assert.Equal(graph.ExternalMetadata{"owners": map[string]interface{}{"cost": 0.5}}, source.Edges[0].Metadata[graph.External])
+}
+
+func TestExternalAppenderFailure(t *testing.T) {
+	trafficMap, source, dest := externalTestTraffic()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	a := ExternalAppender{Config: config.GraphExternalAppender{Name: "owners", URL: server.URL}}
+	a.AppendGraph(trafficMap, graph.NewAppenderGlobalInfo(), graph.NewAppenderNamespaceInfo("testNamespace"))
+
+	// the graph is left unchanged
+	for _, n := range []*graph.Node{source, dest} {
+		_, ok := n.Metadata[graph.External]
+		assert.False(t, ok)
+	}
+}
+
+func TestParseExternalAppenders(t *testing.T) {
+	assert := assert.New(t)
+
+	conf := config.NewConfig()
+	conf.Graph.ExternalAppenders = []config.GraphExternalAppender{{Name: "owners"}, {Name: "cost"}}
+	config.Set(conf)
+
+	o := graph.TelemetryOptions{Appenders: graph.RequestedAppenders{AppenderNames: []string{"cost"}}}
+	appenders := ParseAppenders(o)
+	assert.Equal(1, len(appenders))
+	assert.Equal("cost", appenders[0].Name())
+
+	o.Appenders = graph.RequestedAppenders{AppenderNames: []string{"unknown"}}
+	assert.Panics(func() { ParseAppenders(o) })
+}
+
+func TestParseExternalAppendersNotDefault(t *testing.T) {
+	conf := config.NewConfig()
+	conf.Graph.ExternalAppenders = []config.GraphExternalAppender{{Name: "owners"}}
+	config.Set(conf)
+
+	o := graph.TelemetryOptions{Appenders: graph.RequestedAppenders{All: true}}
+	for _, a := range ParseAppenders(o) {
+		assert.NotEqual(t, "owners", a.Name())
+	}
+}
+
+func TestParseExternalAppendersBuiltInName(t *testing.T) {
+	assert := assert.New(t)
+
+	conf := config.NewConfig()
+	conf.Graph.ExternalAppenders = []config.GraphExternalAppender{{Name: IstioAppenderName}, {Name: "owners"}}
+	config.Set(conf)
+
+	assert.False(IsExternalAppender(IstioAppenderName))
+	assert.True(IsExternalAppender("owners"))
+
+	o := graph.TelemetryOptions{Appenders: graph.RequestedAppenders{AppenderNames: []string{IstioAppenderName}}}
+	appenders := ParseAppenders(o)
+	assert.Equal(1, len(appenders))
+	assert.IsType(IstioAppender{}, appenders[0])
+}
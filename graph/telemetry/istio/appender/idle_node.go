@@ -1,7 +1,6 @@
 package appender
 
 import (
-	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/log"
@@ -32,14 +31,7 @@ func (a IdleNodeAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *g
 
 	services := []models.ServiceDetails{}
 	workloads := []models.WorkloadListItem{}
-	if globalInfo.HomeCluster == "" {
-		globalInfo.HomeCluster = business.DefaultClusterID
-		c, err := globalInfo.Business.Mesh.ResolveKialiControlPlaneCluster(nil)
-		graph.CheckError(err)
-		if c != nil {
-			globalInfo.HomeCluster = c.Name
-		}
-	}
+	resolveHomeCluster(globalInfo)
 
 	if a.GraphType != graph.GraphTypeService {
 		workloads = getWorkloadList(namespaceInfo.Namespace, globalInfo).Workloads
@@ -0,0 +1,240 @@
+package appender
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry/istio/util"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+)
+
+const (
+	// RequestSizeAppenderName uniquely identifies the appender: requestSize
+	RequestSizeAppenderName = "requestSize"
+	// ResponseSizeAppenderName uniquely identifies the appender: responseSize
+	ResponseSizeAppenderName = "responseSize"
+	// ResponseTimePercentilesAppenderName uniquely identifies the appender: responseTimePercentiles
+	ResponseTimePercentilesAppenderName = "responseTimePercentiles"
+)
+
+// percentiles reported by the PercentilesAppender, keyed by the name used in graph.PercentilesMetadata
+var percentiles = []struct {
+	name     string
+	quantile float64
+}{
+	{name: "p50", quantile: 0.50},
+	{name: "p95", quantile: 0.95},
+	{name: "p99", quantile: 0.99},
+}
+
+// PercentilesAppender is responsible for adding the p50, p95 and p99 values of an Istio histogram metric
+// to the graph edges. It backs three appenders:
+//   requestSize:             istio_request_bytes, in bytes
+//   responseSize:            istio_response_bytes, in bytes
+//   responseTimePercentiles: istio_request_duration_milliseconds, in millis
+// Like ResponseTimeAppender, values are reported using destination proxy telemetry, when available.
+// Name: requestSize | responseSize | responseTimePercentiles
+type PercentilesAppender struct {
+	AppenderName       string
+	GraphType          string
+	InjectServiceNodes bool
+	MetadataKey        graph.MetadataKey
+	Metric             string // the histogram metric name, without the _bucket suffix
+	Namespaces         graph.NamespaceInfoMap
+	QueryTime          int64 // unix time in seconds
+	Rates              graph.RequestedRates
+}
+
+// NewPercentilesAppender returns the PercentilesAppender for the given appender name
+func NewPercentilesAppender(name string, o graph.TelemetryOptions) PercentilesAppender {
+	a := PercentilesAppender{
+		AppenderName:       name,
+		GraphType:          o.GraphType,
+		InjectServiceNodes: o.InjectServiceNodes,
+		Namespaces:         o.Namespaces,
+		QueryTime:          o.QueryTime,
+		Rates:              o.Rates,
+	}
+	switch name {
+	case RequestSizeAppenderName:
+		a.MetadataKey = graph.RequestSize
+		a.Metric = "istio_request_bytes"
+	case ResponseSizeAppenderName:
+		a.MetadataKey = graph.ResponseSize
+		a.Metric = "istio_response_bytes"
+	default:
+		a.AppenderName = ResponseTimePercentilesAppenderName
+		a.MetadataKey = graph.ResponseTimePercentiles
+		a.Metric = "istio_request_duration_milliseconds"
+	}
+	return a
+}
+
+// Name implements Appender
+func (a PercentilesAppender) Name() string {
+	return a.AppenderName
+}
+
+// AppendGraph implements Appender
+func (a PercentilesAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	// The histograms only apply to request traffic (not TCP or gRPC-message traffic)
+	if a.Rates.Grpc != graph.RateRequests && a.Rates.Http != graph.RateRequests {
+		return
+	}
+
+	if globalInfo.PromClient == nil {
+		var err error
+		globalInfo.PromClient, err = prometheus.NewClient()
+		graph.CheckError(err)
+	}
+
+	a.appendGraph(trafficMap, namespaceInfo.Namespace, globalInfo.PromClient)
+}
+
+func (a PercentilesAppender) appendGraph(trafficMap graph.TrafficMap, namespace string, client *prometheus.Client) {
+	log.Tracef("Generating %s percentiles; namespace = %v", a.Metric, namespace)
+
+	// create map to quickly look up percentiles
+	percentilesMap := make(map[string]graph.PercentilesMetadata)
+	duration := a.Namespaces[namespace].Duration
+	groupBy := "le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol"
+
+	for _, p := range percentiles {
+		// query prometheus for the percentile in two queries:
+		// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
+		// note - the query order is important as both queries may have overlapping results for edges within
+		//        the namespace.  This query uses destination proxy and so must come first.
+		query := fmt.Sprintf(`histogram_quantile(%.2f, sum(rate(%s_bucket{reporter="destination",destination_service_namespace="%s"}[%vs])) by (%s)) > 0`,
+			p.quantile,
+			a.Metric,
+			namespace,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		incomingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		a.populatePercentilesMap(percentilesMap, p.name, &incomingVector)
+
+		// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
+		query = fmt.Sprintf(`histogram_quantile(%.2f, sum(rate(%s_bucket{reporter="source",source_workload_namespace="%s"}[%vs])) by (%s)) > 0`,
+			p.quantile,
+			a.Metric,
+			namespace,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		outgoingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		a.populatePercentilesMap(percentilesMap, p.name, &outgoingVector)
+	}
+
+	a.applyPercentiles(trafficMap, percentilesMap)
+}
+
+func (a PercentilesAppender) applyPercentiles(trafficMap graph.TrafficMap, percentilesMap map[string]graph.PercentilesMetadata) {
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			key := fmt.Sprintf("%s %s %s", e.Source.ID, e.Dest.ID, e.Metadata[graph.ProtocolKey].(string))
+			if val, ok := percentilesMap[key]; ok {
+				e.Metadata[a.MetadataKey] = val
+			}
+		}
+	}
+}
+
+func (a PercentilesAppender) populatePercentilesMap(percentilesMap map[string]graph.PercentilesMetadata, percentile string, vector *model.Vector) {
+	skipRequestsGrpc := a.Rates.Grpc != graph.RateRequests
+	skipRequestsHttp := a.Rates.Http != graph.RateRequests
+
+	for _, s := range *vector {
+		m := s.Metric
+		lSourceCluster, sourceClusterOk := m["source_cluster"]
+		lSourceWlNs, sourceWlNsOk := m["source_workload_namespace"]
+		lSourceWl, sourceWlOk := m["source_workload"]
+		lSourceApp, sourceAppOk := m["source_canonical_service"]
+		lSourceVer, sourceVerOk := m["source_canonical_revision"]
+		lDestCluster, destClusterOk := m["destination_cluster"]
+		lDestSvcNs, destSvcNsOk := m["destination_service_namespace"]
+		lDestSvc, destSvcOk := m["destination_service"]
+		lDestSvcName, destSvcNameOk := m["destination_service_name"]
+		lDestWlNs, destWlNsOk := m["destination_workload_namespace"]
+		lDestWl, destWlOk := m["destination_workload"]
+		lDestApp, destAppOk := m["destination_canonical_service"]
+		lDestVer, destVerOk := m["destination_canonical_revision"]
+		lProtocol, protocolOk := m["request_protocol"]
+
+		if !sourceWlNsOk || !sourceWlOk || !sourceAppOk || !sourceVerOk || !destSvcNsOk || !destSvcNameOk || !destSvcOk || !destWlNsOk || !destWlOk || !destAppOk || !destVerOk || !protocolOk {
+			log.Warningf("populatePercentilesMap: Skipping %s, missing expected labels", m.String())
+			continue
+		}
+
+		sourceWlNs := string(lSourceWlNs)
+		sourceWl := string(lSourceWl)
+		sourceApp := string(lSourceApp)
+		sourceVer := string(lSourceVer)
+		destSvc := string(lDestSvc)
+		protocol := string(lProtocol)
+
+		if (skipRequestsHttp && protocol == graph.HTTP.Name) || (skipRequestsGrpc && protocol == graph.GRPC.Name) {
+			continue
+		}
+
+		// handle clusters
+		sourceCluster, destCluster := util.HandleClusters(lSourceCluster, sourceClusterOk, lDestCluster, destClusterOk)
+
+		if util.IsBadSourceTelemetry(sourceCluster, sourceClusterOk, sourceWlNs, sourceWl, sourceApp) {
+			continue
+		}
+
+		val := float64(s.Value)
+
+		// handle unusual destinations
+		destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, _ := util.HandleDestination(sourceCluster, sourceWlNs, sourceWl, destCluster, string(lDestSvcNs), string(lDestSvc), string(lDestSvcName), string(lDestWlNs), string(lDestWl), string(lDestApp), string(lDestVer))
+
+		if util.IsBadDestTelemetry(destCluster, destClusterOk, destSvcNs, destSvc, destSvcName, destWl) {
+			continue
+		}
+
+		// Should not happen but if NaN for any reason, Just skip it
+		if math.IsNaN(val) {
+			continue
+		}
+
+		// don't inject a service node if destSvcName is not set or the dest node is already a service node.
+		inject := false
+		if a.InjectServiceNodes && graph.IsOK(destSvcName) {
+			_, destNodeType := graph.Id(destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, a.GraphType)
+			inject = (graph.NodeTypeService != destNodeType)
+		}
+
+		if inject {
+			// Only set percentiles on the outgoing edge. On the incoming edge, we can't validly aggregate percentiles of the outgoing edges (kiali-2297)
+			a.addPercentile(percentilesMap, percentile, val, protocol, destCluster, destSvcNs, destSvcName, "", "", "", destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		} else {
+			a.addPercentile(percentilesMap, percentile, val, protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		}
+	}
+}
+
+func (a PercentilesAppender) addPercentile(percentilesMap map[string]graph.PercentilesMetadata, percentile string, val float64, protocol, sourceCluster, sourceNs, sourceSvc, sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer string) {
+	sourceID, _ := graph.Id(sourceCluster, sourceNs, sourceSvc, sourceNs, sourceWl, sourceApp, sourceVer, a.GraphType)
+	destID, _ := graph.Id(destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer, a.GraphType)
+	key := fmt.Sprintf("%s %s %s", sourceID, destID, protocol)
+
+	edgePercentiles, ok := percentilesMap[key]
+	if !ok {
+		edgePercentiles = graph.NewPercentilesMetadata()
+		percentilesMap[key] = edgePercentiles
+	}
+
+	// For edges within the namespace we may get a value reported from both the incoming and outgoing
+	// traffic queries.  We assume here the first reported value is preferred (i.e. defer to query order)
+	if _, found := edgePercentiles[percentile]; !found {
+		edgePercentiles[percentile] = val
+	}
+}
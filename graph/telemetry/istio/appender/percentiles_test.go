@@ -0,0 +1,117 @@
+package appender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+)
+
+func TestResponseTimePercentiles(t *testing.T) {
+	assert := assert.New(t)
+
+	groupBy := "le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol"
+	incomingQuery := func(quantile string) string {
+		return `round(histogram_quantile(` + quantile + `, sum(rate(istio_request_duration_milliseconds_bucket{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (` + groupBy + `)) > 0,0.001)`
+	}
+	outgoingQuery := func(quantile string) string {
+		return `round(histogram_quantile(` + quantile + `, sum(rate(istio_request_duration_milliseconds_bucket{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (` + groupBy + `)) > 0,0.001)`
+	}
+
+	ppReviews := anomalyMetric("productpage-v1", "productpage", "v1", "reviews-v1", "reviews", "v1")
+	reviewsRatings := anomalyMetric("reviews-v1", "reviews", "v1", "ratings-v1", "ratings", "v1")
+
+	incoming := map[string]model.Vector{
+		"0.50": {
+			&model.Sample{Metric: ppReviews, Value: 10.0},
+			&model.Sample{Metric: reviewsRatings, Value: 5.0}},
+		"0.95": {
+			&model.Sample{Metric: ppReviews, Value: 40.0},
+			&model.Sample{Metric: reviewsRatings, Value: 9.0}},
+		"0.99": {
+			&model.Sample{Metric: ppReviews, Value: 200.0}},
+	}
+	outgoing := map[string]model.Vector{
+		"0.50": {
+			&model.Sample{Metric: ppReviews, Value: 12.0}}, // same edge reported by incoming, should get ignored
+		"0.95": {},
+		"0.99": {
+			&model.Sample{Metric: reviewsRatings, Value: 15.0}},
+	}
+
+	client, api, err := setupMocked()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for quantile := range incoming {
+		in := incoming[quantile]
+		out := outgoing[quantile]
+		mockQuery(api, incomingQuery(quantile), &in)
+		mockQuery(api, outgoingQuery(quantile), &out)
+	}
+
+	trafficMap := anomalyTestTraffic()
+
+	duration, _ := time.ParseDuration("60s")
+	o := graph.TelemetryOptions{
+		Namespaces: map[string]graph.NamespaceInfo{
+			"bookinfo": {
+				Name:     "bookinfo",
+				Duration: duration,
+			},
+		},
+		Rates: graph.RequestedRates{
+			Grpc: graph.RateRequests,
+			Http: graph.RateRequests,
+			Tcp:  graph.RateSent,
+		},
+	}
+	o.GraphType = graph.GraphTypeVersionedApp
+	o.QueryTime = time.Now().Unix()
+	appender := NewPercentilesAppender(ResponseTimePercentilesAppenderName, o)
+	assert.Equal(ResponseTimePercentilesAppenderName, appender.Name())
+
+	appender.appendGraph(trafficMap, "bookinfo", client)
+
+	productpageID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	for _, e := range trafficMap[productpageID].Edges {
+		switch e.Dest.Version {
+		case "v1":
+			assert.Equal(graph.PercentilesMetadata{"p50": 10.0, "p95": 40.0, "p99": 200.0}, e.Metadata[graph.ResponseTimePercentiles])
+		case "v2":
+			assert.Nil(e.Metadata[graph.ResponseTimePercentiles])
+		}
+	}
+
+	reviewsID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	reviews := trafficMap[reviewsID]
+	assert.Equal(graph.PercentilesMetadata{"p50": 5.0, "p95": 9.0, "p99": 15.0}, reviews.Edges[0].Metadata[graph.ResponseTimePercentiles])
+	assert.Nil(reviews.Edges[0].Metadata[graph.RequestSize])
+	assert.Nil(reviews.Edges[0].Metadata[graph.ResponseSize])
+}
+
+func TestPercentilesAppenderMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	o := graph.TelemetryOptions{}
+
+	a := NewPercentilesAppender(RequestSizeAppenderName, o)
+	assert.Equal(RequestSizeAppenderName, a.Name())
+	assert.Equal("istio_request_bytes", a.Metric)
+	assert.Equal(graph.RequestSize, a.MetadataKey)
+
+	a = NewPercentilesAppender(ResponseSizeAppenderName, o)
+	assert.Equal(ResponseSizeAppenderName, a.Name())
+	assert.Equal("istio_response_bytes", a.Metric)
+	assert.Equal(graph.ResponseSize, a.MetadataKey)
+
+	a = NewPercentilesAppender(ResponseTimePercentilesAppenderName, o)
+	assert.Equal(ResponseTimePercentilesAppenderName, a.Name())
+	assert.Equal("istio_request_duration_milliseconds", a.Metric)
+	assert.Equal(graph.ResponseTimePercentiles, a.MetadataKey)
+}
@@ -0,0 +1,225 @@
+package appender
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+const RootCauseAppenderName = "rootCause"
+
+// rootCauseFlags maps the Envoy response flags to the failure cause they reveal, flags not listed here (e.g. DC,
+// a downstream connection termination) don't point to a cause on the edge destination side.
+var rootCauseFlags = map[string]string{
+	"UO":   graph.RootCauseCircuitBreaker,  // upstream overflow
+	"UH":   graph.RootCauseCircuitBreaker,  // no healthy upstream hosts, e.g. all ejected by outlier detection
+	"NR":   graph.RootCauseNoRoute,         // no route configured
+	"NC":   graph.RootCauseNoRoute,         // upstream cluster not found
+	"UF":   graph.RootCauseUpstreamConnect, // upstream connection failure
+	"UC":   graph.RootCauseUpstreamConnect, // upstream connection termination
+	"UR":   graph.RootCauseUpstreamConnect, // upstream remote reset
+	"URX":  graph.RootCauseUpstreamConnect, // upstream retry limit exceeded
+	"UT":   graph.RootCauseTimeout,         // upstream request timeout
+	"SI":   graph.RootCauseTimeout,         // stream idle timeout
+	"DT":   graph.RootCauseTimeout,         // max stream duration exceeded
+	"RL":   graph.RootCauseRateLimited,     // rate limited locally
+	"RLSE": graph.RootCauseRateLimited,     // rate limited, rate limit service error
+	"FI":   graph.RootCauseFaultInjected,   // fault abort injected
+	"DI":   graph.RootCauseFaultInjected,   // fault delay injected
+}
+
+// RootCauseAppender classifies the failed requests of the edges by their likely cause, using the Envoy response
+// flags of the request telemetry (see rootCauseFlags). Each cause reports the percentage of the edge requests,
+// and the Istio config of the destination services possibly responsible:
+// - circuitBreaker: the DestinationRules with a connectionPool or outlierDetection traffic policy
+// - faultInjected: the VirtualServices with fault injection
+// - noRoute: the VirtualServices routing to the services
+// - timeout: the VirtualServices with a request timeout
+// The appender fetches the config of every destination namespace, so it must be explicitly requested.
+// Name: rootCause
+type RootCauseAppender struct {
+	AccessibleNamespaces map[string]time.Time
+}
+
+// Name implements Appender
+func (a RootCauseAppender) Name() string {
+	return RootCauseAppenderName
+}
+
+// AppendGraph implements Appender
+func (a RootCauseAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			rootCauses := getEdgeRootCauses(e)
+			if len(rootCauses) == 0 {
+				continue
+			}
+			for i, rootCause := range rootCauses {
+				rootCauses[i].Config = a.getRootCauseConfig(rootCause.Cause, e.Dest, globalInfo)
+			}
+			e.Metadata[graph.RootCauses] = rootCauses
+		}
+	}
+}
+
+// getEdgeRootCauses returns the causes of the failed edge requests, the highest percentage first
+func getEdgeRootCauses(e *graph.Edge) []graph.RootCause {
+	total := 0.0
+	rates := make(map[string]float64)
+	flags := make(map[string]map[string]bool)
+	for _, protocol := range graph.Protocols {
+		responses, ok := e.Metadata[protocol.EdgeResponses].(graph.Responses)
+		if !ok {
+			continue
+		}
+		for _, detail := range responses {
+			for flagsValue, rate := range detail.Flags {
+				total += rate
+				// a request may be reported with several flags, count it once per cause
+				causes := make(map[string]bool)
+				for _, flag := range strings.Split(flagsValue, ",") {
+					flag = strings.TrimSpace(flag)
+					cause, ok := rootCauseFlags[flag]
+					if !ok {
+						continue
+					}
+					if _, ok := flags[cause]; !ok {
+						flags[cause] = make(map[string]bool)
+					}
+					flags[cause][flag] = true
+					causes[cause] = true
+				}
+				for cause := range causes {
+					rates[cause] += rate
+				}
+			}
+		}
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	rootCauses := []graph.RootCause{}
+	for cause, rate := range rates {
+		if rate <= 0 {
+			continue
+		}
+		rootCause := graph.RootCause{
+			Cause:      cause,
+			Flags:      []string{},
+			Percentage: rate / total * 100.0,
+		}
+		for flag := range flags[cause] {
+			rootCause.Flags = append(rootCause.Flags, flag)
+		}
+		sort.Strings(rootCause.Flags)
+		rootCauses = append(rootCauses, rootCause)
+	}
+	sort.Slice(rootCauses, func(i, j int) bool {
+		if rootCauses[i].Percentage != rootCauses[j].Percentage {
+			return rootCauses[i].Percentage > rootCauses[j].Percentage
+		}
+		return rootCauses[i].Cause < rootCauses[j].Cause
+	})
+	return rootCauses
+}
+
+// getRootCauseConfig returns the config of the dest services possibly responsible for the cause, as
+// objectType:namespace/name (e.g. destinationrules:bookinfo/reviews)
+func (a RootCauseAppender) getRootCauseConfig(cause string, dest *graph.Node, globalInfo *graph.AppenderGlobalInfo) []string {
+	configs := make(map[string]bool)
+	for _, service := range getRootCauseServices(dest) {
+		if _, ok := a.AccessibleNamespaces[service.Namespace]; !ok {
+			continue
+		}
+		istioCfg := getRootCauseIstioConfig(service.Namespace, globalInfo)
+		switch cause {
+		case graph.RootCauseCircuitBreaker:
+			version := ""
+			if dest.NodeType != graph.NodeTypeService && graph.IsOKVersion(dest.Version) {
+				version = dest.Version
+			}
+			for _, dr := range istioCfg.DestinationRules.Items {
+				if dr.HasCircuitBreaker(service.Namespace, service.Name, version) {
+					configs[rootCauseConfigName(kubernetes.DestinationRules, dr.Metadata.Namespace, dr.Metadata.Name)] = true
+				}
+			}
+		case graph.RootCauseFaultInjected, graph.RootCauseNoRoute, graph.RootCauseTimeout:
+			for _, vs := range istioCfg.VirtualServices.Items {
+				if !vs.IsValidHost(service.Namespace, service.Name) {
+					continue
+				}
+				if (cause == graph.RootCauseFaultInjected && !vs.HasFaultInjection()) || (cause == graph.RootCauseTimeout && !vs.HasRequestTimeout()) {
+					continue
+				}
+				configs[rootCauseConfigName(kubernetes.VirtualServices, vs.Metadata.Namespace, vs.Metadata.Name)] = true
+			}
+		}
+	}
+
+	result := []string{}
+	for c := range configs {
+		result = append(result, c)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// getRootCauseServices returns the services of a dest node, the node service or the services reported
+// in its destServices
+func getRootCauseServices(dest *graph.Node) []graph.ServiceName {
+	if dest.NodeType == graph.NodeTypeService {
+		if dest.Service == "" || dest.Service == graph.Unknown {
+			return nil
+		}
+		return []graph.ServiceName{{Cluster: dest.Cluster, Namespace: dest.Namespace, Name: dest.Service}}
+	}
+	services := []graph.ServiceName{}
+	if destServices, ok := dest.Metadata[graph.DestServices].(graph.DestServicesMetadata); ok {
+		for _, ds := range destServices {
+			services = append(services, ds)
+		}
+	}
+	return services
+}
+
+func rootCauseConfigName(objectType, namespace, name string) string {
+	return fmt.Sprintf("%s:%s/%s", objectType, namespace, name)
+}
+
+func getRootCauseIstioConfig(namespace string, gi *graph.AppenderGlobalInfo) models.IstioConfigList {
+	gi.Lock()
+	defer gi.Unlock()
+
+	var istioConfigMap map[string]models.IstioConfigList
+	if existingIstioConfigMap, ok := gi.Vendor[rootCauseIstioConfigKey]; ok {
+		istioConfigMap = existingIstioConfigMap.(map[string]models.IstioConfigList)
+	} else {
+		istioConfigMap = make(map[string]models.IstioConfigList)
+		gi.Vendor[rootCauseIstioConfigKey] = istioConfigMap
+	}
+
+	if istioCfg, ok := istioConfigMap[namespace]; ok {
+		return istioCfg
+	}
+
+	istioCfg, err := gi.Business.IstioConfig.GetIstioConfigList(business.IstioConfigCriteria{
+		IncludeDestinationRules: true,
+		IncludeVirtualServices:  true,
+		Namespace:               namespace,
+	})
+	graph.CheckError(err)
+	istioConfigMap[namespace] = istioCfg
+
+	return istioCfg
+}
@@ -0,0 +1,111 @@
+package appender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/models"
+)
+
+func rootCauseTestTraffic() (graph.TrafficMap, *graph.Edge) {
+	trafficMap := graph.NewTrafficMap()
+	source := graph.NewNode(graph.Unknown, "", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	dest := graph.NewNode(graph.Unknown, "bookinfo", "reviews", "", "", "", "", graph.GraphTypeVersionedApp)
+	trafficMap[source.ID] = &source
+	trafficMap[dest.ID] = &dest
+
+	e := source.AddEdge(&dest)
+	e.Metadata[graph.ProtocolKey] = "http"
+	add := func(val float64, code, flags string) {
+		graph.AddToMetadata("http", val, code, flags, "reviews.bookinfo.svc.cluster.local", source.Metadata, dest.Metadata, e.Metadata)
+	}
+	add(60.0, "200", "-")
+	add(20.0, "503", "UO")
+	add(10.0, "503", "UF,URX")
+	add(5.0, "504", "UT")
+	add(5.0, "0", "DC")
+	return trafficMap, e
+}
+
+func TestGetEdgeRootCauses(t *testing.T) {
+	assert := assert.New(t)
+
+	_, e := rootCauseTestTraffic()
+	rootCauses := getEdgeRootCauses(e)
+
+	assert.Equal([]graph.RootCause{
+		{Cause: graph.RootCauseCircuitBreaker, Flags: []string{"UO"}, Percentage: 20.0},
+		{Cause: graph.RootCauseUpstreamConnect, Flags: []string{"UF", "URX"}, Percentage: 10.0},
+		{Cause: graph.RootCauseTimeout, Flags: []string{"UT"}, Percentage: 5.0},
+	}, rootCauses)
+
+	// no failure flags
+	trafficMap := graph.NewTrafficMap()
+	source := graph.NewNode(graph.Unknown, "", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	trafficMap[source.ID] = &source
+	e = source.AddEdge(&source)
+	graph.AddToMetadata("tcp", 10.0, "", "DC", "", source.Metadata, source.Metadata, e.Metadata)
+	assert.Empty(getEdgeRootCauses(e))
+}
+
+func TestRootCauseAppender(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap, e := rootCauseTestTraffic()
+
+	cbRule := models.DestinationRule{}
+	cbRule.Metadata = meta_v1.ObjectMeta{Namespace: "bookinfo", Name: "reviews-cb"}
+	cbRule.Spec.Host = "reviews"
+	cbRule.Spec.TrafficPolicy = map[string]interface{}{"outlierDetection": map[string]interface{}{"consecutive5xxErrors": 1}}
+	otherRule := models.DestinationRule{}
+	otherRule.Metadata = meta_v1.ObjectMeta{Namespace: "bookinfo", Name: "ratings"}
+	otherRule.Spec.Host = "ratings"
+	otherRule.Spec.TrafficPolicy = map[string]interface{}{"connectionPool": map[string]interface{}{}}
+
+	route := func(extra map[string]interface{}) []interface{} {
+		httpRoute := map[string]interface{}{
+			"route": []interface{}{map[string]interface{}{"destination": map[string]interface{}{"host": "reviews"}}},
+		}
+		for k, v := range extra {
+			httpRoute[k] = v
+		}
+		return []interface{}{httpRoute}
+	}
+	timeoutVS := models.VirtualService{}
+	timeoutVS.Metadata = meta_v1.ObjectMeta{Namespace: "bookinfo", Name: "reviews-timeout"}
+	timeoutVS.Spec.Http = route(map[string]interface{}{"timeout": "1s"})
+	routingVS := models.VirtualService{}
+	routingVS.Metadata = meta_v1.ObjectMeta{Namespace: "bookinfo", Name: "reviews-routing"}
+	routingVS.Spec.Http = route(nil)
+
+	// avoid the config fetch, use the cached config
+	globalInfo := graph.NewAppenderGlobalInfo()
+	istioCfg := models.IstioConfigList{}
+	istioCfg.DestinationRules.Items = []models.DestinationRule{cbRule, otherRule}
+	istioCfg.VirtualServices.Items = []models.VirtualService{timeoutVS, routingVS}
+	globalInfo.Vendor[rootCauseIstioConfigKey] = map[string]models.IstioConfigList{"bookinfo": istioCfg}
+
+	a := RootCauseAppender{AccessibleNamespaces: map[string]time.Time{"bookinfo": time.Now()}}
+	a.AppendGraph(trafficMap, globalInfo, graph.NewAppenderNamespaceInfo("bookinfo"))
+
+	rootCauses, ok := e.Metadata[graph.RootCauses].([]graph.RootCause)
+	assert.True(ok)
+	assert.Equal(3, len(rootCauses))
+	assert.Equal([]string{"destinationrules:bookinfo/reviews-cb"}, rootCauses[0].Config)
+	assert.Equal([]string{}, rootCauses[1].Config)
+	assert.Equal([]string{"virtualservices:bookinfo/reviews-timeout"}, rootCauses[2].Config)
+
+	// no config for inaccessible namespaces
+	trafficMap, e = rootCauseTestTraffic()
+	a = RootCauseAppender{AccessibleNamespaces: map[string]time.Time{}}
+	a.AppendGraph(trafficMap, globalInfo, graph.NewAppenderNamespaceInfo("bookinfo"))
+	for _, rootCause := range e.Metadata[graph.RootCauses].([]graph.RootCause) {
+		assert.Empty(rootCause.Config)
+	}
+}
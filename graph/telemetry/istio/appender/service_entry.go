@@ -54,14 +54,7 @@ func (a ServiceEntryAppender) AppendGraph(trafficMap graph.TrafficMap, globalInf
 		return
 	}
 
-	if globalInfo.HomeCluster == "" {
-		globalInfo.HomeCluster = business.DefaultClusterID
-		c, err := globalInfo.Business.Mesh.ResolveKialiControlPlaneCluster(nil)
-		graph.CheckError(err)
-		if c != nil {
-			globalInfo.HomeCluster = c.Name
-		}
-	}
+	resolveHomeCluster(globalInfo)
 
 	a.applyServiceEntries(trafficMap, globalInfo, namespaceInfo)
 }
@@ -152,35 +145,7 @@ func (a ServiceEntryAppender) applyServiceEntries(trafficMap graph.TrafficMap, g
 // but exported to all namespaces (exportTo: *). It's possible that would allow traffic to flow from an
 // accessible workload through a serviceEntry whose definition we can't fetch.
 func (a ServiceEntryAppender) getServiceEntry(namespace, serviceName string, globalInfo *graph.AppenderGlobalInfo) (*serviceEntry, bool) {
-	serviceEntryHosts, found := getServiceEntryHosts(globalInfo)
-	if !found {
-		for ns := range a.AccessibleNamespaces {
-			istioCfg, err := globalInfo.Business.IstioConfig.GetIstioConfigList(business.IstioConfigCriteria{
-				IncludeServiceEntries: true,
-				Namespace:             ns,
-			})
-			graph.CheckError(err)
-
-			for _, entry := range istioCfg.ServiceEntries {
-				if entry.Spec.Hosts != nil {
-					location := "MESH_EXTERNAL"
-					if entry.Spec.Location == "MESH_INTERNAL" {
-						location = "MESH_INTERNAL"
-					}
-					se := serviceEntry{
-						exportTo:  entry.Spec.ExportTo,
-						location:  location,
-						name:      entry.Metadata.Name,
-						namespace: entry.Metadata.Namespace,
-					}
-					for _, host := range entry.Spec.Hosts.([]interface{}) {
-						serviceEntryHosts.addHost(host.(string), &se)
-					}
-				}
-			}
-		}
-		globalInfo.Vendor[serviceEntryHostsKey] = serviceEntryHosts
-	}
+	serviceEntryHosts := a.loadServiceEntryHosts(globalInfo)
 
 	for host, serviceEntriesForHost := range serviceEntryHosts {
 		for _, se := range serviceEntriesForHost {
@@ -217,6 +182,44 @@ func (a ServiceEntryAppender) getServiceEntry(namespace, serviceName string, glo
 	return nil, false
 }
 
+// loadServiceEntryHosts returns the service entry hosts of the accessible namespaces, resolving them once per graph
+func (a ServiceEntryAppender) loadServiceEntryHosts(globalInfo *graph.AppenderGlobalInfo) serviceEntryHosts {
+	globalInfo.Lock()
+	defer globalInfo.Unlock()
+
+	serviceEntryHosts, found := getServiceEntryHosts(globalInfo)
+	if !found {
+		for ns := range a.AccessibleNamespaces {
+			istioCfg, err := globalInfo.Business.IstioConfig.GetIstioConfigList(business.IstioConfigCriteria{
+				IncludeServiceEntries: true,
+				Namespace:             ns,
+			})
+			graph.CheckError(err)
+
+			for _, entry := range istioCfg.ServiceEntries {
+				if entry.Spec.Hosts != nil {
+					location := "MESH_EXTERNAL"
+					if entry.Spec.Location == "MESH_INTERNAL" {
+						location = "MESH_INTERNAL"
+					}
+					se := serviceEntry{
+						exportTo:  entry.Spec.ExportTo,
+						location:  location,
+						name:      entry.Metadata.Name,
+						namespace: entry.Metadata.Namespace,
+					}
+					for _, host := range entry.Spec.Hosts.([]interface{}) {
+						serviceEntryHosts.addHost(host.(string), &se)
+					}
+				}
+			}
+		}
+		globalInfo.Vendor[serviceEntryHostsKey] = serviceEntryHosts
+	}
+
+	return serviceEntryHosts
+}
+
 func isExportedToNamespace(se *serviceEntry, namespace string) bool {
 	if se.exportTo == nil {
 		return true
@@ -15,17 +15,29 @@ package istio
 //   responseTime: Must be one of: avg | 50 | 95 | 99
 //   throughputType: request | response (default: response)
 //
+// When time series are requested (timeSeries=true) the namespace graphs also provide a request traffic time
+// series for each edge and node (see time_series.go).
+//
+// When the graph cache is enabled, the namespace traffic maps, with appenders applied, are cached and shared
+// by namespace graph requests (see graph/cache.go).
+//
+// The namespace traffic maps are built concurrently, up to the configured graph.namespace_max_concurrent. The
+// appenders run in order on each namespace traffic map, and the maps are merged in namespace name order.
+//
 import (
 	"context"
 	"crypto/md5"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/telemetry"
 	"github.com/kiali/kiali/graph/telemetry/istio/appender"
@@ -49,18 +61,54 @@ func BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Clie
 	log.Tracef("Build [%s] graph for [%d] namespaces [%v]", o.GraphType, len(o.Namespaces), o.Namespaces)
 
 	appenders := appender.ParseAppenders(o)
-	trafficMap := graph.NewTrafficMap()
 
-	for _, namespace := range o.Namespaces {
-		log.Tracef("Build traffic map for namespace [%v]", namespace)
-		namespaceTrafficMap := buildNamespaceTrafficMap(namespace.Name, o, client)
-		namespaceInfo := graph.NewAppenderNamespaceInfo(namespace.Name)
-		for _, a := range appenders {
-			appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
-			a.AppendGraph(namespaceTrafficMap, globalInfo, namespaceInfo)
-			appenderTimer.ObserveDuration()
+	// build the namespace traffic maps concurrently, but merge them in namespace name order so that
+	// the resulting traffic map does not depend on which namespace finished first
+	namespaces := make([]string, 0, len(o.Namespaces))
+	for namespace := range o.Namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	// the appenders share the client, set it before they run concurrently
+	if globalInfo.PromClient == nil {
+		globalInfo.PromClient = client
+	}
+
+	namespaceTrafficMaps := make([]graph.TrafficMap, len(namespaces))
+	namespacePanics := make([]interface{}, len(namespaces))
+	maxConcurrent := config.Get().Graph.NamespaceMaxConcurrent
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	semaphore := make(chan struct{}, maxConcurrent)
+	wg := sync.WaitGroup{}
+
+	for i, namespace := range namespaces {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, namespace string) {
+			defer func() {
+				// an appender or query failure panics, re-panic it on the request goroutine
+				namespacePanics[i] = recover()
+				<-semaphore
+				wg.Done()
+			}()
+			namespaceTrafficMaps[i] = buildAppendedNamespaceTrafficMap(namespace, o, client, appenders, globalInfo)
+		}(i, namespace)
+	}
+	wg.Wait()
+
+	trafficMap := graph.NewTrafficMap()
+	for i, namespace := range namespaces {
+		if namespacePanics[i] != nil {
+			panic(namespacePanics[i])
 		}
-		telemetry.MergeTrafficMaps(trafficMap, namespace.Name, namespaceTrafficMap)
+		telemetry.MergeTrafficMaps(trafficMap, namespace, namespaceTrafficMaps[i])
+	}
+
+	if o.TimeSeriesStep > 0 {
+		addNodeTimeSeries(trafficMap)
 	}
 
 	// The appenders can add/remove/alter nodes. After the manipulations are complete
@@ -77,6 +125,37 @@ func BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Clie
 	return trafficMap
 }
 
+// buildAppendedNamespaceTrafficMap returns the namespace traffic map with the appenders applied, in order. The
+// traffic map is taken from the graph cache when possible.
+func buildAppendedNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client *prometheus.Client, appenders []graph.Appender, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	graphCache := graph.GetGraphCache()
+	if graphCache != nil {
+		if isCached, namespaceTrafficMap := graphCache.GetNamespaceTrafficMap(namespace, o); isCached {
+			log.Tracef("Use cached traffic map for namespace [%s]", namespace)
+			return namespaceTrafficMap
+		}
+	}
+
+	namespaceTimer := internalmetrics.GetGraphNamespaceTimePrometheusTimer(namespace, o.GraphType)
+	log.Tracef("Build traffic map for namespace [%s]", namespace)
+	namespaceTrafficMap := buildNamespaceTrafficMap(namespace, o, client)
+	if o.TimeSeriesStep > 0 {
+		addEdgeTimeSeries(namespaceTrafficMap, namespace, o, client)
+	}
+	namespaceInfo := graph.NewAppenderNamespaceInfo(namespace)
+	for _, a := range appenders {
+		appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
+		a.AppendGraph(namespaceTrafficMap, globalInfo, namespaceInfo)
+		appenderTimer.ObserveDuration()
+	}
+	namespaceTimer.ObserveDuration()
+
+	if graphCache != nil {
+		graphCache.SetNamespaceTrafficMap(namespace, o, namespaceTrafficMap)
+	}
+	return namespaceTrafficMap
+}
+
 // buildNamespaceTrafficMap returns a map of all namespace nodes (key=id).  All
 // nodes either directly send and/or receive requests from a node in the namespace.
 func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client *prometheus.Client) graph.TrafficMap {
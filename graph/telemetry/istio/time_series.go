@@ -0,0 +1,213 @@
+package istio
+
+// Time_series.go is responsible for adding request traffic time series to the edges and nodes of a TrafficMap.
+//
+// Algorithm: Run the same request traffic queries used to build the traffic map, but as range queries
+//            where each point is the rate for one step. For each point, build a traffic map from the
+//            point's samples, using the same logic as the main traffic map, and then pick out the rates
+//            for each edge in the main traffic map. This ensures the time series values are consistent
+//            with the edge rates, regardless of service node injection, unusual destinations, etc.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
+)
+
+// addEdgeTimeSeries sets graph.TimeSeriesKey on each request traffic edge in the namespace traffic map. The series
+// covers o.Duration, ending at o.QueryTime, with a point for each o.TimeSeriesStep.
+func addEdgeTimeSeries(trafficMap graph.TrafficMap, namespace string, o graph.TelemetryOptions, client *prometheus.Client) {
+	if o.Rates.Http != graph.RateRequests && o.Rates.Grpc != graph.RateRequests {
+		return
+	}
+
+	step := o.TimeSeriesStep
+	end := time.Unix(o.QueryTime, 0)
+	points := int(o.Duration / step)
+	if points < 1 {
+		points = 1
+	}
+	queryRange := prom_v1.Range{
+		Start: end.Add(-time.Duration(points-1) * step),
+		End:   end,
+		Step:  step,
+	}
+	log.Tracef("Build time series for namespace [%v], range [%+v]", namespace, queryRange)
+
+	// a traffic map for each point in the series
+	pointTrafficMaps := make([]graph.TrafficMap, points)
+	for i := range pointTrafficMaps {
+		pointTrafficMaps[i] = graph.NewTrafficMap()
+	}
+
+	metric := "istio_requests_total"
+	groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol,response_code,grpc_response_status,response_flags"
+
+	// These are the same queries used to build the request traffic, see buildNamespaceTrafficMap
+	queries := []string{
+		// 0) Incoming: query source telemetry to capture unserviced namespace services' incoming traffic
+		fmt.Sprintf(`sum(rate(%s{reporter="source",source_workload_namespace!="%s",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.%s\\..+$"} [%vs])) by (%s) > 0`,
+			metric,
+			namespace,
+			namespace,
+			int(step.Seconds()), // range duration for the query
+			groupBy),
+		// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
+		fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_workload_namespace="%s"} [%vs])) by (%s) > 0`,
+			metric,
+			namespace,
+			int(step.Seconds()), // range duration for the query
+			groupBy),
+		// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
+		fmt.Sprintf(`sum(rate(%s{reporter="source",source_workload_namespace="%s"} [%vs])) by (%s) > 0`,
+			metric,
+			namespace,
+			int(step.Seconds()), // range duration for the query
+			groupBy),
+	}
+	for _, query := range queries {
+		matrix := promQueryRange(query, queryRange, client.API())
+		for i, vector := range pointVectors(matrix, queryRange, points) {
+			populateTrafficMap(pointTrafficMaps[i], &vector, metric, o)
+		}
+	}
+
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			protocol := e.Metadata[graph.ProtocolKey].(string)
+			if protocol != graph.HTTP.Name && protocol != graph.GRPC.Name {
+				continue
+			}
+			ts := &graph.TimeSeries{
+				Start:    queryRange.Start.Unix(),
+				Step:     int64(step.Seconds()),
+				Rates:    make([]float64, points),
+				ErrRates: make([]float64, points),
+			}
+			for i, pointTrafficMap := range pointTrafficMaps {
+				if pointEdge := findEdge(pointTrafficMap, e.Source.ID, e.Dest.ID, protocol); pointEdge != nil {
+					ts.Rates[i], ts.ErrRates[i] = requestRates(pointEdge)
+				}
+			}
+			e.Metadata[graph.TimeSeriesKey] = graph.TimeSeriesMetadata{protocol: ts}
+		}
+	}
+}
+
+// addNodeTimeSeries sets graph.TimeSeriesKey on each node receiving request traffic, summing the time series
+// of its incoming edges. It should be called after the edge time series are set for all namespaces.
+func addNodeTimeSeries(trafficMap graph.TrafficMap) {
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			edgeTimeSeries, ok := e.Metadata[graph.TimeSeriesKey]
+			if !ok {
+				continue
+			}
+			nodeTimeSeries, ok := e.Dest.Metadata[graph.TimeSeriesKey].(graph.TimeSeriesMetadata)
+			if !ok {
+				nodeTimeSeries = graph.TimeSeriesMetadata{}
+				e.Dest.Metadata[graph.TimeSeriesKey] = nodeTimeSeries
+			}
+			for protocol, ets := range edgeTimeSeries.(graph.TimeSeriesMetadata) {
+				nts, ok := nodeTimeSeries[protocol]
+				if !ok {
+					nts = &graph.TimeSeries{
+						Start:    ets.Start,
+						Step:     ets.Step,
+						Rates:    make([]float64, len(ets.Rates)),
+						ErrRates: make([]float64, len(ets.ErrRates)),
+					}
+					nodeTimeSeries[protocol] = nts
+				}
+				for i := range ets.Rates {
+					nts.Rates[i] += ets.Rates[i]
+					nts.ErrRates[i] += ets.ErrRates[i]
+				}
+			}
+		}
+	}
+}
+
+// pointVectors splits the matrix into a vector for each point in the range
+func pointVectors(matrix model.Matrix, queryRange prom_v1.Range, points int) []model.Vector {
+	vectors := make([]model.Vector, points)
+	for _, stream := range matrix {
+		for _, pair := range stream.Values {
+			i := int((pair.Timestamp.Time().Sub(queryRange.Start) + queryRange.Step/2) / queryRange.Step)
+			if i < 0 || i >= points {
+				continue
+			}
+			vectors[i] = append(vectors[i], &model.Sample{Metric: stream.Metric, Value: pair.Value, Timestamp: pair.Timestamp})
+		}
+	}
+	return vectors
+}
+
+func findEdge(trafficMap graph.TrafficMap, sourceID, destID, protocol string) *graph.Edge {
+	if source, ok := trafficMap[sourceID]; ok {
+		for _, e := range source.Edges {
+			if e.Dest.ID == destID && e.Metadata[graph.ProtocolKey] == protocol {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// requestRates returns the total and error request rates for the edge, as defined by the edge protocol
+func requestRates(e *graph.Edge) (total, err float64) {
+	for _, p := range graph.Protocols {
+		if p.Name != e.Metadata[graph.ProtocolKey] {
+			continue
+		}
+		for _, r := range p.EdgeRates {
+			val, ok := e.Metadata[r.Name]
+			if !ok {
+				continue
+			}
+			switch {
+			case r.IsTotal:
+				total = val.(float64)
+			case r.IsErr:
+				err += val.(float64)
+			}
+		}
+		break
+	}
+	return total, err
+}
+
+func promQueryRange(query string, queryRange prom_v1.Range, api prom_v1.API) model.Matrix {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// wrap with a round() to be in line with metrics api
+	query = fmt.Sprintf("round(%s,0.001)", query)
+	log.Tracef("Graph range query:\n%s@start=%v,end=%v,step=%v\n", query, queryRange.Start.Format(graph.TF), queryRange.End.Format(graph.TF), queryRange.Step)
+
+	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Graph-Generation")
+	value, warnings, err := api.QueryRange(ctx, query, queryRange)
+	if warnings != nil && len(warnings) > 0 {
+		log.Warningf("promQueryRange. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+	}
+	graph.CheckUnavailable(err)
+	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
+
+	switch t := value.Type(); t {
+	case model.ValMatrix: // Range Vector
+		return value.(model.Matrix)
+	default:
+		graph.Error(fmt.Sprintf("No handling for type %v!\n", t))
+	}
+
+	return nil
+}
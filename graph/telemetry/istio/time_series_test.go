@@ -0,0 +1,131 @@
+package istio
+
+import (
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/prometheus"
+	"github.com/kiali/kiali/prometheus/prometheustest"
+)
+
+func timeSeriesMetric(sourceWl, destWl, code string) model.Metric {
+	return model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "bookinfo",
+		"source_workload":                model.LabelValue(sourceWl),
+		"source_canonical_service":       model.LabelValue(sourceWl),
+		"source_canonical_revision":      "v1",
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            model.LabelValue(destWl + ".bookinfo.svc.cluster.local"),
+		"destination_service_name":       model.LabelValue(destWl),
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           model.LabelValue(destWl),
+		"destination_canonical_service":  model.LabelValue(destWl),
+		"destination_canonical_revision": "v1",
+		"request_protocol":               "http",
+		"response_code":                  model.LabelValue(code),
+		"grpc_response_status":           "",
+		"response_flags":                 "-"}
+}
+
+func TestTimeSeries(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	queryTime := time.Unix(1600000000, 0)
+	start := model.TimeFromUnix(queryTime.Add(-2 * time.Minute).Unix())
+	point := func(i int, v model.SampleValue) model.SamplePair {
+		return model.SamplePair{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: v}
+	}
+
+	groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol,response_code,grpc_response_status,response_flags"
+	incomingQuery := `round(sum(rate(istio_requests_total{reporter="destination",destination_workload_namespace="bookinfo"} [60s])) by (` + groupBy + `) > 0,0.001)`
+	incoming := model.Matrix{
+		&model.SampleStream{
+			Metric: timeSeriesMetric("productpage", "reviews", "200"),
+			Values: []model.SamplePair{point(0, 10.0), point(1, 10.0), point(2, 10.0)}},
+		&model.SampleStream{
+			Metric: timeSeriesMetric("productpage", "reviews", "500"),
+			Values: []model.SamplePair{point(1, 1.0), point(2, 5.0)}},
+		&model.SampleStream{
+			Metric: timeSeriesMetric("reviews", "ratings", "200"),
+			Values: []model.SamplePair{point(1, 2.0), point(2, 2.0)}},
+	}
+	// the source proxy reports the same traffic, it should not be counted twice
+	outgoingQuery := `round(sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo"} [60s])) by (` + groupBy + `) > 0,0.001)`
+	outgoing := model.Matrix{
+		&model.SampleStream{
+			Metric: timeSeriesMetric("productpage", "reviews", "200"),
+			Values: []model.SamplePair{point(0, 10.0), point(1, 10.0), point(2, 10.0)}},
+	}
+
+	api := new(prometheustest.PromAPIMock)
+	api.On("QueryRange", mock.Anything, incomingQuery, mock.AnythingOfType("v1.Range")).Return(incoming, nil)
+	api.On("QueryRange", mock.Anything, outgoingQuery, mock.AnythingOfType("v1.Range")).Return(outgoing, nil)
+	api.On("QueryRange", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("v1.Range")).Return(model.Matrix{}, nil)
+	client, err := prometheus.NewClient()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	client.Inject(api)
+
+	productpage := graph.NewNode(business.DefaultClusterID, "bookinfo", "productpage", "bookinfo", "productpage", "productpage", "v1", graph.GraphTypeWorkload)
+	reviews := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews", "reviews", "v1", graph.GraphTypeWorkload)
+	ratings := graph.NewNode(business.DefaultClusterID, "bookinfo", "ratings", "bookinfo", "ratings", "ratings", "v1", graph.GraphTypeWorkload)
+	trafficMap := graph.NewTrafficMap()
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+	trafficMap[ratings.ID] = &ratings
+	productpage.AddEdge(&reviews).Metadata[graph.ProtocolKey] = "http"
+	reviews.AddEdge(&ratings).Metadata[graph.ProtocolKey] = "http"
+	reviews.AddEdge(&ratings).Metadata[graph.ProtocolKey] = "tcp"
+
+	o := graph.TelemetryOptions{
+		Namespaces: graph.NamespaceInfoMap{
+			"bookinfo": {Name: "bookinfo", Duration: 3 * time.Minute},
+		},
+		Rates: graph.RequestedRates{
+			Grpc: graph.RateRequests,
+			Http: graph.RateRequests,
+			Tcp:  graph.RateSent,
+		},
+		TimeSeriesStep: time.Minute,
+	}
+	o.Duration = 3 * time.Minute
+	o.GraphType = graph.GraphTypeWorkload
+	o.QueryTime = queryTime.Unix()
+
+	addEdgeTimeSeries(trafficMap, "bookinfo", o, client)
+	addNodeTimeSeries(trafficMap)
+
+	api.AssertCalled(t, "QueryRange", mock.Anything, incomingQuery, prom_v1.Range{Start: queryTime.Add(-2 * time.Minute), End: queryTime, Step: time.Minute})
+
+	ts := productpage.Edges[0].Metadata[graph.TimeSeriesKey].(graph.TimeSeriesMetadata)["http"]
+	assert.Equal(start.Unix(), ts.Start)
+	assert.Equal(int64(60), ts.Step)
+	assert.Equal([]float64{10.0, 11.0, 15.0}, ts.Rates)
+	assert.Equal([]float64{0.0, 1.0, 5.0}, ts.ErrRates)
+
+	ts = reviews.Edges[0].Metadata[graph.TimeSeriesKey].(graph.TimeSeriesMetadata)["http"]
+	assert.Equal([]float64{0.0, 2.0, 2.0}, ts.Rates)
+	assert.Equal([]float64{0.0, 0.0, 0.0}, ts.ErrRates)
+	assert.Nil(reviews.Edges[1].Metadata[graph.TimeSeriesKey])
+
+	// nodes report incoming traffic
+	assert.Nil(productpage.Metadata[graph.TimeSeriesKey])
+	ts = reviews.Metadata[graph.TimeSeriesKey].(graph.TimeSeriesMetadata)["http"]
+	assert.Equal([]float64{10.0, 11.0, 15.0}, ts.Rates)
+	assert.Equal([]float64{0.0, 1.0, 5.0}, ts.ErrRates)
+	ts = ratings.Metadata[graph.TimeSeriesKey].(graph.TimeSeriesMetadata)["http"]
+	assert.Equal([]float64{0.0, 2.0, 2.0}, ts.Rates)
+}
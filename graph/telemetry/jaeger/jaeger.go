@@ -0,0 +1,395 @@
+// Package jaeger provides the Jaeger (trace-derived) implementation of graph/TelemetryProvider.
+package jaeger
+
+// Jaeger.go is responsible for generating TrafficMaps using Jaeger traces. Unlike the Istio vendor it does
+// not require sidecar telemetry, so it can show workloads that are instrumented for tracing but are not in
+// the mesh. Because it works with traces, and not Prometheus, it does not satisfy the TelemetryVendor
+// interface and it does not run the (Prometheus-based) Istio appenders.
+//
+// The algorithm:
+//   Fetch the traces for each app in the requested namespaces, for the requested time range. Traces
+//   are fetched per app, so the same trace may be returned several times, it is processed only once.
+//
+//   Each span is assigned a node, using the span and process tags (see spanNodeInfo). Sidecar spans
+//   are assigned to the node of the proxied workload.
+//
+//   Each span whose parent span is assigned a different node is a call from the parent node to the span
+//   node, and adds to the edge between the nodes. Spans assigned the same node as their parent are
+//   internal to the node, they are ignored.
+//
+// The edge traffic is derived from the calls found in the fetched traces: rates are calls/duration, using
+// the (possibly reduced) duration of the namespace whose traces hold the calls, and
+// response codes are taken from the span status tags (error spans without a status are reported as 500,
+// or gRPC UNKNOWN). The edge response time is the average call duration. Note that traces are sampled, and
+// limited per app, so the rates are typically lower than the actual request rates, they are best used to
+// compare the relative traffic.
+//
+// Supports only namespace graphs with graphType app, versionedApp or workload, and one vendor-specific
+// query parameter:
+//   traceLimit: The maximum number of traces fetched per app (default: 100)
+//
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	jaegerModels "github.com/jaegertracing/jaeger/model/json"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry"
+	"github.com/kiali/kiali/jaeger"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+const (
+	defaultTraceLimit = 100
+
+	// Metadata keys used only while building the traffic map
+	callCount         graph.MetadataKey = "jaegerCallCount"
+	callRate          graph.MetadataKey = "jaegerCallRate"
+	callDurationTotal graph.MetadataKey = "jaegerCallDurationTotal"
+)
+
+// nodeInfo identifies the node of a span
+type nodeInfo struct {
+	namespace string
+	workload  string
+	app       string
+	version   string
+}
+
+// namespaceWorkloads provides the workload names of a namespace, used to resolve the workload of a pod
+type namespaceWorkloads func(namespace string) []string
+
+// BuildNamespacesTrafficMap returns the TrafficMap for the requested namespaces, built from Jaeger traces
+func BuildNamespacesTrafficMap(o graph.TelemetryOptions, client jaeger.ClientInterface, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	log.Tracef("Build [%s] graph from traces for [%d] namespaces [%v]", o.GraphType, len(o.Namespaces), o.Namespaces)
+
+	if o.GraphType == graph.GraphTypeService {
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] does not support graphType [%s]", graph.VendorJaeger, o.GraphType))
+	}
+
+	traceLimit := defaultTraceLimit
+	if traceLimitString := o.Params.Get("traceLimit"); traceLimitString != "" {
+		var err error
+		if traceLimit, err = strconv.Atoi(traceLimitString); err != nil || traceLimit <= 0 {
+			graph.BadRequest(fmt.Sprintf("Invalid traceLimit, expecting a positive integer [%s]", traceLimitString))
+		}
+	}
+
+	workloadNames := make(map[string][]string)
+	getWorkloads := func(namespace string) []string {
+		if names, ok := workloadNames[namespace]; ok {
+			return names
+		}
+		names := []string{}
+		if _, ok := o.AccessibleNamespaces[namespace]; ok {
+			workloadList, err := globalInfo.Business.Workload.GetWorkloadList(namespace, false)
+			graph.CheckError(err)
+			for _, wl := range workloadList.Workloads {
+				names = append(names, wl.Name)
+			}
+		}
+		workloadNames[namespace] = names
+		return names
+	}
+
+	queryTime := time.Unix(o.QueryTime, 0)
+	traces := make(map[string][]jaegerModels.Trace)
+	traceIDs := make(map[jaegerModels.TraceID]bool)
+	for _, namespace := range o.Namespaces {
+		appList, err := globalInfo.Business.App.GetAppList(namespace.Name, false)
+		graph.CheckError(err)
+
+		query := models.TracingQuery{
+			Start: queryTime.Add(-namespace.Duration),
+			End:   queryTime,
+			Limit: traceLimit,
+		}
+		for _, app := range appList.Apps {
+			r, err := client.GetAppTraces(namespace.Name, app.Name, query)
+			graph.CheckError(err)
+			for _, trace := range r.Data {
+				if !traceIDs[trace.TraceID] {
+					traceIDs[trace.TraceID] = true
+					traces[namespace.Name] = append(traces[namespace.Name], trace)
+				}
+			}
+		}
+	}
+
+	trafficMap := buildTrafficMap(traces, o, getWorkloads)
+
+	telemetry.MarkOutsideOrInaccessible(trafficMap, o)
+	telemetry.MarkTrafficGenerators(trafficMap)
+
+	return trafficMap
+}
+
+// buildTrafficMap returns the TrafficMap for the traces, keyed by the namespace they were fetched for
+func buildTrafficMap(tracesPerNamespace map[string][]jaegerModels.Trace, o graph.TelemetryOptions, workloads namespaceWorkloads) graph.TrafficMap {
+	trafficMap := graph.NewTrafficMap()
+
+	for namespace, traces := range tracesPerNamespace {
+		duration := o.Duration.Seconds()
+		if namespaceInfo, ok := o.Namespaces[namespace]; ok && namespaceInfo.Duration > 0 {
+			duration = namespaceInfo.Duration.Seconds()
+		}
+		for _, trace := range traces {
+			addTrace(trafficMap, trace, duration, o, workloads)
+		}
+	}
+
+	// set the edge rates and response times
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			if rates, ok := e.Metadata[callRate].(map[string]float64); ok {
+				for codeAndFlags, rate := range rates {
+					code := strings.SplitN(codeAndFlags, " ", 2)
+					graph.AddToMetadata(e.Metadata[graph.ProtocolKey].(string), rate, code[0], code[1], "", e.Source.Metadata, e.Dest.Metadata, e.Metadata)
+				}
+				if total := e.Metadata[callCount].(float64); total > 0 {
+					e.Metadata[graph.ResponseTime] = e.Metadata[callDurationTotal].(float64) / total
+				}
+			}
+			delete(e.Metadata, callCount)
+			delete(e.Metadata, callRate)
+			delete(e.Metadata, callDurationTotal)
+		}
+	}
+
+	return trafficMap
+}
+
+// addTrace adds the calls of the trace to the traffic map, duration is the trace query duration in seconds
+func addTrace(trafficMap graph.TrafficMap, trace jaegerModels.Trace, duration float64, o graph.TelemetryOptions, workloads namespaceWorkloads) {
+	spans := make(map[jaegerModels.SpanID]*jaegerModels.Span, len(trace.Spans))
+	nodes := make(map[jaegerModels.SpanID]*graph.Node, len(trace.Spans))
+	for i := range trace.Spans {
+		span := &trace.Spans[i]
+		spans[span.SpanID] = span
+		if info, ok := spanNodeInfo(span, trace.Processes, o, workloads); ok {
+			nodes[span.SpanID] = addNode(trafficMap, info, o)
+		}
+	}
+
+	for _, span := range spans {
+		dest, ok := nodes[span.SpanID]
+		if !ok {
+			continue
+		}
+		parentID, hasParent := parentSpanID(span)
+		if !hasParent {
+			continue
+		}
+		source, ok := nodes[parentID]
+		if !ok || source.ID == dest.ID {
+			continue
+		}
+		addCall(span, source, dest, duration)
+	}
+}
+
+func addNode(trafficMap graph.TrafficMap, info nodeInfo, o graph.TelemetryOptions) *graph.Node {
+	service := ""
+	if o.GraphType == graph.GraphTypeWorkload && !graph.IsOK(info.workload) {
+		// without a workload, represent the traced app as a service
+		service = info.app
+	}
+	id, _ := graph.Id(graph.Unknown, info.namespace, service, info.namespace, info.workload, info.app, info.version, o.GraphType)
+	if n, ok := trafficMap[id]; ok {
+		return n
+	}
+	n := graph.NewNode(graph.Unknown, info.namespace, service, info.namespace, info.workload, info.app, info.version, o.GraphType)
+	trafficMap[id] = &n
+	return &n
+}
+
+// addCall adds the call, represented by the span, to the edge between source and dest, duration is the trace
+// query duration in seconds
+func addCall(span *jaegerModels.Span, source, dest *graph.Node, duration float64) {
+	protocol, code, flags := spanResponse(span)
+
+	var edge *graph.Edge
+	for _, e := range source.Edges {
+		if e.Dest.ID == dest.ID && e.Metadata[graph.ProtocolKey] == protocol {
+			edge = e
+			break
+		}
+	}
+	if edge == nil {
+		edge = source.AddEdge(dest)
+		edge.Metadata[graph.ProtocolKey] = protocol
+		edge.Metadata[callCount] = 0.0
+		edge.Metadata[callRate] = make(map[string]float64)
+		edge.Metadata[callDurationTotal] = 0.0
+	}
+
+	edge.Metadata[callCount] = edge.Metadata[callCount].(float64) + 1
+	edge.Metadata[callRate].(map[string]float64)[code+" "+flags] += 1 / duration
+	// span duration is in microseconds, response time is in millis
+	edge.Metadata[callDurationTotal] = edge.Metadata[callDurationTotal].(float64) + float64(span.Duration)/1000.0
+}
+
+// parentSpanID returns the ID of the span's parent, if it has one
+func parentSpanID(span *jaegerModels.Span) (jaegerModels.SpanID, bool) {
+	for _, ref := range span.References {
+		if ref.RefType == jaegerModels.ChildOf && ref.TraceID == span.TraceID {
+			return ref.SpanID, true
+		}
+	}
+	if span.ParentSpanID != "" {
+		return span.ParentSpanID, true
+	}
+	for _, ref := range span.References {
+		if ref.RefType == jaegerModels.FollowsFrom && ref.TraceID == span.TraceID {
+			return ref.SpanID, true
+		}
+	}
+	return "", false
+}
+
+// spanNodeInfo returns the node of the span. The span and process tags are used to identify the node,
+// preferring the Istio proxy tags, then the OpenTelemetry/Kubernetes resource tags and finally the
+// Jaeger service name ("app" or "app.namespace"). The workload is resolved from the pod name, when available.
+func spanNodeInfo(span *jaegerModels.Span, processes map[jaegerModels.ProcessID]jaegerModels.Process, o graph.TelemetryOptions, workloads namespaceWorkloads) (nodeInfo, bool) {
+	process := span.Process
+	if process == nil {
+		if p, ok := processes[span.ProcessID]; ok {
+			process = &p
+		}
+	}
+	if process == nil {
+		return nodeInfo{}, false
+	}
+	tag := func(keys ...string) string {
+		for _, key := range keys {
+			if v, ok := tagValue(span.Tags, key); ok && v != "" {
+				return v
+			}
+			if v, ok := tagValue(process.Tags, key); ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	// sidecar node_id is like: sidecar~172.17.0.20~reviews-v1-6d8996bff-ztg6z.bookinfo~bookinfo.svc.cluster.local
+	pod := ""
+	namespace := tag("istio.namespace", "k8s.namespace.name", "namespace")
+	if nodeID := tag("node_id"); nodeID != "" {
+		if parts := strings.Split(nodeID, "~"); len(parts) >= 3 {
+			if i := strings.LastIndex(parts[2], "."); i > 0 {
+				pod = parts[2][:i]
+				if namespace == "" {
+					namespace = parts[2][i+1:]
+				}
+			}
+		}
+	}
+	if pod == "" {
+		pod = tag("k8s.pod.name", "hostname")
+	}
+
+	app := tag("istio.canonical_service", "app")
+	serviceName := process.ServiceName
+	if namespace == "" {
+		// a service name like "app.namespace" is used when the tracing namespace selector is enabled
+		if i := strings.LastIndex(serviceName, "."); i > 0 {
+			if _, ok := o.AccessibleNamespaces[serviceName[i+1:]]; ok {
+				namespace = serviceName[i+1:]
+			}
+		}
+	}
+	if namespace == "" {
+		return nodeInfo{}, false
+	}
+	if app == "" {
+		app = strings.TrimSuffix(serviceName, "."+namespace)
+	}
+	if app == "" {
+		return nodeInfo{}, false
+	}
+
+	version := tag("istio.canonical_revision", "version", "service.version")
+	if version == "" || version == "latest" {
+		version = graph.Unknown
+	}
+
+	workload := tag("k8s.deployment.name", "workload")
+	if workload == "" && pod != "" {
+		workload = podWorkload(pod, workloads(namespace))
+	}
+	if workload == "" {
+		workload = graph.Unknown
+	}
+
+	return nodeInfo{namespace: namespace, workload: workload, app: app, version: version}, true
+}
+
+// podWorkload returns the workload with the longest name prefixing the pod name, or "" if none is found
+func podWorkload(pod string, workloads []string) string {
+	result := ""
+	for _, wl := range workloads {
+		if (pod == wl || strings.HasPrefix(pod, wl+"-")) && len(wl) > len(result) {
+			result = wl
+		}
+	}
+	return result
+}
+
+// spanResponse returns the protocol, response code and response flags for the call represented by the span
+func spanResponse(span *jaegerModels.Span) (protocol, code, flags string) {
+	protocol = "http"
+	if v, _ := tagValue(span.Tags, "rpc.system"); v == "grpc" {
+		protocol = "grpc"
+	} else if v, _ := tagValue(span.Tags, "component"); strings.EqualFold(v, "grpc") {
+		protocol = "grpc"
+	}
+
+	if protocol == "grpc" {
+		code, _ = tagValue(span.Tags, "grpc.status_code")
+		if code == "" {
+			code, _ = tagValue(span.Tags, "rpc.grpc.status_code")
+		}
+	} else {
+		code, _ = tagValue(span.Tags, "http.status_code")
+	}
+	if code == "" {
+		code = "200"
+		if protocol == "grpc" {
+			code = "0"
+		}
+		if isError, _ := tagValue(span.Tags, "error"); isError == "true" {
+			code = "500"
+			if protocol == "grpc" {
+				code = "2"
+			}
+		}
+	}
+
+	flags, _ = tagValue(span.Tags, "response_flags")
+	if flags == "" {
+		flags = "-"
+	}
+	return protocol, code, flags
+}
+
+// tagValue returns the string representation of the tag value
+func tagValue(tags []jaegerModels.KeyValue, key string) (string, bool) {
+	for _, tag := range tags {
+		if tag.Key == key {
+			switch v := tag.Value.(type) {
+			case string:
+				return v, true
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64), true
+			default:
+				return fmt.Sprintf("%v", v), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,179 @@
+package jaeger
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	jaegerModels "github.com/jaegertracing/jaeger/model/json"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+func newTestOptions(graphType string) graph.TelemetryOptions {
+	return graph.TelemetryOptions{
+		AccessibleNamespaces: map[string]time.Time{"bookinfo": {}},
+		Namespaces:           graph.NamespaceInfoMap{"bookinfo": graph.NamespaceInfo{Name: "bookinfo", Duration: 10 * time.Minute}},
+		CommonOptions: graph.CommonOptions{
+			Duration:  10 * time.Minute,
+			GraphType: graphType,
+			Params:    url.Values{},
+		},
+	}
+}
+
+func testWorkloads(namespace string) []string {
+	if namespace == "bookinfo" {
+		return []string{"productpage-v1", "reviews", "reviews-v1", "ratings-v1"}
+	}
+	return []string{}
+}
+
+func tag(key string, value interface{}) jaegerModels.KeyValue {
+	return jaegerModels.KeyValue{Key: key, Value: value}
+}
+
+func span(id, parent string, process jaegerModels.ProcessID, durationMicros uint64, tags ...jaegerModels.KeyValue) jaegerModels.Span {
+	s := jaegerModels.Span{
+		TraceID:   "t1",
+		SpanID:    jaegerModels.SpanID(id),
+		ProcessID: process,
+		Duration:  durationMicros,
+		Tags:      tags,
+	}
+	if parent != "" {
+		s.References = []jaegerModels.Reference{{RefType: jaegerModels.ChildOf, TraceID: "t1", SpanID: jaegerModels.SpanID(parent)}}
+	}
+	return s
+}
+
+// testTrace returns a trace for:
+//
+//	productpage (app instrumented) -> reviews (app instrumented, 2 calls, 1 error) -> ratings (sidecar, grpc)
+func testTrace() jaegerModels.Trace {
+	return jaegerModels.Trace{
+		TraceID: "t1",
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+			"p1": {ServiceName: "productpage.bookinfo", Tags: []jaegerModels.KeyValue{tag("hostname", "productpage-v1-6b746f74dc-9stvt")}},
+			"p2": {ServiceName: "reviews.bookinfo", Tags: []jaegerModels.KeyValue{tag("hostname", "reviews-v1-545db77b95-2bmbs"), tag("version", "v1")}},
+			"p3": {ServiceName: "ratings.bookinfo"},
+		},
+		Spans: []jaegerModels.Span{
+			span("s1", "", "p1", 50000),
+			span("s2", "s1", "p1", 45000),
+			span("s3", "s2", "p2", 10000, tag("http.status_code", float64(200))),
+			span("s4", "s2", "p2", 30000, tag("error", true)),
+			span("s5", "s3", "p3", 2000,
+				tag("node_id", "sidecar~172.17.0.20~ratings-v1-b6994bb9-gn2jl.bookinfo~bookinfo.svc.cluster.local"),
+				tag("istio.canonical_service", "ratings"),
+				tag("istio.canonical_revision", "v1"),
+				tag("rpc.system", "grpc"),
+				tag("grpc.status_code", float64(0))),
+		},
+	}
+}
+
+func TestBuildTrafficMap(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := buildTrafficMap(map[string][]jaegerModels.Trace{"bookinfo": {testTrace()}}, newTestOptions(graph.GraphTypeWorkload), testWorkloads)
+	assert.Equal(3, len(trafficMap))
+
+	productpage, ok := trafficMap["wl_unknown_bookinfo_productpage-v1"]
+	assert.True(ok)
+	reviews, ok := trafficMap["wl_unknown_bookinfo_reviews-v1"]
+	assert.True(ok)
+	ratings, ok := trafficMap["wl_unknown_bookinfo_ratings-v1"]
+	assert.True(ok)
+	assert.Equal("v1", ratings.Version)
+
+	assert.Equal(1, len(productpage.Edges))
+	e := productpage.Edges[0]
+	assert.Equal(reviews.ID, e.Dest.ID)
+	assert.Equal("http", e.Metadata[graph.ProtocolKey])
+	assert.InDelta(2.0/600.0, e.Metadata["http"], 0.00001)
+	assert.InDelta(1.0/600.0, e.Metadata["http5xx"], 0.00001)
+	assert.Equal(20.0, e.Metadata[graph.ResponseTime])
+	_, ok = e.Metadata[callCount]
+	assert.False(ok)
+
+	assert.Equal(1, len(reviews.Edges))
+	e = reviews.Edges[0]
+	assert.Equal(ratings.ID, e.Dest.ID)
+	assert.Equal("grpc", e.Metadata[graph.ProtocolKey])
+	assert.InDelta(1.0/600.0, e.Metadata["grpc"], 0.00001)
+	_, ok = e.Metadata["grpcErr"]
+	assert.False(ok)
+	assert.Equal(2.0, e.Metadata[graph.ResponseTime])
+
+	assert.Empty(ratings.Edges)
+}
+
+func TestBuildTrafficMapAppGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	// processing the same trace twice doubles the calls, but not the nodes
+	trafficMap := buildTrafficMap(map[string][]jaegerModels.Trace{"bookinfo": {testTrace(), testTrace()}}, newTestOptions(graph.GraphTypeApp), testWorkloads)
+	assert.Equal(3, len(trafficMap))
+
+	productpage, ok := trafficMap["app_unknown_bookinfo_productpage"]
+	assert.True(ok)
+	assert.Equal(1, len(productpage.Edges))
+	assert.Equal("app_unknown_bookinfo_reviews", productpage.Edges[0].Dest.ID)
+	assert.InDelta(4.0/600.0, productpage.Edges[0].Metadata["http"], 0.00001)
+}
+
+func TestBuildTrafficMapNamespaceDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	// the namespace was created after the start of the requested duration
+	o := newTestOptions(graph.GraphTypeApp)
+	o.Namespaces["bookinfo"] = graph.NamespaceInfo{Name: "bookinfo", Duration: 5 * time.Minute}
+	trafficMap := buildTrafficMap(map[string][]jaegerModels.Trace{"bookinfo": {testTrace()}}, o, testWorkloads)
+
+	productpage, ok := trafficMap["app_unknown_bookinfo_productpage"]
+	assert.True(ok)
+	assert.Equal(1, len(productpage.Edges))
+	assert.InDelta(2.0/300.0, productpage.Edges[0].Metadata["http"], 0.00001)
+	assert.Equal(20.0, productpage.Edges[0].Metadata[graph.ResponseTime])
+}
+
+func TestSpanNodeInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	o := newTestOptions(graph.GraphTypeWorkload)
+	processes := map[jaegerModels.ProcessID]jaegerModels.Process{
+		"p1": {ServiceName: "details"},
+		"p2": {ServiceName: "details", Tags: []jaegerModels.KeyValue{tag("k8s.namespace.name", "bookinfo"), tag("k8s.deployment.name", "details-v1")}},
+		"p3": {ServiceName: "details.other"},
+	}
+
+	// no namespace
+	s := span("s1", "", "p1", 1)
+	_, ok := spanNodeInfo(&s, processes, o, testWorkloads)
+	assert.False(ok)
+
+	s = span("s1", "", "p2", 1)
+	info, ok := spanNodeInfo(&s, processes, o, testWorkloads)
+	assert.True(ok)
+	assert.Equal(nodeInfo{namespace: "bookinfo", workload: "details-v1", app: "details", version: graph.Unknown}, info)
+
+	// the service name namespace must be accessible
+	s = span("s1", "", "p3", 1)
+	_, ok = spanNodeInfo(&s, processes, o, testWorkloads)
+	assert.False(ok)
+
+	// unknown process
+	s = span("s1", "", "p4", 1)
+	_, ok = spanNodeInfo(&s, processes, o, testWorkloads)
+	assert.False(ok)
+}
+
+func TestPodWorkload(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("reviews-v1", podWorkload("reviews-v1-545db77b95-2bmbs", testWorkloads("bookinfo")))
+	assert.Equal("reviews", podWorkload("reviews-545db77b95-2bmbs", testWorkloads("bookinfo")))
+	assert.Equal("", podWorkload("details-v1-545db77b95-2bmbs", testWorkloads("bookinfo")))
+}
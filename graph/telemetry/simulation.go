@@ -0,0 +1,520 @@
+package telemetry
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+// SimulationWorkload is a workload selected by a service, a potential destination of simulated traffic
+type SimulationWorkload struct {
+	App     string
+	Labels  map[string]string
+	Name    string
+	Version string
+}
+
+// Simulation describes a proposed VirtualService, along with the information needed to resolve its routes.
+// Services are keyed by SimulationServiceKey.
+type Simulation struct {
+	ClusterNamespaces []string                                // used to resolve namespace.service hosts
+	GraphType         string                                  // versionedApp | workload
+	Namespace         string                                  // the VirtualService namespace
+	Subsets           map[string]map[string]map[string]string // the DestinationRule subset labels, by service and subset name
+	VirtualService    models.VirtualService
+	Workloads         map[string][]SimulationWorkload // the workloads selected by each service
+}
+
+// simulationRoute is a parsed VirtualService http or tcp route
+type simulationRoute struct {
+	destinations []simulationDestination
+	matches      []map[string]interface{} // empty if the route matches all requests
+}
+
+type simulationDestination struct {
+	namespace string
+	service   string
+	subset    string
+	weight    float64 // normalized, the weights of a route add up to 1
+}
+
+// SimulationServiceKey returns the key of a service in the Simulation maps
+func SimulationServiceKey(namespace, service string) string {
+	return namespace + "/" + service
+}
+
+// SimulateVirtualService returns a copy of trafficMap with the traffic into the VirtualService hosts redistributed
+// as if the proposed VirtualService was applied. The trafficMap must have injected service nodes. The traffic
+// of every edge into a host service node is routed by the first matching route, and split among the route
+// destinations by weight:
+//   - traffic routed to the service itself is split among the workloads of the destination subset, proportionally
+//     to their current traffic or evenly if they don't receive traffic yet. Traffic without subset is split
+//     proportionally to the current traffic of the service. Traffic to an undefined subset is dropped.
+//   - traffic routed to another service is moved to an edge into that service, and follows its current routing.
+//     When that service receives no traffic yet, the traffic is split evenly among its workloads.
+//
+// The VirtualService applies to the traffic of the sidecars when its gateways include the mesh gateway, the
+// default, and to the traffic of the ingress gateways it lists. Ingress gateway sources are identified by the
+// istio appender. Only the source of the observed traffic is known, route matches on sourceLabels,
+// sourceNamespace and gateways are evaluated. Matches on request attributes (e.g. uri, headers, port) are assumed to not match, as
+// most requests don't carry them. Traffic not matching any route is left as-is. Request rates are scaled, other
+// edge metadata (e.g. response times, response codes detail) is kept as observed. Nodes for workloads not receiving
+// traffic are added to the returned map as needed.
+func SimulateVirtualService(trafficMap graph.TrafficMap, s Simulation) graph.TrafficMap {
+	simulated := graph.CloneTrafficMap(trafficMap)
+	httpRoutes := parseSimulationRoutes(s.VirtualService.Spec.Http, s)
+	tcpRoutes := parseSimulationRoutes(s.VirtualService.Spec.Tcp, s)
+
+	hosts := make(map[string]bool)
+	for _, host := range s.VirtualService.Spec.Hosts {
+		if namespace, service, ok := s.resolveHost(host); ok {
+			hosts[SimulationServiceKey(namespace, service)] = true
+		}
+	}
+
+	// collect the edges into the host service nodes up front, edges are added while simulating
+	incoming := make(map[*graph.Node][]*graph.Edge)
+	for _, n := range simulated {
+		for _, e := range n.Edges {
+			if e.Dest.NodeType == graph.NodeTypeService && hosts[SimulationServiceKey(e.Dest.Namespace, e.Dest.Service)] {
+				incoming[e.Dest] = append(incoming[e.Dest], e)
+			}
+		}
+	}
+	serviceNodes := make([]*graph.Node, 0, len(incoming))
+	for n, edges := range incoming {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].Source.ID < edges[j].Source.ID })
+		serviceNodes = append(serviceNodes, n)
+	}
+	sort.Slice(serviceNodes, func(i, j int) bool { return serviceNodes[i].ID < serviceNodes[j].ID })
+
+	for _, serviceNode := range serviceNodes {
+		for _, p := range graph.Protocols {
+			// without routes for the protocol the service traffic is not affected by the VirtualService
+			routes := httpRoutes
+			if p.Name == graph.TCP.Name {
+				routes = tcpRoutes
+			}
+			if len(routes) == 0 {
+				continue
+			}
+			var edges []*graph.Edge
+			for _, e := range incoming[serviceNode] {
+				if e.Metadata[graph.ProtocolKey] == p.Name {
+					edges = append(edges, e)
+				}
+			}
+			if len(edges) > 0 {
+				simulateServiceTraffic(simulated, serviceNode, p, edges, routes, s)
+			}
+		}
+	}
+
+	return simulated
+}
+
+func simulateServiceTraffic(trafficMap graph.TrafficMap, serviceNode *graph.Node, p graph.Protocol, edges []*graph.Edge, routes []simulationRoute, s Simulation) {
+	// the traffic routed to the service itself, by subset
+	subsetTraffic := make(map[string]float64)
+	for _, e := range edges {
+		total := getRate(e.Metadata, totalRate(p))
+		if total == 0.0 {
+			continue
+		}
+		route, ok := matchSimulationRoute(routes, e.Source)
+		if !ok || !simulationGatewaysApply(s.VirtualService.Spec.Gateways, e.Source) {
+			subsetTraffic[""] += total
+			continue
+		}
+		redirected := 0.0
+		for _, d := range route.destinations {
+			val := total * d.weight
+			if d.namespace == serviceNode.Namespace && d.service == serviceNode.Service {
+				subsetTraffic[d.subset] += val
+				continue
+			}
+			redirected += val
+			redirectNode := addSimulationServiceNode(trafficMap, serviceNode.Cluster, d.namespace, d.service)
+			forwardSimulatedTraffic(trafficMap, redirectNode, p, val, s)
+			addSimulatedTraffic(e.Source, redirectNode, p, val)
+		}
+		if redirected > 0.0 {
+			scaleEdgeTraffic(e, p, (total-redirected)/total)
+		}
+	}
+
+	serviceKey := SimulationServiceKey(serviceNode.Namespace, serviceNode.Service)
+	current := make(map[*graph.Node]float64)
+	var currentDests []*graph.Node
+	for _, e := range serviceNode.Edges {
+		if e.Metadata[graph.ProtocolKey] == p.Name {
+			current[e.Dest] += getRate(e.Metadata, totalRate(p))
+			currentDests = append(currentDests, e.Dest)
+		}
+	}
+
+	subsets := make([]string, 0, len(subsetTraffic))
+	for subset := range subsetTraffic {
+		subsets = append(subsets, subset)
+	}
+	sort.Strings(subsets)
+
+	simulatedTraffic := make(map[*graph.Node]float64)
+	for _, subset := range subsets {
+		var dests []*graph.Node
+		if subset == "" {
+			dests = currentDests
+			if len(dests) == 0 {
+				for _, w := range s.Workloads[serviceKey] {
+					dests = append(dests, addSimulationWorkloadNode(trafficMap, serviceNode, w, s.GraphType))
+				}
+			}
+		} else if labels, ok := s.Subsets[serviceKey][subset]; ok {
+			for _, w := range s.Workloads[serviceKey] {
+				if labelsMatch(labels, w.Labels) {
+					dests = append(dests, addSimulationWorkloadNode(trafficMap, serviceNode, w, s.GraphType))
+				}
+			}
+		}
+		for dest, val := range splitTraffic(subsetTraffic[subset], dests, current) {
+			simulatedTraffic[dest] += val
+		}
+	}
+
+	for _, e := range serviceNode.Edges {
+		if e.Metadata[graph.ProtocolKey] != p.Name {
+			continue
+		}
+		if currentVal := current[e.Dest]; currentVal > 0.0 {
+			scaleEdgeTraffic(e, p, simulatedTraffic[e.Dest]/currentVal)
+			delete(simulatedTraffic, e.Dest)
+		}
+	}
+	// what remains goes to destinations without current traffic
+	dests := make([]*graph.Node, 0, len(simulatedTraffic))
+	for dest := range simulatedTraffic {
+		dests = append(dests, dest)
+	}
+	sort.Slice(dests, func(i, j int) bool { return dests[i].ID < dests[j].ID })
+	for _, dest := range dests {
+		if val := simulatedTraffic[dest]; val > 0.0 {
+			addSimulatedTraffic(serviceNode, dest, p, val)
+		}
+	}
+}
+
+// splitTraffic splits val among dests proportionally to their current traffic, or evenly if they have none
+func splitTraffic(val float64, dests []*graph.Node, current map[*graph.Node]float64) map[*graph.Node]float64 {
+	result := make(map[*graph.Node]float64)
+	if len(dests) == 0 {
+		return result
+	}
+	currentTotal := 0.0
+	seen := make(map[*graph.Node]bool)
+	var unique []*graph.Node
+	for _, dest := range dests {
+		if !seen[dest] {
+			seen[dest] = true
+			unique = append(unique, dest)
+			currentTotal += current[dest]
+		}
+	}
+	for _, dest := range unique {
+		if currentTotal > 0.0 {
+			result[dest] += val * current[dest] / currentTotal
+		} else {
+			result[dest] += val / float64(len(unique))
+		}
+	}
+	return result
+}
+
+// forwardSimulatedTraffic scales the outgoing traffic of a service node receiving additional traffic, the
+// additional traffic follows the current routing of the service. Without current traffic, the additional
+// traffic is split evenly among the workloads of the service.
+func forwardSimulatedTraffic(trafficMap graph.TrafficMap, serviceNode *graph.Node, p graph.Protocol, val float64, s Simulation) {
+	in := getRate(serviceNode.Metadata, inRate(p, graph.MetadataKey(p.Name)))
+	if in == 0.0 {
+		workloads := s.Workloads[SimulationServiceKey(serviceNode.Namespace, serviceNode.Service)]
+		for _, w := range workloads {
+			dest := addSimulationWorkloadNode(trafficMap, serviceNode, w, s.GraphType)
+			addSimulatedTraffic(serviceNode, dest, p, val/float64(len(workloads)))
+		}
+		return
+	}
+	for _, e := range serviceNode.Edges {
+		if e.Metadata[graph.ProtocolKey] == p.Name {
+			scaleEdgeTraffic(e, p, (in+val)/in)
+		}
+	}
+}
+
+// scaleEdgeTraffic multiplies the edge rates by factor, updating the node rates accordingly
+func scaleEdgeTraffic(e *graph.Edge, p graph.Protocol, factor float64) {
+	for _, r := range p.EdgeRates {
+		if r.IsPercentErr || r.IsPercentReq {
+			continue
+		}
+		val, ok := e.Metadata[r.Name]
+		if !ok {
+			continue
+		}
+		delta := val.(float64)*factor - val.(float64)
+		e.Metadata[r.Name] = val.(float64) + delta
+		addRate(e.Dest.Metadata, inRate(p, r.Name), delta)
+		if r.IsTotal {
+			addRate(e.Source.Metadata, graph.MetadataKey(p.Name+"Out"), delta)
+		}
+	}
+}
+
+// addSimulatedTraffic adds val to the total rate of the source to dest edge, adding the edge if necessary
+func addSimulatedTraffic(source, dest *graph.Node, p graph.Protocol, val float64) {
+	var edge *graph.Edge
+	for _, e := range source.Edges {
+		if e.Dest == dest && e.Metadata[graph.ProtocolKey] == p.Name {
+			edge = e
+			break
+		}
+	}
+	if edge == nil {
+		edge = source.AddEdge(dest)
+		edge.Metadata[graph.ProtocolKey] = p.Name
+	}
+	addRate(edge.Metadata, totalRate(p), val)
+	addRate(dest.Metadata, inRate(p, totalRate(p)), val)
+	addRate(source.Metadata, graph.MetadataKey(p.Name+"Out"), val)
+}
+
+func addSimulationServiceNode(trafficMap graph.TrafficMap, cluster, namespace, service string) *graph.Node {
+	id, _ := graph.Id(cluster, namespace, service, "", "", "", "", graph.GraphTypeWorkload)
+	if n, ok := trafficMap[id]; ok {
+		return n
+	}
+	n := graph.NewNode(cluster, namespace, service, "", "", "", "", graph.GraphTypeWorkload)
+	trafficMap[id] = &n
+	return &n
+}
+
+func addSimulationWorkloadNode(trafficMap graph.TrafficMap, serviceNode *graph.Node, w SimulationWorkload, graphType string) *graph.Node {
+	id, _ := graph.Id(serviceNode.Cluster, serviceNode.Namespace, "", serviceNode.Namespace, w.Name, w.App, w.Version, graphType)
+	if n, ok := trafficMap[id]; ok {
+		return n
+	}
+	n := graph.NewNode(serviceNode.Cluster, serviceNode.Namespace, "", serviceNode.Namespace, w.Name, w.App, w.Version, graphType)
+	trafficMap[id] = &n
+	return &n
+}
+
+func matchSimulationRoute(routes []simulationRoute, source *graph.Node) (simulationRoute, bool) {
+	for _, route := range routes {
+		if len(route.matches) == 0 {
+			return route, true
+		}
+		for _, match := range route.matches {
+			if simulationMatchApplies(match, source) {
+				return route, true
+			}
+		}
+	}
+	return simulationRoute{}, false
+}
+
+// simulationMatchApplies returns true if the match applies to all of the traffic from source
+func simulationMatchApplies(match map[string]interface{}, source *graph.Node) bool {
+	cfg := config.Get()
+	for field, value := range match {
+		switch field {
+		case "name", "ignoreUriCase":
+			// no effect on matching
+		case "sourceNamespace":
+			if value != source.Namespace {
+				return false
+			}
+		case "sourceLabels":
+			labels, ok := value.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			for k, v := range labels {
+				switch k {
+				case cfg.IstioLabels.AppLabelName:
+					if v != source.App {
+						return false
+					}
+				case cfg.IstioLabels.VersionLabelName:
+					if v != source.Version {
+						return false
+					}
+				default:
+					return false
+				}
+			}
+		case "gateways":
+			// an empty list defers to the VirtualService gateways
+			if gateways, _ := value.([]interface{}); len(gateways) > 0 && !simulationGatewaysApply(gateways, source) {
+				return false
+			}
+		default:
+			// a request attribute, unknown for observed traffic
+			return false
+		}
+	}
+	return true
+}
+
+// simulationGatewaysApply returns true if the gateways, a VirtualService or route match gateways field, apply to
+// the traffic from source. The mesh gateway, the default when no gateway is listed, applies to the sidecars. An
+// ingress gateway applies to the traffic of the ingress gateway nodes it configures.
+func simulationGatewaysApply(gateways interface{}, source *graph.Node) bool {
+	gatewayList, _ := gateways.([]interface{})
+	if len(gatewayList) == 0 {
+		gatewayList = []interface{}{"mesh"}
+	}
+	ingressGateways, isIngressGateway := source.Metadata[graph.IsIngressGateway].(graph.GatewaysMetadata)
+	for _, g := range gatewayList {
+		gateway, _ := g.(string)
+		if gateway == "mesh" {
+			if !isIngressGateway {
+				return true
+			}
+			continue
+		}
+		// gateways are referred as <namespace>/<name> or <name>, the ingress gateway nodes know only the name
+		if i := strings.LastIndex(gateway, "/"); i >= 0 {
+			gateway = gateway[i+1:]
+		}
+		if _, ok := ingressGateways[gateway]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSimulationRoutes(routes interface{}, s Simulation) []simulationRoute {
+	routeList, ok := routes.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := []simulationRoute{}
+	for _, r := range routeList {
+		route, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parsed := simulationRoute{}
+		if matches, ok := route["match"].([]interface{}); ok {
+			for _, m := range matches {
+				if match, ok := m.(map[string]interface{}); ok {
+					parsed.matches = append(parsed.matches, match)
+				}
+			}
+		}
+
+		totalWeight := 0.0
+		if destinations, ok := route["route"].([]interface{}); ok {
+			for _, d := range destinations {
+				destination, ok := d.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				target, ok := destination["destination"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				host, _ := target["host"].(string)
+				namespace, service, ok := s.resolveHost(host)
+				if !ok {
+					continue
+				}
+				subset, _ := target["subset"].(string)
+				weight, _ := destination["weight"].(float64)
+				totalWeight += weight
+				parsed.destinations = append(parsed.destinations, simulationDestination{namespace: namespace, service: service, subset: subset, weight: weight})
+			}
+		}
+		// a single destination may omit the weight
+		for i := range parsed.destinations {
+			if totalWeight > 0.0 {
+				parsed.destinations[i].weight /= totalWeight
+			} else {
+				parsed.destinations[i].weight = 1.0 / float64(len(parsed.destinations))
+			}
+		}
+		result = append(result, parsed)
+	}
+	return result
+}
+
+// RouteNamespaces returns the namespaces of the VirtualService hosts and route destinations, sorted. The
+// ClusterNamespaces and Namespace must be set.
+func (s Simulation) RouteNamespaces() []string {
+	found := make(map[string]bool)
+	for _, host := range s.VirtualService.Spec.Hosts {
+		if namespace, _, ok := s.resolveHost(host); ok {
+			found[namespace] = true
+		}
+	}
+	for _, routes := range []interface{}{s.VirtualService.Spec.Http, s.VirtualService.Spec.Tcp} {
+		for _, route := range parseSimulationRoutes(routes, s) {
+			for _, d := range route.destinations {
+				found[d.namespace] = true
+			}
+		}
+	}
+	namespaces := make([]string, 0, len(found))
+	for namespace := range found {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// resolveHost returns the namespace and name of the service for a host, false if it is not a service host
+func (s Simulation) resolveHost(host string) (namespace, service string, ok bool) {
+	if host == "" || strings.Contains(host, "*") {
+		return "", "", false
+	}
+	h := kubernetes.GetHost(host, s.Namespace, "", s.ClusterNamespaces)
+	if !h.CompleteInput {
+		return "", "", false
+	}
+	return h.Namespace, h.Service, true
+}
+
+func labelsMatch(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// totalRate returns the protocol's edge total rate
+func totalRate(p graph.Protocol) graph.MetadataKey {
+	for _, r := range p.EdgeRates {
+		if r.IsTotal {
+			return r.Name
+		}
+	}
+	return graph.MetadataKey(p.Name)
+}
+
+// inRate returns the node incoming rate corresponding to an edge rate, e.g. httpIn5xx for http5xx
+func inRate(p graph.Protocol, edgeRate graph.MetadataKey) graph.MetadataKey {
+	return graph.MetadataKey(p.Name + "In" + strings.TrimPrefix(string(edgeRate), p.Name))
+}
+
+func addRate(md graph.Metadata, k graph.MetadataKey, delta float64) {
+	val := getRate(md, k) + delta
+	if val < 0.0 {
+		// rounding
+		val = 0.0
+	}
+	md[k] = val
+}
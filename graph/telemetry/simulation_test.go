@@ -0,0 +1,274 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/models"
+)
+
+func newSimulationNode(trafficMap graph.TrafficMap, service, workload, app, version string) *graph.Node {
+	n := graph.NewNode("east", "bookinfo", service, "bookinfo", workload, app, version, graph.GraphTypeVersionedApp)
+	trafficMap[n.ID] = &n
+	return &n
+}
+
+func newSimulation(http []interface{}) Simulation {
+	vs := models.VirtualService{}
+	vs.Spec.Hosts = []string{"reviews"}
+	vs.Spec.Http = http
+	return Simulation{
+		GraphType: graph.GraphTypeVersionedApp,
+		Namespace: "bookinfo",
+		Subsets: map[string]map[string]map[string]string{
+			"bookinfo/reviews": {
+				"v1": {"version": "v1"},
+				"v2": {"version": "v2"},
+			},
+		},
+		VirtualService: vs,
+		Workloads: map[string][]SimulationWorkload{
+			"bookinfo/reviews": {
+				{App: "reviews", Labels: map[string]string{"app": "reviews", "version": "v1"}, Name: "reviews-v1", Version: "v1"},
+				{App: "reviews", Labels: map[string]string{"app": "reviews", "version": "v2"}, Name: "reviews-v2", Version: "v2"},
+			},
+		},
+	}
+}
+
+func weightedRoute(weights ...interface{}) map[string]interface{} {
+	destinations := []interface{}{}
+	for i := 0; i < len(weights); i += 2 {
+		destinations = append(destinations, map[string]interface{}{
+			"destination": map[string]interface{}{"host": "reviews", "subset": weights[i]},
+			"weight":      weights[i+1],
+		})
+	}
+	return map[string]interface{}{"route": destinations}
+}
+
+// productpage -> reviews (service) -> reviews-v1, all of the traffic goes to v1
+func newSimulationTrafficMap() (graph.TrafficMap, *graph.Node, *graph.Node, *graph.Node) {
+	trafficMap := graph.NewTrafficMap()
+	productpage := newSimulationNode(trafficMap, "", "productpage-v1", "productpage", "v1")
+	reviews := newSimulationNode(trafficMap, "reviews", "", "", "")
+	reviewsV1 := newSimulationNode(trafficMap, "", "reviews-v1", "reviews", "v1")
+	addHTTPEdge(productpage, reviews, 10.0, 1.0)
+	addHTTPEdge(reviews, reviewsV1, 10.0, 1.0)
+	return trafficMap, productpage, reviews, reviewsV1
+}
+
+func TestSimulateVirtualServiceWeights(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap, _, reviews, reviewsV1 := newSimulationTrafficMap()
+	simulated := SimulateVirtualService(trafficMap, newSimulation([]interface{}{weightedRoute("v1", 80.0, "v2", 20.0)}))
+
+	// the original traffic map is not modified
+	assert.Equal(3, len(trafficMap))
+	assert.Equal(10.0, trafficMap[reviewsV1.ID].Metadata["httpIn"])
+
+	assert.Equal(4, len(simulated))
+	reviewsV2, ok := simulated["vapp_east_bookinfo_reviews-v2"]
+	assert.True(ok)
+
+	simulatedReviews := simulated[reviews.ID]
+	assert.Equal(2, len(simulatedReviews.Edges))
+	v1Edge := simulatedReviews.Edges[0]
+	assert.Equal(reviewsV1.ID, v1Edge.Dest.ID)
+	assert.InDelta(8.0, v1Edge.Metadata["http"], 0.0001)
+	assert.InDelta(0.8, v1Edge.Metadata["http5xx"], 0.0001)
+	assert.InDelta(8.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+	assert.InDelta(0.8, simulated[reviewsV1.ID].Metadata["httpIn5xx"], 0.0001)
+
+	v2Edge := simulatedReviews.Edges[1]
+	assert.Equal(reviewsV2, v2Edge.Dest)
+	assert.Equal("http", v2Edge.Metadata[graph.ProtocolKey])
+	assert.InDelta(2.0, v2Edge.Metadata["http"], 0.0001)
+	assert.InDelta(2.0, reviewsV2.Metadata["httpIn"], 0.0001)
+	assert.InDelta(10.0, simulatedReviews.Metadata["httpOut"], 0.0001)
+
+	// the incoming traffic is unchanged
+	assert.InDelta(10.0, simulatedReviews.Metadata["httpIn"], 0.0001)
+
+	// the diff provides the deltas
+	diff := DiffTrafficMaps(simulated, trafficMap)
+	assert.Equal(graph.DiffAdded, diffOf(diff[reviewsV2.ID].Metadata).Status)
+	assert.InDelta(-2.0, diffOf(v1Edge.Metadata).RequestRateDelta, 0.0001)
+}
+
+func TestSimulateVirtualServiceMatches(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap, _, reviews, reviewsV1 := newSimulationTrafficMap()
+
+	// the header match can't be evaluated, the traffic falls through to the next route
+	headerRoute := weightedRoute("v2", 100.0)
+	headerRoute["match"] = []interface{}{map[string]interface{}{"headers": map[string]interface{}{"end-user": map[string]interface{}{"exact": "jason"}}}}
+	simulated := SimulateVirtualService(trafficMap, newSimulation([]interface{}{headerRoute, weightedRoute("v1", 100.0)}))
+	assert.Equal(3, len(simulated))
+	assert.InDelta(10.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+
+	// the source labels match
+	sourceRoute := weightedRoute("v2", 100.0)
+	sourceRoute["match"] = []interface{}{map[string]interface{}{"sourceLabels": map[string]interface{}{"app": "productpage"}}}
+	simulated = SimulateVirtualService(trafficMap, newSimulation([]interface{}{sourceRoute, weightedRoute("v1", 100.0)}))
+	assert.Equal(4, len(simulated))
+	assert.InDelta(0.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+	assert.InDelta(10.0, simulated["vapp_east_bookinfo_reviews-v2"].Metadata["httpIn"], 0.0001)
+
+	// no route matches, the traffic is left as-is
+	simulated = SimulateVirtualService(trafficMap, newSimulation([]interface{}{headerRoute}))
+	assert.Equal(3, len(simulated))
+	assert.InDelta(10.0, simulated[reviews.ID].Edges[0].Metadata["http"], 0.0001)
+}
+
+func TestSimulateVirtualServiceRedirect(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap, productpage, reviews, reviewsV1 := newSimulationTrafficMap()
+	route := map[string]interface{}{
+		"route": []interface{}{
+			map[string]interface{}{"destination": map[string]interface{}{"host": "reviews"}, "weight": 50.0},
+			map[string]interface{}{"destination": map[string]interface{}{"host": "ratings.bookinfo.svc.cluster.local"}, "weight": 50.0},
+		},
+	}
+	simulated := SimulateVirtualService(trafficMap, newSimulation([]interface{}{route}))
+
+	ratings, ok := simulated["svc_east_bookinfo_ratings"]
+	assert.True(ok)
+	assert.Equal(graph.NodeTypeService, ratings.NodeType)
+
+	simulatedProductpage := simulated[productpage.ID]
+	assert.Equal(2, len(simulatedProductpage.Edges))
+	assert.InDelta(5.0, simulatedProductpage.Edges[0].Metadata["http"], 0.0001)
+	assert.Equal(ratings, simulatedProductpage.Edges[1].Dest)
+	assert.InDelta(5.0, simulatedProductpage.Edges[1].Metadata["http"], 0.0001)
+	assert.InDelta(10.0, simulatedProductpage.Metadata["httpOut"], 0.0001)
+
+	assert.InDelta(5.0, simulated[reviews.ID].Metadata["httpIn"], 0.0001)
+	assert.InDelta(5.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+}
+
+// ingressgateway -> reviews (service), productpage -> reviews (service), reviews -> reviews-v1
+func newSimulationGatewayTrafficMap() (graph.TrafficMap, *graph.Node, *graph.Node, *graph.Node) {
+	trafficMap := graph.NewTrafficMap()
+	gateway := graph.NewNode("east", "istio-system", "", "istio-system", "istio-ingressgateway", "istio-ingressgateway", "latest", graph.GraphTypeVersionedApp)
+	gateway.Metadata[graph.IsIngressGateway] = graph.GatewaysMetadata{"bookinfo-gateway": []string{"*"}}
+	trafficMap[gateway.ID] = &gateway
+	productpage := newSimulationNode(trafficMap, "", "productpage-v1", "productpage", "v1")
+	reviews := newSimulationNode(trafficMap, "reviews", "", "", "")
+	reviewsV1 := newSimulationNode(trafficMap, "", "reviews-v1", "reviews", "v1")
+	addHTTPEdge(&gateway, reviews, 10.0, 0.0)
+	addHTTPEdge(productpage, reviews, 10.0, 0.0)
+	addHTTPEdge(reviews, reviewsV1, 20.0, 0.0)
+	return trafficMap, &gateway, productpage, reviewsV1
+}
+
+func TestSimulateVirtualServiceGateways(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap, _, _, reviewsV1 := newSimulationGatewayTrafficMap()
+
+	// bound to the ingress gateway only, the sidecar traffic is left as-is
+	s := newSimulation([]interface{}{weightedRoute("v2", 100.0)})
+	s.VirtualService.Spec.Gateways = []interface{}{"bookinfo-gateway"}
+	simulated := SimulateVirtualService(trafficMap, s)
+	assert.InDelta(10.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+	assert.InDelta(10.0, simulated["vapp_east_bookinfo_reviews-v2"].Metadata["httpIn"], 0.0001)
+
+	// bound to an unrelated gateway, no traffic is affected
+	s.VirtualService.Spec.Gateways = []interface{}{"other-gateway"}
+	simulated = SimulateVirtualService(trafficMap, s)
+	assert.InDelta(20.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+	_, ok := simulated["vapp_east_bookinfo_reviews-v2"]
+	assert.False(ok)
+
+	// bound to the mesh and the ingress gateway, all of the traffic is affected
+	s.VirtualService.Spec.Gateways = []interface{}{"mesh", "istio-system/bookinfo-gateway"}
+	simulated = SimulateVirtualService(trafficMap, s)
+	assert.InDelta(0.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+	assert.InDelta(20.0, simulated["vapp_east_bookinfo_reviews-v2"].Metadata["httpIn"], 0.0001)
+
+	// the match gateways select the ingress gateway traffic, the mesh traffic falls through to the next route
+	gatewayRoute := weightedRoute("v2", 100.0)
+	gatewayRoute["match"] = []interface{}{map[string]interface{}{"gateways": []interface{}{"bookinfo-gateway"}}}
+	s = newSimulation([]interface{}{gatewayRoute, weightedRoute("v1", 100.0)})
+	s.VirtualService.Spec.Gateways = []interface{}{"mesh", "bookinfo-gateway"}
+	simulated = SimulateVirtualService(trafficMap, s)
+	assert.InDelta(10.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+	assert.InDelta(10.0, simulated["vapp_east_bookinfo_reviews-v2"].Metadata["httpIn"], 0.0001)
+
+	// without gateways the VirtualService applies to the mesh only
+	s = newSimulation([]interface{}{weightedRoute("v2", 100.0)})
+	simulated = SimulateVirtualService(trafficMap, s)
+	assert.InDelta(10.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+	assert.InDelta(10.0, simulated["vapp_east_bookinfo_reviews-v2"].Metadata["httpIn"], 0.0001)
+}
+
+func TestSimulateVirtualServiceRedirectNewService(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap, _, _, reviewsV1 := newSimulationTrafficMap()
+	route := map[string]interface{}{
+		"route": []interface{}{
+			map[string]interface{}{"destination": map[string]interface{}{"host": "reviews"}, "weight": 50.0},
+			map[string]interface{}{"destination": map[string]interface{}{"host": "reviews-canary"}, "weight": 50.0},
+		},
+	}
+	s := newSimulation([]interface{}{route})
+	s.Workloads["bookinfo/reviews-canary"] = []SimulationWorkload{
+		{App: "reviews-canary", Name: "reviews-canary-v1", Version: "v1"},
+		{App: "reviews-canary", Name: "reviews-canary-v2", Version: "v2"},
+	}
+	simulated := SimulateVirtualService(trafficMap, s)
+
+	// the canary service receives no traffic yet, its traffic is split evenly among its workloads
+	canary, ok := simulated["svc_east_bookinfo_reviews-canary"]
+	assert.True(ok)
+	assert.Equal(2, len(canary.Edges))
+	assert.InDelta(5.0, canary.Metadata["httpOut"], 0.0001)
+	for _, id := range []string{"vapp_east_bookinfo_reviews-canary-v1", "vapp_east_bookinfo_reviews-canary-v2"} {
+		n, ok := simulated[id]
+		assert.True(ok)
+		assert.InDelta(2.5, n.Metadata["httpIn"], 0.0001)
+	}
+	assert.InDelta(5.0, simulated[reviewsV1.ID].Metadata["httpIn"], 0.0001)
+}
+
+func TestSimulationRouteNamespaces(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	s := newSimulation([]interface{}{
+		weightedRoute("v1", 100.0),
+		map[string]interface{}{"route": []interface{}{map[string]interface{}{"destination": map[string]interface{}{"host": "reviews.canary.svc.cluster.local"}}}},
+	})
+	assert.Equal(t, []string{"bookinfo", "canary"}, s.RouteNamespaces())
+}
+
+func TestParseSimulationRoutes(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	s := newSimulation(nil)
+	routes := parseSimulationRoutes([]interface{}{
+		map[string]interface{}{"route": []interface{}{map[string]interface{}{"destination": map[string]interface{}{"host": "reviews.bookinfo", "subset": "v1"}}}},
+		weightedRoute("v1", 75.0, "v2", 25.0),
+		// service entries are not supported
+		map[string]interface{}{"route": []interface{}{map[string]interface{}{"destination": map[string]interface{}{"host": "www.google.com"}}}},
+	}, s)
+	assert.Equal(3, len(routes))
+	assert.Equal([]simulationDestination{{namespace: "bookinfo", service: "reviews", subset: "v1", weight: 1.0}}, routes[0].destinations)
+	assert.Equal(0.75, routes[1].destinations[0].weight)
+	assert.Equal(0.25, routes[1].destinations[1].weight)
+	assert.Empty(routes[2].destinations)
+
+	assert.Nil(parseSimulationRoutes(nil, s))
+}
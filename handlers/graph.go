@@ -14,31 +14,65 @@ package handlers
 //
 // The current Handlers:
 //   GraphNamespaces: Generate a graph for one or more requested namespaces.
+//   GraphNamespacesEntryPoints: Generate a graph for one or more requested namespaces, limited to the paths through the ingress gateways and egress nodes.
+//   GraphNamespacesDiff: Generate a graph for one or more requested namespaces, compared to a baseline time period.
 //   GraphNode:       Generate a graph for a specific node, detailing the immediate incoming and outgoing traffic.
+//   GraphDependencies: Analyze the transitive dependencies of a specific node, and its dependency paths.
+//   GraphNamespacesStream: Stream namespaces graph updates, as Server-Sent Events providing graph deltas.
+//   GraphSimulation: Generate a namespace graph simulating the traffic shifting of a proposed VirtualService.
+//   GraphSnapshotSave: Save a namespaces graph as a named snapshot, see also GraphSnapshot[List|Delete].
 //
 // The handlers accept the following query parameters (see notes below)
 //   appenders:       Comma-separated list of TelemetryVendor-specific appenders to run. (default: all)
-//   configVendor:    default: cytoscape
+//   compareTime:     Unix time (seconds) for the baseline of a diff graph (default queryTime-duration)
+//   configVendor:    cytoscape | dot | graphml | jgf (default: cytoscape)
 //   duration:        time.Duration indicating desired query range duration, (default: 10m)
+//   find:            Find expression, matching nodes or edges are flagged with isFind (default: none)
 //   graphType:       Determines how to present the telemetry data. app | service | versionedApp | workload (default: workload)
-//   boxBy:           If supported by vendor, visually box by a specified node attribute (default: none)
+//   boxBy:           If supported by vendor, visually box by a specified node attribute, label:<labelName> or workloadGroup (default: none)
+//   hide:            Hide expression, matching nodes or edges are removed from the graph (default: none)
 //   namespaces:      Comma-separated list of namespace names to use in the graph. Will override namespace path param
 //   queryTime:       Unix time (seconds) for query such that range is queryTime-duration..queryTime (default now)
-//   TelemetryVendor: default: istio
+//   refreshInterval: time.Duration between streamed graph updates (default: 15s, minimum: 5s)
+//   timeSeries:      If true, namespace graphs provide a request traffic time series for nodes and edges (default: false)
+//   timeSeriesStep:  time.Duration between time series points (default: duration/30, at least 1m)
+//   TelemetryVendor: istio | jaeger (default: istio), the jaeger vendor builds namespaces graphs from traces
 //
 //  Note: some handlers may ignore some query parameters.
 //  Note: vendors may support additional, vendor-specific query parameters.
 //
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"runtime/debug"
+	"sync"
+	"time"
 
+	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/api"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/snapshot"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
+)
+
+const (
+	graphStreamDefaultRefreshInterval = 15 * time.Second
+	graphStreamMinRefreshInterval     = 5 * time.Second
+	// a graph update is not started unless it has this long to complete before the server write timeout
+	graphStreamWriteMargin = 10 * time.Second
 )
 
+var graphStreams chan struct{}
+var graphStreamsOnce sync.Once
+
 // GraphNamespaces is a REST http.HandlerFunc handling graph generation for 1 or more namespaces
 func GraphNamespaces(w http.ResponseWriter, r *http.Request) {
 	defer handlePanic(w)
@@ -52,6 +86,56 @@ func GraphNamespaces(w http.ResponseWriter, r *http.Request) {
 	respond(w, code, payload)
 }
 
+// GraphNamespacesEntryPoints is a REST http.HandlerFunc handling entry point graph generation for 1 or more namespaces
+func GraphNamespacesEntryPoints(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewOptions(r)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GraphNamespacesEntryPoints(business, o)
+	respond(w, code, payload)
+}
+
+// GraphNamespacesDiff is a REST http.HandlerFunc handling diff graph generation for 1 or more namespaces
+func GraphNamespacesDiff(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewDiffOptions(r)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GraphNamespacesDiff(business, o)
+	respond(w, code, payload)
+}
+
+// GraphSimulation is a REST http.HandlerFunc handling traffic simulation graph generation. The request
+// body is the proposed VirtualService, its traffic shifting is applied to the namespace graph.
+func GraphSimulation(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewSimulationOptions(r)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		graph.BadRequest("Simulation request with bad body: " + err.Error())
+	}
+	vs := models.VirtualService{}
+	if err := json.Unmarshal(body, &vs); err != nil {
+		graph.BadRequest("Invalid VirtualService: " + err.Error())
+	}
+	vs.Metadata.Namespace = mux.Vars(r)["namespace"]
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GraphSimulation(business, o, vs)
+	respond(w, code, payload)
+}
+
 // GraphNode is a REST http.HandlerFunc handling node-detail graph config generation.
 func GraphNode(w http.ResponseWriter, r *http.Request) {
 	defer handlePanic(w)
@@ -65,6 +149,331 @@ func GraphNode(w http.ResponseWriter, r *http.Request) {
 	respond(w, code, payload)
 }
 
+// GraphDependencies is a REST http.HandlerFunc handling node dependency analysis.
+func GraphDependencies(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewDependenciesOptions(r)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GraphDependencies(business, o)
+	respond(w, code, payload)
+}
+
+// GraphNamespacesStream is a REST http.HandlerFunc streaming namespaces graph updates as Server-Sent Events.
+// The graph is regenerated every refreshInterval. The first event ("graph") provides the full cytoscape
+// config, subsequent events ("delta") provide only the nodes and edges changed since the previous event.
+// The stream ends after the configured graph.stream_max_duration, clients are expected to reconnect (the
+// EventSource default), receiving a new full graph. When the connection write deadline can't be cleared, the
+// stream also ends before the server write timeout.
+func GraphNamespacesStream(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewOptions(r)
+	if o.ConfigVendor != graph.VendorCytoscape {
+		graph.BadRequest(fmt.Sprintf("ConfigVendor [%s] not supported for graph streams", o.ConfigVendor))
+	}
+
+	refreshInterval := graphStreamDefaultRefreshInterval
+	if refreshIntervalString := r.URL.Query().Get("refreshInterval"); refreshIntervalString != "" {
+		var err error
+		refreshInterval, err = time.ParseDuration(refreshIntervalString)
+		if err != nil {
+			graph.BadRequest(fmt.Sprintf("Invalid refreshInterval [%s]", refreshIntervalString))
+		}
+		if refreshInterval < graphStreamMinRefreshInterval {
+			graph.BadRequest(fmt.Sprintf("Invalid refreshInterval [%s], must be at least [%s]", refreshIntervalString, graphStreamMinRefreshInterval))
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		graph.Error("Streaming is not supported by the connection")
+	}
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	if !acquireGraphStream() {
+		graph.Panic("Too many open graph streams, try again later", http.StatusServiceUnavailable)
+	}
+	defer releaseGraphStream()
+
+	var deadline time.Time
+	if maxDuration := config.Get().Graph.StreamMaxDuration; maxDuration > 0 {
+		deadline = time.Now().Add(time.Duration(maxDuration) * time.Second)
+	}
+	if !clearWriteDeadline(w) {
+		if writeTimeout := time.Duration(config.Get().Server.WriteTimeout) * time.Second; writeTimeout > 0 {
+			if writeDeadline := time.Now().Add(writeTimeout - graphStreamWriteMargin); deadline.IsZero() || writeDeadline.Before(deadline) {
+				deadline = writeDeadline
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", refreshInterval.Milliseconds())
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	var previous *cytoscape.Config
+	for {
+		// once streaming the response can no longer fail, errors are reported as events
+		current, err := graphStreamConfig(business, o)
+		switch {
+		case err != nil:
+			writeGraphStreamEvent(w, "error", o.ConfigOptions.QueryTime, err.Error())
+		case previous == nil:
+			writeGraphStreamEvent(w, "graph", current.Timestamp, current)
+		default:
+			if delta := cytoscape.NewDelta(*previous, current); !delta.IsEmpty() {
+				writeGraphStreamEvent(w, "delta", delta.Timestamp, delta)
+			} else {
+				// keep the connection alive
+				fmt.Fprintf(w, ": no changes\n\n")
+			}
+		}
+		flusher.Flush()
+		if err != nil {
+			return
+		}
+		previous = &current
+
+		// don't wait for an update that can't be sent before the deadline
+		if !deadline.IsZero() && time.Now().Add(refreshInterval).After(deadline) {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		queryTime := time.Now().Unix()
+		o.ConfigOptions.QueryTime = queryTime
+		o.TelemetryOptions.QueryTime = queryTime
+	}
+}
+
+// graphStreamConfig generates the streamed graph, returning an error instead of panicking
+func graphStreamConfig(business *business.Layer, o graph.Options) (result cytoscape.Config, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case graph.Response:
+				err = fmt.Errorf("%s", r.Message)
+			default:
+				log.Errorf("Graph stream update failed: %v: %s", r, debug.Stack())
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	_, config := api.GraphNamespaces(business, o)
+	return config.(cytoscape.Config), nil
+}
+
+func writeGraphStreamEvent(w http.ResponseWriter, event string, id int64, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Errorf("Failed to marshal graph stream event [%s]: %v", event, err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\nid: %d\ndata: %s\n\n", event, id, data)
+}
+
+// clearWriteDeadline removes the connection write deadline, set from the server write timeout, so that the
+// stream can outlive it. It returns false when neither the response writer nor the writers it wraps support it.
+func clearWriteDeadline(w http.ResponseWriter) bool {
+	for {
+		switch rw := w.(type) {
+		case interface{ SetWriteDeadline(time.Time) error }:
+			return rw.SetWriteDeadline(time.Time{}) == nil
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// acquireGraphStream returns false if the maximum number of concurrent graph streams are already open
+func acquireGraphStream() bool {
+	graphStreamsOnce.Do(func() {
+		if maxConcurrent := config.Get().Graph.StreamMaxConcurrent; maxConcurrent > 0 {
+			graphStreams = make(chan struct{}, maxConcurrent)
+		}
+	})
+	if graphStreams == nil {
+		internalmetrics.GetGraphStreamsMetric().Inc()
+		return true
+	}
+	select {
+	case graphStreams <- struct{}{}:
+		internalmetrics.GetGraphStreamsMetric().Inc()
+		return true
+	default:
+		return false
+	}
+}
+
+func releaseGraphStream() {
+	if graphStreams != nil {
+		<-graphStreams
+	}
+	internalmetrics.GetGraphStreamsMetric().Dec()
+}
+
+// GraphSnapshotSave is a REST http.HandlerFunc saving a namespaces graph as a named snapshot. The graph is
+// generated like for GraphNamespaces, always in the cytoscape format.
+func GraphSnapshotSave(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	name := mux.Vars(r)["snapshot"]
+	if err := snapshot.ValidateName(name); err != nil {
+		graph.BadRequest(err.Error())
+	}
+
+	o := graph.NewOptions(r)
+	if o.ConfigVendor != graph.VendorCytoscape {
+		graph.BadRequest(fmt.Sprintf("ConfigVendor [%s] not supported for graph snapshots", o.ConfigVendor))
+	}
+
+	store := getGraphSnapshotStore()
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	_, config := api.GraphNamespaces(business, o)
+	s := &snapshot.Snapshot{
+		Info: snapshot.Info{
+			Created: time.Now(),
+			Name:    name,
+			Options: snapshot.NewOptions(o),
+			User:    r.Header.Get("Kiali-User"),
+		},
+		Graph: config.(cytoscape.Config),
+	}
+	checkGraphSnapshotError(store.Save(s), name)
+
+	audit(r, fmt.Sprintf("SAVE graph snapshot [%s] Namespaces: %v", name, s.Options.Namespaces))
+	RespondWithJSON(w, http.StatusCreated, s.Info)
+}
+
+// GraphSnapshotList is a REST http.HandlerFunc listing the saved graph snapshots, newest first. Only the
+// snapshots of namespaces accessible to the user are listed.
+func GraphSnapshotList(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	store := getGraphSnapshotStore()
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	infos, err := store.List()
+	graph.CheckError(err)
+
+	accessibleNamespaces := getGraphSnapshotAccessibleNamespaces(business)
+	result := []snapshot.Info{}
+	for _, info := range infos {
+		if isGraphSnapshotAccessible(info, accessibleNamespaces) {
+			result = append(result, info)
+		}
+	}
+	RespondWithJSON(w, http.StatusOK, result)
+}
+
+// GraphSnapshot is a REST http.HandlerFunc returning the cytoscape config of a saved graph snapshot
+func GraphSnapshot(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	s := getGraphSnapshot(r)
+	respond(w, http.StatusOK, s.Graph)
+}
+
+// GraphSnapshotDelete is a REST http.HandlerFunc deleting a saved graph snapshot
+func GraphSnapshotDelete(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	s := getGraphSnapshot(r)
+
+	store := getGraphSnapshotStore()
+	checkGraphSnapshotError(store.Delete(s.Name), s.Name)
+
+	audit(r, fmt.Sprintf("DELETE graph snapshot [%s]", s.Name))
+	RespondWithCode(w, http.StatusOK)
+}
+
+// getGraphSnapshot returns the requested snapshot, if the user can access all of its namespaces
+func getGraphSnapshot(r *http.Request) *snapshot.Snapshot {
+	name := mux.Vars(r)["snapshot"]
+	if err := snapshot.ValidateName(name); err != nil {
+		graph.BadRequest(err.Error())
+	}
+
+	store := getGraphSnapshotStore()
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	s, err := store.Get(name)
+	checkGraphSnapshotError(err, name)
+
+	// don't reveal the existence of inaccessible snapshots
+	if !isGraphSnapshotAccessible(s.Info, getGraphSnapshotAccessibleNamespaces(business)) {
+		checkGraphSnapshotError(snapshot.ErrNotFound, name)
+	}
+	return s
+}
+
+func getGraphSnapshotStore() snapshot.Store {
+	store, err := snapshot.GetStore()
+	if err == snapshot.ErrDisabled {
+		graph.Panic(err.Error(), http.StatusServiceUnavailable)
+	}
+	graph.CheckError(err)
+	return store
+}
+
+func getGraphSnapshotAccessibleNamespaces(business *business.Layer) map[string]bool {
+	namespaces, err := business.Namespace.GetNamespaces()
+	graph.CheckError(err)
+
+	result := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		result[namespace.Name] = true
+	}
+	return result
+}
+
+func isGraphSnapshotAccessible(info snapshot.Info, accessibleNamespaces map[string]bool) bool {
+	for _, namespace := range info.Options.Namespaces {
+		if !accessibleNamespaces[namespace] {
+			return false
+		}
+	}
+	return true
+}
+
+func checkGraphSnapshotError(err error, name string) {
+	switch err {
+	case nil:
+		return
+	case snapshot.ErrExists:
+		graph.Panic(fmt.Sprintf("Graph snapshot [%s] already exists", name), http.StatusConflict)
+	case snapshot.ErrNotFound:
+		graph.Panic(fmt.Sprintf("Graph snapshot [%s] not found", name), http.StatusNotFound)
+	default:
+		graph.CheckError(err)
+	}
+}
+
 func handlePanic(w http.ResponseWriter) {
 	code := http.StatusInternalServerError
 	if r := recover(); r != nil {
@@ -94,6 +503,18 @@ func handlePanic(w http.ResponseWriter) {
 
 func respond(w http.ResponseWriter, code int, payload interface{}) {
 	if code == http.StatusOK {
+		// some config vendors produce non-JSON configs, return them as-is
+		if rawConfig, ok := payload.(graph.RawConfig); ok {
+			raw, err := rawConfig.Raw()
+			if err != nil {
+				RespondWithError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			w.Header().Set("Content-Type", rawConfig.ContentType())
+			w.WriteHeader(code)
+			_, _ = w.Write(raw)
+			return
+		}
 		RespondWithJSONIndent(w, code, payload)
 		return
 	}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+)
+
+// wrappingResponseWriter wraps a ResponseWriter like the middlewares do
+type wrappingResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w wrappingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func resetGraphStreams() {
+	graphStreams = nil
+	graphStreamsOnce = sync.Once{}
+}
+
+func TestAcquireGraphStream(t *testing.T) {
+	assert := assert.New(t)
+	defer resetGraphStreams()
+
+	conf := config.NewConfig()
+	conf.Graph.StreamMaxConcurrent = 2
+	config.Set(conf)
+	resetGraphStreams()
+
+	assert.True(acquireGraphStream())
+	assert.True(acquireGraphStream())
+	assert.False(acquireGraphStream())
+	releaseGraphStream()
+	assert.True(acquireGraphStream())
+	releaseGraphStream()
+	releaseGraphStream()
+
+	// the streams are unlimited when the maximum is not positive
+	conf.Graph.StreamMaxConcurrent = 0
+	config.Set(conf)
+	resetGraphStreams()
+
+	for i := 0; i < 20; i++ {
+		assert.True(acquireGraphStream())
+	}
+	for i := 0; i < 20; i++ {
+		releaseGraphStream()
+	}
+}
+
+func TestClearWriteDeadline(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !clearWriteDeadline(wrappingResponseWriter{w}) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// respond after the server write timeout
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	ts.Config.WriteTimeout = 50 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if !assert.NoError(err) {
+		return
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("ok", string(body))
+
+	assert.False(clearWriteDeadline(httptest.NewRecorder()))
+}
@@ -123,6 +123,65 @@ func ServiceHealth(w http.ResponseWriter, r *http.Request) {
 	handleHealthResponse(w, health, err)
 }
 
+// NamespaceSLOStatus is the API handler to get the SLO status of the apps, services or workloads of the given namespace
+func NamespaceSLOStatus(w http.ResponseWriter, r *http.Request) {
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	p := namespaceHealthParams{}
+	if ok, err := p.extract(r); !ok {
+		// Bad request
+		RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	status, err := business.Health.GetNamespaceSLOStatus(p.Namespace, p.Type, p.QueryTime)
+	handleHealthResponse(w, status, err)
+}
+
+// AppSLOStatus is the API handler to get the SLO status of a single app
+func AppSLOStatus(w http.ResponseWriter, r *http.Request) {
+	p := appHealthParams{}
+	p.extract(r)
+	sloStatus(w, r, p.Namespace, business.SLOKindApp, p.App, p.QueryTime)
+}
+
+// ServiceSLOStatus is the API handler to get the SLO status of a single service
+func ServiceSLOStatus(w http.ResponseWriter, r *http.Request) {
+	p := serviceHealthParams{}
+	p.extract(r)
+	sloStatus(w, r, p.Namespace, business.SLOKindService, p.Service, p.QueryTime)
+}
+
+// WorkloadSLOStatus is the API handler to get the SLO status of a single workload
+func WorkloadSLOStatus(w http.ResponseWriter, r *http.Request) {
+	p := workloadHealthParams{}
+	p.extract(r)
+	sloStatus(w, r, p.Namespace, business.SLOKindWorkload, p.Workload, p.QueryTime)
+}
+
+func sloStatus(w http.ResponseWriter, r *http.Request, namespace, kind, name string, queryTime time.Time) {
+	layer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	status, err := layer.Health.GetSLOStatus(namespace, kind, name, queryTime)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	if status == nil {
+		RespondWithError(w, http.StatusNotFound, "No SLO is defined for "+kind+" ["+namespace+"/"+name+"]")
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, status)
+}
+
 func handleHealthResponse(w http.ResponseWriter, health interface{}, err error) {
 	if err != nil {
 		handleErrorResponse(w, err)
@@ -164,7 +223,7 @@ func (p *baseHealthParams) baseExtract(r *http.Request, vars map[string]string)
 
 // namespaceHealthParams holds the path and query parameters for NamespaceHealth
 //
-// swagger:parameters namespaceHealth
+// swagger:parameters namespaceHealth namespaceSLO
 type namespaceHealthParams struct {
 	baseHealthParams
 	// The type of health, "app", "service" or "workload".
@@ -191,7 +250,7 @@ func (p *namespaceHealthParams) extract(r *http.Request) (bool, string) {
 
 // appHealthParams holds the path and query parameters for AppHealth
 //
-// swagger:parameters appHealth
+// swagger:parameters appHealth appSLO
 type appHealthParams struct {
 	baseHealthParams
 	// The target app
@@ -208,7 +267,7 @@ func (p *appHealthParams) extract(r *http.Request) {
 
 // serviceHealthParams holds the path and query parameters for ServiceHealth
 //
-// swagger:parameters serviceHealth
+// swagger:parameters serviceHealth serviceSLO
 type serviceHealthParams struct {
 	baseHealthParams
 	// The target service
@@ -225,7 +284,7 @@ func (p *serviceHealthParams) extract(r *http.Request) {
 
 // workloadHealthParams holds the path and query parameters for WorkloadHealth
 //
-// swagger:parameters workloadHealth
+// swagger:parameters workloadHealth workloadSLO
 type workloadHealthParams struct {
 	baseHealthParams
 	// The target workload
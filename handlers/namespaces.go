@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/status"
 )
 
 func NamespaceList(w http.ResponseWriter, r *http.Request) {
@@ -29,11 +36,19 @@ func NamespaceList(w http.ResponseWriter, r *http.Request) {
 }
 
 // NamespaceValidationSummary is the API handler to fetch validations summary to be displayed.
-// It is related to all the Istio Objects within the namespace
+// It is related to all the Istio Objects within the namespace. When a SARIF or JUnit report is requested,
+// with the format parameter or the Accept header, the validations of the objects are returned in that
+// format instead of the summary.
 func NamespaceValidationSummary(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	namespace := vars["namespace"]
 
+	format, err := validationFormat(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	business, err := getBusiness(r)
 	if err != nil {
 		log.Error(err)
@@ -41,17 +56,90 @@ func NamespaceValidationSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var validationSummary models.IstioValidationSummary
-
 	istioConfigValidationResults, errValidations := business.Validations.GetValidations(namespace, "")
 	if errValidations != nil {
 		log.Error(errValidations)
 		RespondWithError(w, http.StatusInternalServerError, errValidations.Error())
-	} else {
-		validationSummary = istioConfigValidationResults.SummarizeValidation(namespace)
+		return
+	}
+
+	var report []byte
+	var contentType string
+	switch format {
+	case models.ValidationFormatSARIF:
+		coreVersion, _ := status.GetStatus(status.CoreVersion)
+		report, err = json.MarshalIndent(istioConfigValidationResults.SARIF(coreVersion, nil), "", "  ")
+		contentType = "application/sarif+json"
+	case models.ValidationFormatJUnit:
+		report, err = xml.MarshalIndent(istioConfigValidationResults.JUnit(namespace, nil), "", "  ")
+		report = append([]byte(xml.Header), report...)
+		contentType = "application/xml"
+	default:
+		RespondWithJSON(w, http.StatusOK, istioConfigValidationResults.SummarizeValidation(namespace))
+		return
+	}
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(report)
+}
 
-	RespondWithJSON(w, http.StatusOK, validationSummary)
+// validationFormatMediaTypes maps the media types of the Accept header to the validations formats
+var validationFormatMediaTypes = map[string]string{
+	"application/sarif+json": models.ValidationFormatSARIF,
+	"application/xml":        models.ValidationFormatJUnit,
+	"text/xml":               models.ValidationFormatJUnit,
+}
+
+// validationFormat returns the requested validations format, the format parameter takes precedence over the
+// Accept header: application/sarif+json for SARIF, application/xml for JUnit.
+func validationFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		switch format {
+		case models.ValidationFormatJSON, models.ValidationFormatJUnit, models.ValidationFormatSARIF:
+			return format, nil
+		default:
+			return "", fmt.Errorf("invalid format [%s], expecting one of: json | junit | sarif", format)
+		}
+	}
+	return acceptedValidationFormat(r.Header.Get("Accept")), nil
+}
+
+// acceptedValidationFormat returns the validations format preferred by the Accept header. SARIF or JUnit are
+// returned only when preferred to any other media type: browsers accept XML, but prefer HTML, and get JSON.
+func acceptedValidationFormat(accept string) string {
+	otherQuality := 0.0
+	formatQualities := make(map[string]float64)
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if format, found := validationFormatMediaTypes[mediaType]; found {
+			if quality > formatQualities[format] {
+				formatQualities[format] = quality
+			}
+		} else if quality > otherQuality {
+			otherQuality = quality
+		}
+	}
+
+	format, bestQuality := models.ValidationFormatJSON, otherQuality
+	for _, f := range []string{models.ValidationFormatSARIF, models.ValidationFormatJUnit} {
+		if quality := formatQualities[f]; quality > bestQuality {
+			format, bestQuality = f, quality
+		}
+	}
+	return format
 }
 
 // NamespaceUpdate is the API to perform a patch on a Namespace configuration
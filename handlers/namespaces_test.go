@@ -17,12 +17,16 @@ import (
 	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	apps_v1 "k8s.io/api/apps/v1"
+	batch_v1 "k8s.io/api/batch/v1"
+	batch_v1beta1 "k8s.io/api/batch/v1beta1"
 	core_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/kubernetes/kubetest"
 	"github.com/kiali/kiali/prometheus"
 	"github.com/kiali/kiali/prometheus/prometheustest"
@@ -213,3 +217,85 @@ func setupMocked() (*prometheus.Client, *prometheustest.PromAPIMock, *kubetest.K
 
 	return client, api, k8s, nil
 }
+
+func TestValidationFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	request := func(query, accept string) *http.Request {
+		r := httptest.NewRequest("GET", "/api/namespaces/ns/validations"+query, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		return r
+	}
+
+	format, err := validationFormat(request("", ""))
+	assert.NoError(err)
+	assert.Equal("json", format)
+
+	format, _ = validationFormat(request("", "application/sarif+json"))
+	assert.Equal("sarif", format)
+
+	format, _ = validationFormat(request("", "text/xml, application/json;q=0.9"))
+	assert.Equal("junit", format)
+
+	// browsers accept XML, but prefer other media types
+	format, _ = validationFormat(request("", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8"))
+	assert.Equal("json", format)
+	format, _ = validationFormat(request("", "application/xml,application/xhtml+xml,text/html;q=0.9,text/plain;q=0.8,*/*;q=0.5"))
+	assert.Equal("json", format)
+	format, _ = validationFormat(request("", "application/json, application/xml"))
+	assert.Equal("json", format)
+
+	// the format parameter takes precedence over the Accept header
+	format, _ = validationFormat(request("?format=sarif", "application/xml"))
+	assert.Equal("sarif", format)
+
+	_, err = validationFormat(request("?format=html", ""))
+	assert.Error(err)
+}
+
+func TestNamespaceValidationSummaryBrowser(t *testing.T) {
+	conf := config.NewConfig()
+	conf.KubernetesConfig.CacheEnabled = false
+	config.Set(conf)
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("IsMaistraApi").Return(false)
+	k8s.On("GetNamespace", "bookinfo").Return(kubetest.FakeNamespace("bookinfo"), nil)
+	k8s.On("GetNamespaces", mock.AnythingOfType("string")).Return([]core_v1.Namespace{*kubetest.FakeNamespace("bookinfo")}, nil)
+	k8s.On("GetIstioObjects", mock.AnythingOfType("string"), mock.AnythingOfType("string"), "").Return([]kubernetes.IstioObject{}, nil)
+	k8s.On("GetServices", mock.AnythingOfType("string"), mock.Anything).Return([]core_v1.Service{}, nil)
+	k8s.On("GetDeployments", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]apps_v1.Deployment{}, nil)
+	k8s.On("GetReplicaSets", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]apps_v1.ReplicaSet{}, nil)
+	k8s.On("GetReplicationControllers", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]core_v1.ReplicationController{}, nil)
+	k8s.On("GetStatefulSets", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]apps_v1.StatefulSet{}, nil)
+	k8s.On("GetDaemonSets", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]apps_v1.DaemonSet{}, nil)
+	k8s.On("GetJobs", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]batch_v1.Job{}, nil)
+	k8s.On("GetCronJobs", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]batch_v1beta1.CronJob{}, nil)
+	k8s.On("GetPods", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return([]core_v1.Pod{}, nil)
+	k8s.On("GetConfigMap", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(&core_v1.ConfigMap{}, nil)
+	business.SetWithBackends(kubetest.NewK8SClientFactoryMock(k8s), nil)
+
+	mr := mux.NewRouter()
+	mr.HandleFunc("/api/namespaces/{namespace}/validations", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			context := context.WithValue(r.Context(), "authInfo", &api.AuthInfo{Token: "test"})
+			NamespaceValidationSummary(w, r.WithContext(context))
+		}))
+	ts := httptest.NewServer(mr)
+	defer ts.Close()
+
+	// a browser opening the validations URL gets the JSON summary
+	request, _ := http.NewRequest("GET", ts.URL+"/api/namespaces/bookinfo/validations", nil)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `"errors":0`)
+}
@@ -29,9 +29,12 @@ import (
 )
 
 type K8SClientInterface interface {
+	CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error)
+	DeleteConfigMap(namespace, name string) error
 	ForwardGetRequest(namespace, podName string, localPort, destinationPort int, path string) ([]byte, error)
 	GetClusterServicesByLabels(labelsSelector string) ([]core_v1.Service, error)
 	GetConfigMap(namespace, name string) (*core_v1.ConfigMap, error)
+	GetConfigMaps(namespace, labelSelector string) ([]core_v1.ConfigMap, error)
 	GetCronJobs(namespace string) ([]batch_v1beta1.CronJob, error)
 	GetDaemonSet(namespace string, name string) (*apps_v1.DaemonSet, error)
 	GetDaemonSets(namespace string) ([]apps_v1.DaemonSet, error)
@@ -49,9 +52,11 @@ type K8SClientInterface interface {
 	GetPodPortForwarder(namespace, podName, portMap string) (*httputil.PortForwarder, error)
 	GetReplicationControllers(namespace string) ([]core_v1.ReplicationController, error)
 	GetReplicaSets(namespace string) ([]apps_v1.ReplicaSet, error)
+	GetSecret(namespace, name string) (*core_v1.Secret, error)
 	GetSecrets(namespace string, labelSelector string) ([]core_v1.Secret, error)
 	GetSelfSubjectAccessReview(namespace, api, resourceType string, verbs []string) ([]*auth_v1.SelfSubjectAccessReview, error)
 	GetService(namespace string, name string) (*core_v1.Service, error)
+	GetServiceAccounts(namespace string) ([]core_v1.ServiceAccount, error)
 	GetServices(namespace string, selectorLabels map[string]string) ([]core_v1.Service, error)
 	GetServicesByLabels(namespace string, labelsSelector string) ([]core_v1.Service, error)
 	GetStatefulSet(namespace string, name string) (*apps_v1.StatefulSet, error)
@@ -69,6 +74,16 @@ type OSClientInterface interface {
 	UpdateProject(project string, jsonPatch string) (*osproject_v1.Project, error)
 }
 
+// CreateConfigMap creates the ConfigMap in the specified namespace
+func (in *K8SClient) CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error) {
+	return in.k8s.CoreV1().ConfigMaps(namespace).Create(in.ctx, configMap, meta_v1.CreateOptions{})
+}
+
+// DeleteConfigMap deletes the specified ConfigMap
+func (in *K8SClient) DeleteConfigMap(namespace, name string) error {
+	return in.k8s.CoreV1().ConfigMaps(namespace).Delete(in.ctx, name, meta_v1.DeleteOptions{})
+}
+
 func (in *K8SClient) ForwardGetRequest(namespace, podName string, localPort, destinationPort int, path string) ([]byte, error) {
 	f, err := in.GetPodPortForwarder(namespace, podName, fmt.Sprintf("%d:%d", localPort, destinationPort))
 	if err != nil {
@@ -116,6 +131,16 @@ func (in *K8SClient) GetConfigMap(namespace, name string) (*core_v1.ConfigMap, e
 	return configMap, nil
 }
 
+// GetConfigMaps fetches and returns the ConfigMaps in the namespace that match the optional labelSelector
+func (in *K8SClient) GetConfigMaps(namespace, labelSelector string) ([]core_v1.ConfigMap, error) {
+	listOptions := meta_v1.ListOptions{LabelSelector: labelSelector}
+	if configMapList, err := in.k8s.CoreV1().ConfigMaps(namespace).List(in.ctx, listOptions); err == nil {
+		return configMapList.Items, nil
+	} else {
+		return []core_v1.ConfigMap{}, err
+	}
+}
+
 // GetNamespace fetches and returns the specified namespace definition
 // from the cluster
 func (in *K8SClient) GetNamespace(namespace string) (*core_v1.Namespace, error) {
@@ -227,6 +252,15 @@ func (in *K8SClient) GetServices(namespace string, selectorLabels map[string]str
 	return services, nil
 }
 
+// GetServiceAccounts returns the ServiceAccounts of a namespace
+func (in *K8SClient) GetServiceAccounts(namespace string) ([]core_v1.ServiceAccount, error) {
+	if saList, err := in.k8s.CoreV1().ServiceAccounts(namespace).List(in.ctx, emptyListOptions); err == nil {
+		return saList.Items, nil
+	} else {
+		return []core_v1.ServiceAccount{}, err
+	}
+}
+
 func (in *K8SClient) GetServicesByLabels(namespace string, labelsSelector string) ([]core_v1.Service, error) {
 	selector := meta_v1.ListOptions{LabelSelector: labelsSelector}
 	if allServicesList, err := in.k8s.CoreV1().Services(namespace).List(in.ctx, selector); err == nil {
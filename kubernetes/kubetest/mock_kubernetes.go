@@ -11,6 +11,16 @@ import (
 	"github.com/kiali/kiali/util/httputil"
 )
 
+func (o *K8SClientMock) CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error) {
+	args := o.Called(namespace, configMap)
+	return args.Get(0).(*core_v1.ConfigMap), args.Error(1)
+}
+
+func (o *K8SClientMock) DeleteConfigMap(namespace, name string) error {
+	args := o.Called(namespace, name)
+	return args.Error(0)
+}
+
 func (o *K8SClientMock) ForwardGetRequest(namespace, podName string, localPort, destinationPort int, path string) ([]byte, error) {
 	args := o.Called(namespace, podName, localPort, destinationPort, path)
 	return args.Get(0).([]byte), args.Error(1)
@@ -26,6 +36,11 @@ func (o *K8SClientMock) GetConfigMap(namespace, name string) (*core_v1.ConfigMap
 	return args.Get(0).(*core_v1.ConfigMap), args.Error(1)
 }
 
+func (o *K8SClientMock) GetConfigMaps(namespace, labelSelector string) ([]core_v1.ConfigMap, error) {
+	args := o.Called(namespace, labelSelector)
+	return args.Get(0).([]core_v1.ConfigMap), args.Error(1)
+}
+
 func (o *K8SClientMock) GetCronJobs(namespace string) ([]batch_apps_v1.CronJob, error) {
 	args := o.Called(namespace)
 	return args.Get(0).([]batch_apps_v1.CronJob), args.Error(1)
@@ -106,6 +121,11 @@ func (o *K8SClientMock) GetReplicaSets(namespace string) ([]apps_v1.ReplicaSet,
 	return args.Get(0).([]apps_v1.ReplicaSet), args.Error(1)
 }
 
+func (o *K8SClientMock) GetSecret(namespace, name string) (*core_v1.Secret, error) {
+	args := o.Called(namespace, name)
+	return args.Get(0).(*core_v1.Secret), args.Error(1)
+}
+
 func (o *K8SClientMock) GetSecrets(namespace string, labelSelector string) ([]core_v1.Secret, error) {
 	args := o.Called(namespace, labelSelector)
 	return args.Get(0).([]core_v1.Secret), args.Error(1)
@@ -126,6 +146,11 @@ func (o *K8SClientMock) GetServices(namespace string, selectorLabels map[string]
 	return args.Get(0).([]core_v1.Service), args.Error(1)
 }
 
+func (o *K8SClientMock) GetServiceAccounts(namespace string) ([]core_v1.ServiceAccount, error) {
+	args := o.Called(namespace)
+	return args.Get(0).([]core_v1.ServiceAccount), args.Error(1)
+}
+
 func (o *K8SClientMock) GetServicesByLabels(namespace string, labelsSelector string) ([]core_v1.Service, error) {
 	args := o.Called(namespace, labelsSelector)
 	return args.Get(0).([]core_v1.Service), args.Error(1)
@@ -0,0 +1,75 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ParseManifests decodes the objects of a YAML manifest, one object per document of the multi-document format.
+// Empty documents are skipped. The specs are normalized to map[string]interface{}, like the specs returned
+// by the Kubernetes API.
+func ParseManifests(manifests []byte) ([]GenericIstioObject, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(manifests))
+
+	var objects []GenericIstioObject
+	for {
+		var object GenericIstioObject
+		err := dec.Decode(&object)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return objects, err
+		}
+		if object.Kind == "" && object.Name == "" && len(object.Spec) == 0 {
+			continue
+		}
+		object.Spec = cleanUpStringInterfaceMap(object.Spec)
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// Needed due to Yaml.Decode default map type is map[interface{}]interface{}
+// We need to convert it to map[string]interface{} to be compliant with real Istio Objects.
+// Known issue: https://github.com/go-yaml/yaml/issues/139
+
+func cleanUpInterfaceArray(in []interface{}) []interface{} {
+	result := make([]interface{}, len(in))
+	for i, v := range in {
+		result[i] = cleanUpMapValue(v)
+	}
+	return result
+}
+
+func cleanUpInterfaceMap(in map[interface{}]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range in {
+		result[fmt.Sprintf("%v", k)] = cleanUpMapValue(v)
+	}
+	return result
+}
+
+func cleanUpStringInterfaceMap(in map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range in {
+		result[k] = cleanUpMapValue(v)
+	}
+	return result
+}
+
+func cleanUpMapValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return cleanUpInterfaceArray(v)
+	case map[interface{}]interface{}:
+		return cleanUpInterfaceMap(v)
+	case map[string]interface{}:
+		return cleanUpStringInterfaceMap(v)
+	default:
+		return v
+	}
+}
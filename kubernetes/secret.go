@@ -52,6 +52,12 @@ func GetRemoteSecret(path string) (*RemoteSecret, error) {
 	return ParseRemoteSecretBytes(secretFile)
 }
 
+// GetSecret returns the secret of the given name in the namespace.
+// It returns an error on any problem.
+func (in *K8SClient) GetSecret(namespace, name string) (*core_v1.Secret, error) {
+	return in.k8s.CoreV1().Secrets(namespace).Get(in.ctx, name, emptyGetOptions)
+}
+
 // GetSecrets returns a list of secrets for a given namespace.
 // If selectorLabels is defined, the list will only contain services matching
 // the specified label selector.
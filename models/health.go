@@ -17,13 +17,15 @@ type NamespaceWorkloadHealth map[string]*WorkloadHealth
 
 // ServiceHealth contains aggregated health from various sources, for a given service
 type ServiceHealth struct {
-	Requests RequestHealth `json:"requests"`
+	Requests  RequestHealth `json:"requests"`
+	SLOStatus *SLOStatus    `json:"sloStatus,omitempty"`
 }
 
 // AppHealth contains aggregated health from various sources, for a given app
 type AppHealth struct {
 	WorkloadStatuses []*WorkloadStatus `json:"workloadStatuses"`
 	Requests         RequestHealth     `json:"requests"`
+	SLOStatus        *SLOStatus        `json:"sloStatus,omitempty"`
 }
 
 func NewEmptyRequestHealth() RequestHealth {
@@ -62,6 +64,7 @@ func EmptyWorkloadHealth() *WorkloadHealth {
 type WorkloadHealth struct {
 	WorkloadStatus *WorkloadStatus `json:"workloadStatus"`
 	Requests       RequestHealth   `json:"requests"`
+	SLOStatus      *SLOStatus      `json:"sloStatus,omitempty"`
 }
 
 // WorkloadStatus gives
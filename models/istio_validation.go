@@ -108,6 +108,9 @@ var ObjectTypeSingular = map[string]string{
 	"sidecars":               "sidecar",
 	"peerauthentications":    "peerauthentication",
 	"requestauthentications": "requestauthentication",
+	"envoyfilters":           "envoyfilter",
+	"workloadentries":        "workloadentry",
+	"workloadgroups":         "workloadgroup",
 }
 
 var checkDescriptors = map[string]IstioCheck{
@@ -176,6 +179,31 @@ var checkDescriptors = map[string]IstioCheck{
 		Message:  "This subset has not labels",
 		Severity: WarningSeverity,
 	},
+	"envoyfilter.patch.unknownapplyto": {
+		Code:     "KIA1201",
+		Message:  "Unknown applyTo value",
+		Severity: ErrorSeverity,
+	},
+	"envoyfilter.patch.invalidoperation": {
+		Code:     "KIA1202",
+		Message:  "Patch operation is not supported for this applyTo",
+		Severity: ErrorSeverity,
+	},
+	"envoyfilter.proxyversion.invalid": {
+		Code:     "KIA1203",
+		Message:  "Proxy version is not a valid regular expression",
+		Severity: ErrorSeverity,
+	},
+	"envoyfilter.proxyversion.nomatch": {
+		Code:     "KIA1204",
+		Message:  "Proxy version doesn't match the running Istio version, the patch is never applied",
+		Severity: WarningSeverity,
+	},
+	"envoyfilter.priority.conflict": {
+		Code:     "KIA1205",
+		Message:  "More than one EnvoyFilter patching the same listener and context with the same priority",
+		Severity: WarningSeverity,
+	},
 	"gateways.multimatch": {
 		Code:     "KIA0301",
 		Message:  "More than one Gateway for the same host port combination",
@@ -186,6 +214,31 @@ var checkDescriptors = map[string]IstioCheck{
 		Message:  "No matching workload found for gateway selector in this namespace",
 		Severity: WarningSeverity,
 	},
+	"gateways.tls.certexpired": {
+		Code:     "KIA0303",
+		Message:  "The certificate of the credential has expired",
+		Severity: ErrorSeverity,
+	},
+	"gateways.tls.certexpiring": {
+		Code:     "KIA0304",
+		Message:  "The certificate of the credential expires soon",
+		Severity: WarningSeverity,
+	},
+	"gateways.tls.credentialnotfound": {
+		Code:     "KIA0305",
+		Message:  "Secret not found in the namespace of the gateway workload",
+		Severity: ErrorSeverity,
+	},
+	"gateways.tls.hostnotcovered": {
+		Code:     "KIA0306",
+		Message:  "Host not covered by the certificate of the credential",
+		Severity: WarningSeverity,
+	},
+	"gateways.tls.invalidcredential": {
+		Code:     "KIA0307",
+		Message:  "Secret doesn't hold a valid certificate and private key",
+		Severity: ErrorSeverity,
+	},
 	"generic.exportto.namespacenotfound": {
 		Code:     "KIA0005",
 		Message:  "No matching namespace found or namespace is not accessible",
@@ -206,6 +259,11 @@ var checkDescriptors = map[string]IstioCheck{
 		Message:  "No matching workload found for the selector in this namespace",
 		Severity: WarningSeverity,
 	},
+	"generic.serviceaccount.notfound": {
+		Code:     "KIA0006",
+		Message:  "ServiceAccount not found in this namespace",
+		Severity: ErrorSeverity,
+	},
 	"peerauthentication.mtls.destinationrulemissing": {
 		Code:     "KIA0401",
 		Message:  "Mesh-wide Destination Rule enabling mTLS is missing",
@@ -306,6 +364,21 @@ var checkDescriptors = map[string]IstioCheck{
 		Message:  "Subset not found",
 		Severity: WarningSeverity,
 	},
+	"workloadentry.address.duplicate": {
+		Code:     "KIA1301",
+		Message:  "More than one WorkloadEntry with the same address in the same network",
+		Severity: WarningSeverity,
+	},
+	"workloadentry.labels.servicenotfound": {
+		Code:     "KIA1302",
+		Message:  "No Service or ServiceEntry selecting the labels of this WorkloadEntry",
+		Severity: WarningSeverity,
+	},
+	"workloadgroup.labels.workloadconflict": {
+		Code:     "KIA1401",
+		Message:  "WorkloadGroup labels match the labels of an existing workload",
+		Severity: WarningSeverity,
+	},
 	"validation.unable.cross-namespace": {
 		Code:     "KIA0001",
 		Message:  "Unable to verify the validity, cross-namespace validation is not supported for this field",
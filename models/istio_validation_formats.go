@@ -0,0 +1,249 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validation report formats, see IstioValidations.SARIF and IstioValidations.JUnit
+const (
+	ValidationFormatJSON  = "json"
+	ValidationFormatJUnit = "junit"
+	ValidationFormatSARIF = "sarif"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// SARIFLog is a SARIF 2.1.0 log, a single run holding a result per check
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver describes Kiali, the rules are the check codes reported by the run
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     SARIFMessage           `json:"shortDescription"`
+	DefaultConfiguration SARIFRuleConfiguration `json:"defaultConfiguration"`
+}
+
+type SARIFRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations"`
+}
+
+// SARIFLocation locates a check in the object, the physical location is set only when the file defining the
+// object is known
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLogicalLocation is the object, or the element of the object when the check has a path. The fully
+// qualified name is namespace/objectType/name[/path], e.g. bookinfo/virtualservice/reviews/spec/http[0]/route
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// JUnitTestSuites is a JUnit XML report, a test suite per namespace and a test case per object. A test case
+// fails when the object has error checks, all the checks are reported in the test case output.
+type JUnitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	TestSuites []JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// sortedKeys returns the validation keys sorted by namespace, object type and name
+func (iv IstioValidations) sortedKeys() []IstioValidationKey {
+	keys := make([]IstioValidationKey, 0, len(iv))
+	for key := range iv {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		if keys[i].ObjectType != keys[j].ObjectType {
+			return keys[i].ObjectType < keys[j].ObjectType
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// sarifLevel maps a check severity to a SARIF level
+func sarifLevel(severity SeverityLevel) string {
+	switch severity {
+	case ErrorSeverity:
+		return "error"
+	case WarningSeverity:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// SARIF returns the validations as a SARIF log: each check code is a rule, and each check a result located
+// in its object. files optionally maps the objects to the files defining them, it can be nil.
+func (iv IstioValidations) SARIF(version string, files map[IstioValidationKey]string) SARIFLog {
+	driver := SARIFDriver{
+		Name:           "Kiali",
+		InformationURI: "https://kiali.io",
+		Version:        version,
+		Rules:          []SARIFRule{},
+	}
+	rules := make(map[string]bool)
+	results := []SARIFResult{}
+	for _, key := range iv.sortedKeys() {
+		for _, check := range iv[key].Checks {
+			if !rules[check.Code] {
+				rules[check.Code] = true
+				driver.Rules = append(driver.Rules, SARIFRule{
+					ID:                   check.Code,
+					ShortDescription:     SARIFMessage{Text: check.Message},
+					DefaultConfiguration: SARIFRuleConfiguration{Level: sarifLevel(check.Severity)},
+				})
+			}
+
+			logicalLocation := SARIFLogicalLocation{
+				Name:               key.Name,
+				FullyQualifiedName: fmt.Sprintf("%s/%s/%s", key.Namespace, key.ObjectType, key.Name),
+				Kind:               "resource",
+			}
+			if check.Path != "" {
+				logicalLocation.Name = check.Path
+				logicalLocation.FullyQualifiedName += "/" + check.Path
+				logicalLocation.Kind = "element"
+			}
+			location := SARIFLocation{LogicalLocations: []SARIFLogicalLocation{logicalLocation}}
+			if file, ok := files[key]; ok {
+				location.PhysicalLocation = &SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: file}}
+			}
+
+			results = append(results, SARIFResult{
+				RuleID:    check.Code,
+				Level:     sarifLevel(check.Severity),
+				Message:   SARIFMessage{Text: check.Message},
+				Locations: []SARIFLocation{location},
+			})
+		}
+	}
+	sort.Slice(driver.Rules, func(i, j int) bool {
+		return driver.Rules[i].ID < driver.Rules[j].ID
+	})
+
+	return SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []SARIFRun{{Tool: SARIFTool{Driver: driver}, Results: results}},
+	}
+}
+
+// JUnit returns the validations as a JUnit report named name. files optionally maps the objects to the
+// files defining them, it can be nil.
+func (iv IstioValidations) JUnit(name string, files map[IstioValidationKey]string) JUnitTestSuites {
+	report := JUnitTestSuites{Name: name, TestSuites: []JUnitTestSuite{}}
+	var suite *JUnitTestSuite
+	for _, key := range iv.sortedKeys() {
+		if suite == nil || suite.Name != key.Namespace {
+			report.TestSuites = append(report.TestSuites, JUnitTestSuite{Name: key.Namespace, TestCases: []JUnitTestCase{}})
+			suite = &report.TestSuites[len(report.TestSuites)-1]
+		}
+
+		testCase := JUnitTestCase{
+			Name:      fmt.Sprintf("%s/%s", key.ObjectType, key.Name),
+			ClassName: fmt.Sprintf("%s.%s", key.Namespace, key.ObjectType),
+		}
+		errors := 0
+		lines := []string{}
+		if file, ok := files[key]; ok {
+			lines = append(lines, "file: "+file)
+		}
+		for _, check := range iv[key].Checks {
+			if check.Severity == ErrorSeverity {
+				errors++
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s: %s", check.Severity, check.Code, check.Path, check.Message))
+		}
+		if len(iv[key].Checks) > 0 {
+			testCase.SystemOut = strings.Join(lines, "\n")
+		}
+		if errors > 0 {
+			testCase.Failure = &JUnitFailure{
+				Message: fmt.Sprintf("%d validation errors", errors),
+				Type:    string(ErrorSeverity),
+				Text:    testCase.SystemOut,
+			}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		report.Tests++
+	}
+	return report
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func formatTestValidations() IstioValidations {
+	return IstioValidations{
+		IstioValidationKey{ObjectType: "virtualservice", Name: "reviews", Namespace: "bookinfo"}: &IstioValidation{
+			Name:       "reviews",
+			ObjectType: "virtualservice",
+			Valid:      false,
+			Checks: []*IstioCheck{
+				{Code: "KIA1101", Severity: ErrorSeverity, Message: "DestinationWeight on route doesn't have a valid service (host not found)", Path: "spec/http[0]/route[0]/destination/host"},
+				{Code: "KIA1107", Severity: WarningSeverity, Message: "Subset not found"},
+			},
+		},
+		IstioValidationKey{ObjectType: "destinationrule", Name: "reviews", Namespace: "bookinfo"}: &IstioValidation{
+			Name:       "reviews",
+			ObjectType: "destinationrule",
+			Valid:      true,
+			Checks:     []*IstioCheck{},
+		},
+		IstioValidationKey{ObjectType: "gateway", Name: "ingress", Namespace: "istio-system"}: &IstioValidation{
+			Name:       "ingress",
+			ObjectType: "gateway",
+			Valid:      true,
+			Checks: []*IstioCheck{
+				{Code: "KIA0302", Severity: WarningSeverity, Message: "No matching workload found for gateway selector in this namespace", Path: "spec/selector"},
+			},
+		},
+	}
+}
+
+func TestValidationsSARIF(t *testing.T) {
+	assert := assert.New(t)
+
+	files := map[IstioValidationKey]string{
+		{ObjectType: "virtualservice", Name: "reviews", Namespace: "bookinfo"}: "bookinfo.yaml",
+	}
+	log := formatTestValidations().SARIF("v1.0.0", files)
+
+	assert.Equal("2.1.0", log.Version)
+	assert.Equal(1, len(log.Runs))
+	driver := log.Runs[0].Tool.Driver
+	assert.Equal("v1.0.0", driver.Version)
+	assert.Equal(3, len(driver.Rules))
+	assert.Equal("KIA0302", driver.Rules[0].ID)
+	assert.Equal("warning", driver.Rules[0].DefaultConfiguration.Level)
+	assert.Equal("KIA1101", driver.Rules[1].ID)
+	assert.Equal("error", driver.Rules[1].DefaultConfiguration.Level)
+
+	results := log.Runs[0].Results
+	assert.Equal(3, len(results))
+	assert.Equal("KIA1101", results[0].RuleID)
+	assert.Equal("error", results[0].Level)
+	assert.Equal("bookinfo.yaml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(SARIFLogicalLocation{
+		Name:               "spec/http[0]/route[0]/destination/host",
+		FullyQualifiedName: "bookinfo/virtualservice/reviews/spec/http[0]/route[0]/destination/host",
+		Kind:               "element",
+	}, results[0].Locations[0].LogicalLocations[0])
+	assert.Equal(SARIFLogicalLocation{
+		Name:               "reviews",
+		FullyQualifiedName: "bookinfo/virtualservice/reviews",
+		Kind:               "resource",
+	}, results[1].Locations[0].LogicalLocations[0])
+	assert.Equal("KIA0302", results[2].RuleID)
+	assert.Nil(results[2].Locations[0].PhysicalLocation)
+}
+
+func TestValidationsJUnit(t *testing.T) {
+	assert := assert.New(t)
+
+	report := formatTestValidations().JUnit("kiali", nil)
+
+	assert.Equal(3, report.Tests)
+	assert.Equal(1, report.Failures)
+	assert.Equal(2, len(report.TestSuites))
+
+	bookinfo := report.TestSuites[0]
+	assert.Equal("bookinfo", bookinfo.Name)
+	assert.Equal(2, bookinfo.Tests)
+	assert.Equal(1, bookinfo.Failures)
+	assert.Equal("destinationrule/reviews", bookinfo.TestCases[0].Name)
+	assert.Nil(bookinfo.TestCases[0].Failure)
+	assert.Empty(bookinfo.TestCases[0].SystemOut)
+	assert.Equal("virtualservice/reviews", bookinfo.TestCases[1].Name)
+	assert.Equal("bookinfo.virtualservice", bookinfo.TestCases[1].ClassName)
+	assert.Equal("1 validation errors", bookinfo.TestCases[1].Failure.Message)
+
+	istioSystem := report.TestSuites[1]
+	assert.Equal("istio-system", istioSystem.Name)
+	assert.Equal(0, istioSystem.Failures)
+	assert.Nil(istioSystem.TestCases[0].Failure)
+	assert.Contains(istioSystem.TestCases[0].SystemOut, "warning KIA0302 spec/selector")
+
+	b, err := xml.Marshal(report)
+	assert.NoError(err)
+	assert.Contains(string(b), `<testsuites name="kiali" tests="3" failures="1"><testsuite name="bookinfo" tests="2" failures="1">`)
+}
@@ -0,0 +1,70 @@
+package models
+
+// SLO objective types
+const (
+	SLOAvailability = "availability"
+	SLOLatency      = "latency"
+)
+
+// SLO definition sources
+const (
+	SLOSourceAnnotation = "annotation"
+	SLOSourceConfig     = "config"
+)
+
+// SLO statuses, from the best to the worst
+const (
+	SLOStatusNoData    = "noData"    // no request was received over the window
+	SLOStatusHealthy   = "healthy"   // the error budget is not exhausted, and not burning too fast
+	SLOStatusBurning   = "burning"   // the error budget burns too fast over both the long and short windows of a burn rate
+	SLOStatusExhausted = "exhausted" // the error budget is exhausted
+)
+
+// NamespaceSLOStatus is an alias of map of app, service or workload name x SLO status
+type NamespaceSLOStatus map[string]*SLOStatus
+
+// SLOStatus is the status of the service level objectives of an app, service or workload
+// swagger:model SLOStatus
+type SLOStatus struct {
+	// The objectives, availability first
+	Objectives []SLOObjectiveStatus `json:"objectives"`
+	// The worst status of the objectives
+	// example: healthy
+	Status string `json:"status"`
+}
+
+// SLOObjectiveStatus is the status of a single service level objective
+type SLOObjectiveStatus struct {
+	// The remaining error budget, as a percentage of the budget allowed over the window. Negative when exceeded.
+	ErrorBudgetRemaining float64 `json:"errorBudgetRemaining"`
+	// The burn rates over multiple windows, see SLOBurnRate
+	BurnRates []SLOBurnRate `json:"burnRates"`
+	// The measured percentage of good requests over the window, 100 when no request was received
+	SLI float64 `json:"sli"`
+	// Where the objective is defined: annotation | config
+	Source string `json:"source"`
+	// The objective status: noData | healthy | burning | exhausted
+	Status string `json:"status"`
+	// The target percentage of good requests
+	// example: 99.9
+	Target float64 `json:"target"`
+	// The latency threshold in millis, latency objectives only
+	Threshold float64 `json:"threshold,omitempty"`
+	// The objective type: availability | latency
+	Type string `json:"type"`
+	// The window of the objective
+	// example: 30d
+	Window string `json:"window"`
+}
+
+// SLOBurnRate is the rate at which the error budget is consumed over a long and a short window, 1 meaning
+// that the budget would be exactly exhausted at the end of the objective window. The burn rate alerts when
+// both rates exceed the threshold.
+type SLOBurnRate struct {
+	Alerting    bool    `json:"alerting"`
+	LongRate    float64 `json:"longRate"`
+	LongWindow  string  `json:"longWindow"`
+	ShortRate   float64 `json:"shortRate"`
+	ShortWindow string  `json:"shortWindow"`
+	Threshold   float64 `json:"threshold"`
+}
@@ -37,6 +37,7 @@ type ClientInterface interface {
 	GetFlags() (prom_v1.FlagsResult, error)
 	GetNamespaceServicesRequestRates(namespace, ratesInterval string, queryTime time.Time) (model.Vector, error)
 	GetServiceRequestRates(namespace, service, ratesInterval string, queryTime time.Time) (model.Vector, error)
+	GetSLOCounts(labels, interval string, latencyThreshold float64, queryTime time.Time) (SLOCounts, error)
 	GetWorkloadRequestRates(namespace, workload, ratesInterval string, queryTime time.Time) (model.Vector, model.Vector, error)
 	GetMetricsForLabels(labels []string) ([]string, error)
 }
@@ -71,13 +72,40 @@ func NewClient() (*Client, error) {
 // NewClient creates a new client to the Prometheus API.
 // It returns an error on any problem.
 func NewClientForConfig(cfg config.PrometheusConfig) (*Client, error) {
-	clientConfig := api.Config{Address: cfg.URL}
-
 	// Prom Cache will be initialized once at first use of Prometheus Client
 	once.Do(initPromCache)
 
+	p8s, err := newAPIClient(cfg.URL, cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+	client := Client{p8s: p8s, api: prom_v1.NewAPI(p8s), ctx: context.Background()}
+
+	// with remote cluster endpoints, fan out the queries to every Prometheus
+	if len(cfg.Clusters) > 0 {
+		apis := map[string]prom_v1.API{}
+		for cluster, clusterCfg := range cfg.Clusters {
+			auth := clusterCfg.Auth
+			if auth.Type == "" {
+				auth = cfg.Auth
+			}
+			clusterP8s, err := newAPIClient(clusterCfg.URL, auth)
+			if err != nil {
+				return nil, err
+			}
+			apis[cluster] = prom_v1.NewAPI(clusterP8s)
+		}
+		client.api = NewFederatedAPI(client.api, apis)
+	}
+	return &client, nil
+}
+
+// newAPIClient creates a new client to the Prometheus API at the given address
+func newAPIClient(address string, cfgAuth config.Auth) (api.Client, error) {
+	clientConfig := api.Config{Address: address}
+
 	// Be sure to copy config.Auth and not modify the existing
-	auth := cfg.Auth
+	auth := cfgAuth
 	if auth.UseKialiToken {
 		// Note: if we are using the 'bearer' authentication method then we want to use the Kiali
 		// service account token and not the user's token. This is because Kiali does filtering based
@@ -111,8 +139,7 @@ func NewClientForConfig(cfg config.PrometheusConfig) (*Client, error) {
 	if err != nil {
 		return nil, errors.NewServiceUnavailable(err.Error())
 	}
-	client := Client{p8s: p8s, api: prom_v1.NewAPI(p8s), ctx: context.Background()}
-	return &client, nil
+	return p8s, nil
 }
 
 // Inject allows for replacing the API with a mock For testing
@@ -230,6 +257,15 @@ func (in *Client) GetWorkloadRequestRates(namespace, workload, ratesInterval str
 	return inResult, outResult, nil
 }
 
+// GetSLOCounts queries Prometheus to fetch the number of requests received over a time interval by the
+// destination matching the labels, as reported by the destination. When latencyThreshold (in millis) is
+// positive the requests slower than the threshold are counted too, the threshold must be a bucket boundary
+// of the istio_request_duration_milliseconds histogram.
+func (in *Client) GetSLOCounts(labels, interval string, latencyThreshold float64, queryTime time.Time) (SLOCounts, error) {
+	log.Tracef("GetSLOCounts [labels: %s] [interval: %s] [latencyThreshold: %v] [queryTime: %s]", labels, interval, latencyThreshold, queryTime.String())
+	return getSLOCounts(in.ctx, in.api, labels, interval, latencyThreshold, queryTime)
+}
+
 // FetchRange fetches a simple metric (gauge or counter) in given range
 func (in *Client) FetchRange(metricName, labels, grouping, aggregator string, q *RangeQuery) Metric {
 	query := fmt.Sprintf("%s(%s%s)", aggregator, metricName, labels)
@@ -0,0 +1,430 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/log"
+)
+
+const homeEndpoint = "home"
+
+var (
+	// ratioExpr matches the ratio of two sums of rates, e.g. the average of a histogram:
+	// sum(rate(x_sum[5m])) by (a) / sum(rate(x_count[5m])) by (a)
+	ratioExpr = regexp.MustCompile(`sum\(rate\([^()]*\)\)(?: by \([^()]*\))? / sum\(rate\([^()]*\)\)(?: by \([^()]*\))?`)
+	// ratioTemplate matches the expressions wrapping a ratio (R) in the Kiali queries: the filtering of the graph
+	// queries, R > 0, possibly rounded, round(R > 0,0.001), and the rounding of the metrics queries,
+	// round(R, 0.001) > 0.001 or R
+	ratioTemplate = regexp.MustCompile(`^R( > 0)?$|^round\(R( > 0)?, ?([0-9.]+)\)( > [0-9.]+ or R)?$`)
+)
+
+// federatedAPI is a Prometheus API fanning out the queries to the Prometheus of every cluster, for meshes
+// without a global (federated) Prometheus. Query results are merged by series: the values of a series
+// reported by several Prometheus are summed, as the clusters scrape disjoint proxies, except for histogram
+// quantile queries where the highest value is kept. The numerator and denominator of a ratio of sums, e.g. an
+// average response time, are queried separately and summed before being divided. Any other endpoint is served
+// by the home Prometheus.
+// A failing cluster Prometheus is reported as a warning, the query fails only if every Prometheus fails.
+type federatedAPI struct {
+	prom_v1.API                        // the home Prometheus
+	clusters    map[string]prom_v1.API // the remote cluster Prometheus, keyed by cluster name
+}
+
+// NewFederatedAPI returns a Prometheus API fanning out the queries to the home Prometheus and to the
+// Prometheus of every remote cluster, and merging the results.
+func NewFederatedAPI(home prom_v1.API, clusters map[string]prom_v1.API) prom_v1.API {
+	return &federatedAPI{API: home, clusters: clusters}
+}
+
+type fanOutResult struct {
+	endpoint string
+	value    interface{}
+	warnings prom_v1.Warnings
+	err      error
+}
+
+// fanOut calls every Prometheus concurrently, returning the successful results in a stable order (home first,
+// then by cluster name) and the warnings.
+func (in *federatedAPI) fanOut(call func(api prom_v1.API) (interface{}, prom_v1.Warnings, error)) ([]interface{}, prom_v1.Warnings, error) {
+	clusterNames := make([]string, 0, len(in.clusters))
+	for cluster := range in.clusters {
+		clusterNames = append(clusterNames, cluster)
+	}
+	sort.Strings(clusterNames)
+
+	results := make([]fanOutResult, len(clusterNames)+1)
+	wg := sync.WaitGroup{}
+	wg.Add(len(results))
+	for i := range results {
+		endpoint, api := homeEndpoint, in.API
+		if i > 0 {
+			endpoint, api = clusterNames[i-1], in.clusters[clusterNames[i-1]]
+		}
+		go func(i int, endpoint string, api prom_v1.API) {
+			defer wg.Done()
+			value, warnings, err := call(api)
+			results[i] = fanOutResult{endpoint: endpoint, value: value, warnings: warnings, err: err}
+		}(i, endpoint, api)
+	}
+	wg.Wait()
+
+	var values []interface{}
+	var warnings prom_v1.Warnings
+	var firstErr error
+	for _, r := range results {
+		warnings = append(warnings, r.warnings...)
+		if r.err != nil {
+			log.Warningf("Prometheus query failed for cluster [%s]: %v", r.endpoint, r.err)
+			warnings = append(warnings, fmt.Sprintf("Prometheus query failed for cluster [%s]: %v", r.endpoint, r.err))
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		values = append(values, r.value)
+	}
+	if len(values) == 0 {
+		return nil, warnings, firstErr
+	}
+	return values, warnings, nil
+}
+
+// Query implements prom_v1.API
+func (in *federatedAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, prom_v1.Warnings, error) {
+	if ratio, ok := parseRatioQuery(query); ok {
+		return in.queryRatio(ratio, func(api prom_v1.API, q string) (model.Value, prom_v1.Warnings, error) {
+			return api.Query(ctx, q, ts)
+		})
+	}
+	results, warnings, err := in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return api.Query(ctx, query, ts)
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+	return mergeValues(results, combineFunc(query)), warnings, nil
+}
+
+// QueryRange implements prom_v1.API
+func (in *federatedAPI) QueryRange(ctx context.Context, query string, r prom_v1.Range) (model.Value, prom_v1.Warnings, error) {
+	if ratio, ok := parseRatioQuery(query); ok {
+		return in.queryRatio(ratio, func(api prom_v1.API, q string) (model.Value, prom_v1.Warnings, error) {
+			return api.QueryRange(ctx, q, r)
+		})
+	}
+	results, warnings, err := in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return api.QueryRange(ctx, query, r)
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+	return mergeValues(results, combineFunc(query)), warnings, nil
+}
+
+// Series implements prom_v1.API
+func (in *federatedAPI) Series(ctx context.Context, matches []string, startTime time.Time, endTime time.Time) ([]model.LabelSet, prom_v1.Warnings, error) {
+	results, warnings, err := in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return api.Series(ctx, matches, startTime, endTime)
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+	series := []model.LabelSet{}
+	seen := make(map[model.Fingerprint]bool)
+	for _, r := range results {
+		for _, labelSet := range r.([]model.LabelSet) {
+			if fp := labelSet.Fingerprint(); !seen[fp] {
+				seen[fp] = true
+				series = append(series, labelSet)
+			}
+		}
+	}
+	return series, warnings, nil
+}
+
+// LabelNames implements prom_v1.API
+func (in *federatedAPI) LabelNames(ctx context.Context, startTime time.Time, endTime time.Time) ([]string, prom_v1.Warnings, error) {
+	results, warnings, err := in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return api.LabelNames(ctx, startTime, endTime)
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, r := range results {
+		for _, name := range r.([]string) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, warnings, nil
+}
+
+// LabelValues implements prom_v1.API
+func (in *federatedAPI) LabelValues(ctx context.Context, label string, startTime time.Time, endTime time.Time) (model.LabelValues, prom_v1.Warnings, error) {
+	results, warnings, err := in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return api.LabelValues(ctx, label, startTime, endTime)
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+	values := model.LabelValues{}
+	seen := make(map[model.LabelValue]bool)
+	for _, r := range results {
+		for _, value := range r.(model.LabelValues) {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	sort.Sort(values)
+	return values, warnings, nil
+}
+
+// queryRatio queries the numerator and the denominator of a ratio on every Prometheus, and divides their merged
+// sums: the sum of the ratios of every cluster would be meaningless
+func (in *federatedAPI) queryRatio(ratio ratioQuery, query func(api prom_v1.API, q string) (model.Value, prom_v1.Warnings, error)) (model.Value, prom_v1.Warnings, error) {
+	results, warnings, err := in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		numerator, numeratorWarnings, err := query(api, ratio.numerator)
+		if err != nil {
+			return nil, numeratorWarnings, err
+		}
+		denominator, denominatorWarnings, err := query(api, ratio.denominator)
+		return [2]model.Value{numerator, denominator}, append(numeratorWarnings, denominatorWarnings...), err
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+	numerators := make([]interface{}, 0, len(results))
+	denominators := make([]interface{}, 0, len(results))
+	for _, r := range results {
+		values := r.([2]model.Value)
+		numerators = append(numerators, values[0])
+		denominators = append(denominators, values[1])
+	}
+	return ratio.divide(mergeValues(numerators, sumValues), mergeValues(denominators, sumValues)), warnings, nil
+}
+
+// ratioQuery is a query of the ratio of two sums, see ratioExpr and ratioTemplate
+type ratioQuery struct {
+	numerator   string
+	denominator string
+	positive    bool    // only the positive ratios are kept
+	precision   float64 // the ratios are rounded to the precision, no rounding when 0
+	significant bool    // only the ratios higher than the precision are rounded
+}
+
+// parseRatioQuery returns the ratio of a query when it is a supported ratio of sums
+func parseRatioQuery(query string) (ratioQuery, bool) {
+	ratio := ratioExpr.FindString(query)
+	if ratio == "" {
+		return ratioQuery{}, false
+	}
+	match := ratioTemplate.FindStringSubmatch(strings.ReplaceAll(query, ratio, "R"))
+	if match == nil {
+		return ratioQuery{}, false
+	}
+
+	i := strings.Index(ratio, " / ")
+	rq := ratioQuery{
+		numerator:   ratio[:i],
+		denominator: ratio[i+len(" / "):],
+		positive:    match[1] != "" || match[2] != "",
+		significant: match[4] != "",
+	}
+	if match[3] != "" {
+		precision, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return ratioQuery{}, false
+		}
+		rq.precision = precision
+	}
+	return rq, true
+}
+
+// divide divides the series of the numerator by the series of the denominator with the same labels, the series
+// without a denominator are dropped
+func (rq ratioQuery) divide(numerator, denominator model.Value) model.Value {
+	switch num := numerator.(type) {
+	case model.Vector:
+		denominators := make(map[model.Fingerprint]model.SampleValue)
+		if den, ok := denominator.(model.Vector); ok {
+			for _, s := range den {
+				denominators[s.Metric.Fingerprint()] = s.Value
+			}
+		}
+		ratios := model.Vector{}
+		for _, s := range num {
+			if value, ok := rq.ratio(s.Value, denominators[s.Metric.Fingerprint()]); ok {
+				sample := *s
+				sample.Value = value
+				ratios = append(ratios, &sample)
+			}
+		}
+		return ratios
+	case model.Matrix:
+		denominators := make(map[model.Fingerprint]map[model.Time]model.SampleValue)
+		if den, ok := denominator.(model.Matrix); ok {
+			for _, s := range den {
+				values := make(map[model.Time]model.SampleValue, len(s.Values))
+				for _, pair := range s.Values {
+					values[pair.Timestamp] = pair.Value
+				}
+				denominators[s.Metric.Fingerprint()] = values
+			}
+		}
+		ratios := model.Matrix{}
+		for _, s := range num {
+			values := []model.SamplePair{}
+			for _, pair := range s.Values {
+				if value, ok := rq.ratio(pair.Value, denominators[s.Metric.Fingerprint()][pair.Timestamp]); ok {
+					values = append(values, model.SamplePair{Timestamp: pair.Timestamp, Value: value})
+				}
+			}
+			if len(values) > 0 {
+				ratios = append(ratios, &model.SampleStream{Metric: s.Metric, Values: values})
+			}
+		}
+		return ratios
+	}
+	return numerator
+}
+
+// ratio returns the ratio, filtered and rounded like the query would have done it
+func (rq ratioQuery) ratio(numerator, denominator model.SampleValue) (model.SampleValue, bool) {
+	if denominator == 0 {
+		return 0, false
+	}
+	value := float64(numerator / denominator)
+	if rq.positive && value <= 0 {
+		return 0, false
+	}
+	if rq.precision > 0 && (!rq.significant || value > rq.precision) {
+		// same rounding as the Prometheus round function
+		inverse := 1.0 / rq.precision
+		value = math.Floor(value*inverse+0.5) / inverse
+	}
+	return model.SampleValue(value), true
+}
+
+// combineFunc returns how to combine the values of a series reported by several Prometheus. Ratios, other than
+// the ratios of sums handled by queryRatio, can't be combined: the highest value is kept, as for quantiles.
+func combineFunc(query string) func(a, b model.SampleValue) model.SampleValue {
+	if strings.Contains(query, "histogram_quantile") || strings.Contains(query, " / ") {
+		return func(a, b model.SampleValue) model.SampleValue {
+			if b > a {
+				return b
+			}
+			return a
+		}
+	}
+	return sumValues
+}
+
+func sumValues(a, b model.SampleValue) model.SampleValue {
+	return a + b
+}
+
+// mergeValues merges the query results of several Prometheus, see federatedAPI
+func mergeValues(results []interface{}, combine func(a, b model.SampleValue) model.SampleValue) model.Value {
+	var merged model.Value
+	for _, r := range results {
+		value, ok := r.(model.Value)
+		if !ok || value == nil {
+			continue
+		}
+		if merged == nil {
+			merged = value
+			continue
+		}
+		if merged.Type() != value.Type() {
+			log.Warningf("Ignoring Prometheus result of type [%s], expecting [%s]", value.Type(), merged.Type())
+			continue
+		}
+		switch v := value.(type) {
+		case model.Vector:
+			merged = mergeVectors(merged.(model.Vector), v, combine)
+		case model.Matrix:
+			merged = mergeMatrices(merged.(model.Matrix), v, combine)
+		case *model.Scalar:
+			scalar := *merged.(*model.Scalar)
+			scalar.Value = combine(scalar.Value, v.Value)
+			merged = &scalar
+		}
+	}
+	return merged
+}
+
+func mergeVectors(a, b model.Vector, combine func(a, b model.SampleValue) model.SampleValue) model.Vector {
+	merged := make(model.Vector, 0, len(a)+len(b))
+	index := make(map[model.Fingerprint]int, len(a))
+	for _, vector := range []model.Vector{a, b} {
+		for _, s := range vector {
+			fp := s.Metric.Fingerprint()
+			if i, ok := index[fp]; ok {
+				sample := *merged[i]
+				sample.Value = combine(sample.Value, s.Value)
+				merged[i] = &sample
+				continue
+			}
+			index[fp] = len(merged)
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+func mergeMatrices(a, b model.Matrix, combine func(a, b model.SampleValue) model.SampleValue) model.Matrix {
+	merged := make(model.Matrix, 0, len(a)+len(b))
+	index := make(map[model.Fingerprint]int, len(a))
+	for _, matrix := range []model.Matrix{a, b} {
+		for _, s := range matrix {
+			fp := s.Metric.Fingerprint()
+			if i, ok := index[fp]; ok {
+				merged[i] = &model.SampleStream{Metric: s.Metric, Values: mergeSamplePairs(merged[i].Values, s.Values, combine)}
+				continue
+			}
+			index[fp] = len(merged)
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+// mergeSamplePairs merges two streams of sample pairs sorted by timestamp
+func mergeSamplePairs(a, b []model.SamplePair, combine func(a, b model.SampleValue) model.SampleValue) []model.SamplePair {
+	merged := make([]model.SamplePair, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].Timestamp < b[j].Timestamp:
+			merged = append(merged, a[i])
+			i++
+		case a[i].Timestamp > b[j].Timestamp:
+			merged = append(merged, b[j])
+			j++
+		default:
+			merged = append(merged, model.SamplePair{Timestamp: a[i].Timestamp, Value: combine(a[i].Value, b[j].Value)})
+			i++
+			j++
+		}
+	}
+	merged = append(merged, a[i:]...)
+	return append(merged, b[j:]...)
+}
@@ -23,6 +23,7 @@ const (
 	labelService          = "service"
 	labelType             = "type"
 	labelName             = "name"
+	labelResult           = "result"
 )
 
 // MetricsType defines all of Kiali's own internal metrics.
@@ -30,7 +31,11 @@ type MetricsType struct {
 	GraphNodes                     *prometheus.GaugeVec
 	GraphGenerationTime            *prometheus.HistogramVec
 	GraphAppenderTime              *prometheus.HistogramVec
+	GraphNamespaceTime             *prometheus.HistogramVec
 	GraphMarshalTime               *prometheus.HistogramVec
+	GraphCacheRequests             *prometheus.CounterVec
+	GraphCacheEntries              *prometheus.GaugeVec
+	GraphStreams                   *prometheus.GaugeVec
 	APIProcessingTime              *prometheus.HistogramVec
 	PrometheusProcessingTime       *prometheus.HistogramVec
 	KubernetesClients              *prometheus.GaugeVec
@@ -65,6 +70,13 @@ var Metrics = MetricsType{
 		},
 		[]string{labelAppender},
 	),
+	GraphNamespaceTime: prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "kiali_graph_namespace_duration_seconds",
+			Help: "The time required to build the traffic map of a namespace, appenders included, while generating a graph.",
+		},
+		[]string{labelNamespace, labelGraphType},
+	),
 	GraphMarshalTime: prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name: "kiali_graph_marshal_duration_seconds",
@@ -72,6 +84,27 @@ var Metrics = MetricsType{
 		},
 		[]string{labelGraphKind, labelGraphType, labelWithServiceNodes},
 	),
+	GraphCacheRequests: prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kiali_graph_cache_requests_total",
+			Help: "Counts the graph cache lookups for namespace traffic maps, by result (hit | miss).",
+		},
+		[]string{labelResult},
+	),
+	GraphCacheEntries: prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kiali_graph_cache_entries",
+			Help: "The number of namespace traffic maps in the graph cache.",
+		},
+		[]string{},
+	),
+	GraphStreams: prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kiali_graph_streams",
+			Help: "The number of open graph update streams.",
+		},
+		[]string{},
+	),
 	APIProcessingTime: prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name: "kiali_api_processing_duration_seconds",
@@ -172,7 +205,11 @@ func RegisterInternalMetrics() {
 		Metrics.GraphNodes,
 		Metrics.GraphGenerationTime,
 		Metrics.GraphAppenderTime,
+		Metrics.GraphNamespaceTime,
 		Metrics.GraphMarshalTime,
+		Metrics.GraphCacheRequests,
+		Metrics.GraphCacheEntries,
+		Metrics.GraphStreams,
 		Metrics.APIProcessingTime,
 		Metrics.PrometheusProcessingTime,
 		Metrics.KubernetesClients,
@@ -225,6 +262,21 @@ func GetGraphAppenderTimePrometheusTimer(appenderName string) *prometheus.Timer
 	return timer
 }
 
+// GetGraphNamespaceTimePrometheusTimer returns a timer that can be used to store
+// a value for the graph namespace time metric. The timer is ticking immediately
+// when this function returns.
+// Typical usage is as follows:
+//    promtimer := GetGraphNamespaceTimePrometheusTimer(...)
+//    ... build the namespace traffic map and run the appenders ...
+//    promtimer.ObserveDuration()
+func GetGraphNamespaceTimePrometheusTimer(namespace string, graphType string) *prometheus.Timer {
+	timer := prometheus.NewTimer(Metrics.GraphNamespaceTime.With(prometheus.Labels{
+		labelNamespace: namespace,
+		labelGraphType: graphType,
+	}))
+	return timer
+}
+
 // GetGraphMarshalTimePrometheusTimer returns a timer that can be used to store
 // a value for the graph marshal time metric. The timer is ticking immediately
 // when this function returns.
@@ -240,6 +292,23 @@ func GetGraphMarshalTimePrometheusTimer(graphKind string, graphType string, with
 	return timer
 }
 
+// GetGraphCacheRequestsMetric returns the counter of graph cache lookups with the given result (hit | miss)
+func GetGraphCacheRequestsMetric(result string) prometheus.Counter {
+	return Metrics.GraphCacheRequests.With(prometheus.Labels{
+		labelResult: result,
+	})
+}
+
+// SetGraphCacheEntries sets the graph cache entry count
+func SetGraphCacheEntries(entryCount int) {
+	Metrics.GraphCacheEntries.With(prometheus.Labels{}).Set(float64(entryCount))
+}
+
+// GetGraphStreamsMetric returns the gauge of open graph update streams
+func GetGraphStreamsMetric() prometheus.Gauge {
+	return Metrics.GraphStreams.With(prometheus.Labels{})
+}
+
 // GetAPIProcessingTimePrometheusTimer returns a timer that can be used to store
 // a value for the API processing time metric. The timer is ticking immediately
 // when this function returns.
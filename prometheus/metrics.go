@@ -3,6 +3,8 @@ package prometheus
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -188,6 +190,62 @@ func getRequestRatesForLabel(ctx context.Context, api prom_v1.API, time time.Tim
 	return result.(model.Vector), nil
 }
 
+// getSLOCounts retrieves the number of requests received by a destination over the interval, only the destination
+// reporter is used to avoid counting requests twice.
+func getSLOCounts(ctx context.Context, api prom_v1.API, labels, interval string, latencyThreshold float64, queryTime time.Time) (SLOCounts, error) {
+	counts := SLOCounts{}
+	lbl := fmt.Sprintf(`reporter="destination",%s`, labels)
+
+	var err error
+	query := fmt.Sprintf("sum(increase(istio_requests_total{%s}[%s]))", lbl, interval)
+	if counts.Total, err = getSLOCount(ctx, api, query, queryTime); err != nil {
+		return counts, err
+	}
+	if counts.Total == 0 {
+		return counts, nil
+	}
+
+	// the error codes match the default health tolerances: 5xx, no response (0), and gRPC errors
+	query = fmt.Sprintf(`(sum(increase(istio_requests_total{%s,request_protocol!="grpc",response_code=~"0|5.."}[%s])) or vector(0)) + (sum(increase(istio_requests_total{%s,request_protocol="grpc",grpc_response_status=~"[1-9]|1[0-6]"}[%s])) or vector(0))`,
+		lbl, interval, lbl, interval)
+	if counts.Errors, err = getSLOCount(ctx, api, query, queryTime); err != nil {
+		return counts, err
+	}
+
+	if latencyThreshold > 0 {
+		query = fmt.Sprintf(`sum(increase(istio_request_duration_milliseconds_count{%s}[%s])) - (sum(increase(istio_request_duration_milliseconds_bucket{%s,le="%s"}[%s])) or vector(0))`,
+			lbl, interval, lbl, strconv.FormatFloat(latencyThreshold, 'f', -1, 64), interval)
+		if counts.Slow, err = getSLOCount(ctx, api, query, queryTime); err != nil {
+			return counts, err
+		}
+	}
+	return counts, nil
+}
+
+// getSLOCount returns the value of a query expected to return a single sample, 0 if there is no sample
+func getSLOCount(ctx context.Context, api prom_v1.API, query string, queryTime time.Time) (float64, error) {
+	log.Tracef("[Prom] getSLOCount: %s", query)
+	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Metrics-GetSLOCounts")
+	result, warnings, err := api.Query(ctx, query, queryTime)
+	if warnings != nil && len(warnings) > 0 {
+		log.Warningf("getSLOCount. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+	}
+	if err != nil {
+		return 0, errors.NewServiceUnavailable(err.Error())
+	}
+	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
+
+	count := 0.0
+	if vector, ok := result.(model.Vector); ok {
+		for _, sample := range vector {
+			if value := float64(sample.Value); !math.IsNaN(value) && value > 0 {
+				count += value
+			}
+		}
+	}
+	return count, nil
+}
+
 // roundSignificant will output promQL that performs rounding only if the resulting value is significant, that is, higher than the requested precision
 func roundSignificant(innerQuery string, precision float64) string {
 	return fmt.Sprintf("round(%s, %f) > %f or %s", innerQuery, precision, precision, innerQuery)
@@ -0,0 +1,131 @@
+package prometheustest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kiali/kiali/prometheus"
+)
+
+// unavailableAPI fails every query
+type unavailableAPI struct {
+	PromAPIMock
+}
+
+func (o *unavailableAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, prom_v1.Warnings, error) {
+	return nil, nil, errors.New("unavailable")
+}
+
+func sample(cluster string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{"destination_cluster": model.LabelValue(cluster), "destination_workload": "reviews"},
+		Value:  model.SampleValue(value),
+	}
+}
+
+func TestFederatedQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	home := new(PromAPIMock)
+	east := new(PromAPIMock)
+	west := new(PromAPIMock)
+	query := `sum(rate(istio_requests_total[1m])) by (destination_cluster,destination_workload)`
+	home.On("Query", mock.Anything, query, mock.Anything).Return(model.Vector{sample("home", 1), sample("east", 2)}, nil)
+	east.On("Query", mock.Anything, query, mock.Anything).Return(model.Vector{sample("east", 3)}, nil)
+	west.On("Query", mock.Anything, query, mock.Anything).Return(model.Vector{sample("west", 4)}, nil)
+
+	api := prometheus.NewFederatedAPI(home, map[string]prom_v1.API{"west": west, "east": east})
+	result, warnings, err := api.Query(context.Background(), query, time.Now())
+	assert.NoError(err)
+	assert.Empty(warnings)
+	assert.Equal(model.Vector{sample("home", 1), sample("east", 5), sample("west", 4)}, result)
+
+	// the highest quantile is kept
+	query = `histogram_quantile(0.95, sum(rate(istio_request_duration_milliseconds_bucket[1m])) by (le,destination_cluster,destination_workload))`
+	home.On("Query", mock.Anything, query, mock.Anything).Return(model.Vector{sample("east", 20)}, nil)
+	east.On("Query", mock.Anything, query, mock.Anything).Return(model.Vector{sample("east", 30)}, nil)
+	west.On("Query", mock.Anything, query, mock.Anything).Return(model.Vector{}, nil)
+	result, _, err = api.Query(context.Background(), query, time.Now())
+	assert.NoError(err)
+	assert.Equal(model.Vector{sample("east", 30)}, result)
+
+	// a failing cluster is reported as a warning
+	api = prometheus.NewFederatedAPI(home, map[string]prom_v1.API{"east": new(unavailableAPI)})
+	result, warnings, err = api.Query(context.Background(), query, time.Now())
+	assert.NoError(err)
+	assert.Equal(1, len(warnings))
+	assert.Equal(model.Vector{sample("east", 20)}, result)
+
+	api = prometheus.NewFederatedAPI(new(unavailableAPI), map[string]prom_v1.API{"east": new(unavailableAPI)})
+	_, _, err = api.Query(context.Background(), query, time.Now())
+	assert.Error(err)
+}
+
+func TestFederatedQueryRange(t *testing.T) {
+	assert := assert.New(t)
+
+	metric := model.Metric{"destination_workload": "reviews"}
+	stream := func(values ...model.SamplePair) model.Matrix {
+		return model.Matrix{&model.SampleStream{Metric: metric, Values: values}}
+	}
+
+	home := new(PromAPIMock)
+	east := new(PromAPIMock)
+	query := `sum(rate(istio_requests_total[1m])) by (destination_workload)`
+	home.On("QueryRange", mock.Anything, query, mock.Anything).Return(stream(model.SamplePair{Timestamp: 60, Value: 1}, model.SamplePair{Timestamp: 120, Value: 2}), nil)
+	east.On("QueryRange", mock.Anything, query, mock.Anything).Return(stream(model.SamplePair{Timestamp: 120, Value: 3}, model.SamplePair{Timestamp: 180, Value: 4}), nil)
+
+	api := prometheus.NewFederatedAPI(home, map[string]prom_v1.API{"east": east})
+	result, _, err := api.QueryRange(context.Background(), query, prom_v1.Range{})
+	assert.NoError(err)
+	assert.Equal(stream(model.SamplePair{Timestamp: 60, Value: 1}, model.SamplePair{Timestamp: 120, Value: 5}, model.SamplePair{Timestamp: 180, Value: 4}), result)
+}
+
+func TestFederatedRatioQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	numerator := `sum(rate(istio_request_duration_milliseconds_sum[1m])) by (destination_cluster,destination_workload)`
+	denominator := `sum(rate(istio_request_duration_milliseconds_count[1m])) by (destination_cluster,destination_workload)`
+	home := new(PromAPIMock)
+	east := new(PromAPIMock)
+	// home: 2 requests of 10ms and 10 requests of 10ms, east: 6 requests of 30ms
+	home.On("Query", mock.Anything, numerator, mock.Anything).Return(model.Vector{sample("home", 20), sample("east", 100)}, nil)
+	home.On("Query", mock.Anything, denominator, mock.Anything).Return(model.Vector{sample("home", 2), sample("east", 10)}, nil)
+	east.On("Query", mock.Anything, numerator, mock.Anything).Return(model.Vector{sample("east", 180)}, nil)
+	east.On("Query", mock.Anything, denominator, mock.Anything).Return(model.Vector{sample("east", 6)}, nil)
+	api := prometheus.NewFederatedAPI(home, map[string]prom_v1.API{"east": east})
+
+	// the average is computed from the merged sums, it is not the sum of the averages
+	query := `round(` + numerator + ` / ` + denominator + `, 0.001000) > 0.001000 or ` + numerator + ` / ` + denominator
+	result, _, err := api.Query(context.Background(), query, time.Now())
+	assert.NoError(err)
+	assert.Equal(model.Vector{sample("home", 10), sample("east", 17.5)}, result)
+
+	query = `round(` + numerator + ` / ` + denominator + ` > 0,0.001)`
+	result, _, err = api.Query(context.Background(), query, time.Now())
+	assert.NoError(err)
+	assert.Equal(model.Vector{sample("home", 10), sample("east", 17.5)}, result)
+
+	metric := model.Metric{"destination_workload": "reviews"}
+	stream := func(values ...model.SamplePair) model.Matrix {
+		return model.Matrix{&model.SampleStream{Metric: metric, Values: values}}
+	}
+	numerator = `sum(rate(istio_request_duration_milliseconds_sum[1m])) by (destination_workload)`
+	denominator = `sum(rate(istio_request_duration_milliseconds_count[1m])) by (destination_workload)`
+	home.On("QueryRange", mock.Anything, numerator, mock.Anything).Return(stream(model.SamplePair{Timestamp: 60, Value: 10}, model.SamplePair{Timestamp: 120, Value: 0}), nil)
+	home.On("QueryRange", mock.Anything, denominator, mock.Anything).Return(stream(model.SamplePair{Timestamp: 60, Value: 1}, model.SamplePair{Timestamp: 120, Value: 0}), nil)
+	east.On("QueryRange", mock.Anything, numerator, mock.Anything).Return(stream(model.SamplePair{Timestamp: 60, Value: 20}), nil)
+	east.On("QueryRange", mock.Anything, denominator, mock.Anything).Return(stream(model.SamplePair{Timestamp: 60, Value: 3}), nil)
+
+	// the samples without requests are dropped
+	result, _, err = api.QueryRange(context.Background(), numerator+` / `+denominator, prom_v1.Range{})
+	assert.NoError(err)
+	assert.Equal(stream(model.SamplePair{Timestamp: 60, Value: 7.5}), result)
+}
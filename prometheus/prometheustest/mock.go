@@ -296,6 +296,11 @@ func (o *PromClientMock) GetServiceRequestRates(namespace, service, ratesInterva
 	return args.Get(0).(model.Vector), args.Error(1)
 }
 
+func (o *PromClientMock) GetSLOCounts(labels, interval string, latencyThreshold float64, queryTime time.Time) (prometheus.SLOCounts, error) {
+	args := o.Called(labels, interval, latencyThreshold, queryTime)
+	return args.Get(0).(prometheus.SLOCounts), args.Error(1)
+}
+
 func (o *PromClientMock) GetWorkloadRequestRates(namespace, workload, ratesInterval string, queryTime time.Time) (model.Vector, model.Vector, error) {
 	args := o.Called(namespace, workload, ratesInterval, queryTime)
 	return args.Get(0).(model.Vector), args.Get(1).(model.Vector), args.Error(2)
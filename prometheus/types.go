@@ -27,6 +27,13 @@ func (q *RangeQuery) FillDefaults() {
 	q.Avg = true
 }
 
+// SLOCounts holds the number of requests received over an interval, see Client.GetSLOCounts
+type SLOCounts struct {
+	Errors float64 // requests failed with a 5xx code, a gRPC error or no response
+	Slow   float64 // requests slower than the latency threshold, 0 if no threshold is requested
+	Total  float64
+}
+
 // Metrics contains all simple metrics and histograms data
 type Metrics struct {
 	Metrics    map[string]*Metric   `json:"metrics"`
@@ -119,6 +119,18 @@ func (srw *statusResponseWriter) WriteHeader(code int) {
 	srw.StatusCode = code
 }
 
+// Flush allows streaming handlers to flush the contained ResponseWriter, when supported
+func (srw *statusResponseWriter) Flush() {
+	if flusher, ok := srw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the contained ResponseWriter, allowing handlers to reach its optional interfaces
+func (srw *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return srw.ResponseWriter
+}
+
 // updateMetric evaluates the StatusCode, if there is an error, increase the API failure counter, otherwise save the duration
 func updateMetric(route string, srw *statusResponseWriter, timer *prometheus.Timer) {
 	// Always measure the duration even if the API call ended in an error
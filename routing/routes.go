@@ -901,6 +901,28 @@ func NewRoutes() (r *Routes) {
 			handlers.ServiceHealth,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/services/{service}/slo services serviceSLO
+		// ---
+		// Get the status of the service level objectives (SLO) of the given service
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      200: sloStatusResponse
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//
+		{
+			"ServiceSLOStatus",
+			"GET",
+			"/api/namespaces/{namespace}/services/{service}/slo",
+			handlers.ServiceSLOStatus,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/apps/{app}/health apps appHealth
 		// ---
 		// Get health associated to the given app
@@ -922,6 +944,28 @@ func NewRoutes() (r *Routes) {
 			handlers.AppHealth,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/apps/{app}/slo apps appSLO
+		// ---
+		// Get the status of the service level objectives (SLO) of the given app
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      200: sloStatusResponse
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//
+		{
+			"AppSLOStatus",
+			"GET",
+			"/api/namespaces/{namespace}/apps/{app}/slo",
+			handlers.AppSLOStatus,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/workloads/{workload}/health workloads workloadHealth
 		// ---
 		// Get health associated to the given workload
@@ -943,6 +987,28 @@ func NewRoutes() (r *Routes) {
 			handlers.WorkloadHealth,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/workloads/{workload}/slo workloads workloadSLO
+		// ---
+		// Get the status of the service level objectives (SLO) of the given workload
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      200: sloStatusResponse
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//
+		{
+			"WorkloadSLOStatus",
+			"GET",
+			"/api/namespaces/{namespace}/workloads/{workload}/slo",
+			handlers.WorkloadSLOStatus,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/metrics namespaces namespaceMetrics
 		// ---
 		// Endpoint to fetch metrics to be displayed, related to a namespace
@@ -985,12 +1051,37 @@ func NewRoutes() (r *Routes) {
 			handlers.NamespaceHealth,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/slo namespaces namespaceSLO
+		// ---
+		// Get the status of the service level objectives (SLO) of the apps, services or workloads in the given namespace, limited to the ones with an SLO
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      200: namespaceSLOStatusResponse
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//
+		{
+			"NamespaceSLOStatus",
+			"GET",
+			"/api/namespaces/{namespace}/slo",
+			handlers.NamespaceSLOStatus,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/validations namespaces namespaceValidations
 		// ---
-		// Get validation summary for all objects in the given namespace
+		// Get validation summary for all objects in the given namespace, or the validations as a SARIF or JUnit report
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/sarif+json
+		//     - application/xml
 		//
 		//     Schemes: http, https
 		//
@@ -1075,6 +1166,8 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
 		//
 		//     Schemes: http, https
 		//
@@ -1090,12 +1183,173 @@ func NewRoutes() (r *Routes) {
 			handlers.GraphNamespaces,
 			true,
 		},
+		// swagger:route GET /namespaces/graph/diff graphs graphNamespacesDiff
+		// ---
+		// The backing JSON for a namespaces graph, decorated with the differences found when compared to a baseline time period.
+		//
+		//     Produces:
+		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphResponse
+		//
+		{
+			"GraphNamespacesDiff",
+			"GET",
+			"/api/namespaces/graph/diff",
+			handlers.GraphNamespacesDiff,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/entrypoints graphs graphNamespacesEntryPoints
+		// ---
+		// The backing JSON for a namespaces graph holding only the paths through the external entry points of the mesh: the paths
+		// starting at the ingress gateways, annotated with the gateway hostnames, and the paths ending at the egress nodes.
+		//
+		//     Produces:
+		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphResponse
+		//
+		{
+			"GraphNamespacesEntryPoints",
+			"GET",
+			"/api/namespaces/graph/entrypoints",
+			handlers.GraphNamespacesEntryPoints,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/stream graphs graphNamespacesStream
+		// ---
+		// A stream of namespaces graph updates, as Server-Sent Events. The first event provides the full graph, subsequent
+		// events provide only the changed nodes and edges. The stream ends before the server write timeout, clients should reconnect.
+		//
+		//     Produces:
+		//     - text/event-stream
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: graphStreamResponse
+		//
+		{
+			"GraphNamespacesStream",
+			"GET",
+			"/api/namespaces/graph/stream",
+			handlers.GraphNamespacesStream,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/snapshots graphs graphSnapshotList
+		// ---
+		// The saved namespaces graph snapshots, newest first. Only the snapshots of accessible namespaces are listed.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: graphSnapshotListResponse
+		//
+		{
+			"GraphSnapshotList",
+			"GET",
+			"/api/namespaces/graph/snapshots",
+			handlers.GraphSnapshotList,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/snapshots/{snapshot} graphs graphSnapshot
+		// ---
+		// The backing JSON for a saved namespaces graph snapshot.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: graphResponse
+		//
+		{
+			"GraphSnapshot",
+			"GET",
+			"/api/namespaces/graph/snapshots/{snapshot}",
+			handlers.GraphSnapshot,
+			true,
+		},
+		// swagger:route POST /namespaces/graph/snapshots/{snapshot} graphs graphSnapshotSave
+		// ---
+		// Generates a namespaces graph and saves it as a named snapshot, recording the user, time and graph options.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      201: graphSnapshotInfoResponse
+		//
+		{
+			"GraphSnapshotSave",
+			"POST",
+			"/api/namespaces/graph/snapshots/{snapshot}",
+			handlers.GraphSnapshotSave,
+			true,
+		},
+		// swagger:route DELETE /namespaces/graph/snapshots/{snapshot} graphs graphSnapshotDelete
+		// ---
+		// Deletes a saved namespaces graph snapshot.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200
+		//
+		{
+			"GraphSnapshotDelete",
+			"DELETE",
+			"/api/namespaces/graph/snapshots/{snapshot}",
+			handlers.GraphSnapshotDelete,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/aggregates/{aggregate}/{aggregateValue}/graph graphs graphAggregate
 		// ---
 		// The backing JSON for an aggregate node detail graph. (supported graphTypes: app | versionedApp | workload)
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
 		//
 		//     Schemes: http, https
 		//
@@ -1118,6 +1372,8 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
 		//
 		//     Schemes: http, https
 		//
@@ -1140,6 +1396,8 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
 		//
 		//     Schemes: http, https
 		//
@@ -1162,6 +1420,8 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
 		//
 		//     Schemes: http, https
 		//
@@ -1183,6 +1443,8 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
 		//
 		//     Schemes: http, https
 		//
@@ -1204,6 +1466,8 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
 		//
 		//     Schemes: http, https
 		//
@@ -1219,6 +1483,121 @@ func NewRoutes() (r *Routes) {
 			handlers.GraphNode,
 			true,
 		},
+		// swagger:route POST /namespaces/{namespace}/graph/simulation graphs graphSimulation
+		// ---
+		// The backing JSON for a namespace graph in which the traffic is shifted according to the VirtualService provided in the request body,
+		// decorated with the differences found when compared to the current graph. (supported graphTypes: versionedApp | workload)
+		//
+		//     Consumes:
+		//     - application/json
+		//
+		//     Produces:
+		//     - application/json
+		//     - application/graphml+xml
+		//     - text/vnd.graphviz
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphResponse
+		//
+		{
+			"GraphSimulation",
+			"POST",
+			"/api/namespaces/{namespace}/graph/simulation",
+			handlers.GraphSimulation,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/applications/{app}/versions/{version}/graph/dependencies graphs graphAppVersionDependencies
+		// ---
+		// The upstream and downstream dependencies of a versioned app node, with the dependency paths. (supported graphTypes: app | versionedApp)
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: dependenciesResponse
+		//
+		{
+			"GraphAppVersionDependencies",
+			"GET",
+			"/api/namespaces/{namespace}/applications/{app}/versions/{version}/graph/dependencies",
+			handlers.GraphDependencies,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/applications/{app}/graph/dependencies graphs graphAppDependencies
+		// ---
+		// The upstream and downstream dependencies of an app node, with the dependency paths. (supported graphTypes: app | versionedApp)
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: dependenciesResponse
+		//
+		{
+			"GraphAppDependencies",
+			"GET",
+			"/api/namespaces/{namespace}/applications/{app}/graph/dependencies",
+			handlers.GraphDependencies,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/services/{service}/graph/dependencies graphs graphServiceDependencies
+		// ---
+		// The upstream and downstream dependencies of a service node, with the dependency paths.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: dependenciesResponse
+		//
+		{
+			"GraphServiceDependencies",
+			"GET",
+			"/api/namespaces/{namespace}/services/{service}/graph/dependencies",
+			handlers.GraphDependencies,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/workloads/{workload}/graph/dependencies graphs graphWorkloadDependencies
+		// ---
+		// The upstream and downstream dependencies of a workload node, with the dependency paths.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: dependenciesResponse
+		//
+		{
+			"GraphWorkloadDependencies",
+			"GET",
+			"/api/namespaces/{namespace}/workloads/{workload}/graph/dependencies",
+			handlers.GraphDependencies,
+			true,
+		},
 		// swagger:route GET /grafana integrations grafanaInfo
 		// ---
 		// Get the grafana URL and other descriptors
@@ -54,7 +54,7 @@ func NewServer() *Server {
 		Addr:         fmt.Sprintf("%v:%v", conf.Server.Address, conf.Server.Port),
 		TLSConfig:    tlsConfig,
 		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		WriteTimeout: time.Duration(conf.Server.WriteTimeout) * time.Second,
 	}
 
 	// return our new Server
@@ -73,6 +73,12 @@ func Put(name, value string) (previous string, hasPrevious bool) {
 	return previous, hasPrevious
 }
 
+// GetStatus returns the status info for the provided name, without refreshing the versions like Get.
+func GetStatus(name string) (value string, ok bool) {
+	value, ok = info.Status[name]
+	return value, ok
+}
+
 // Get returns a copy of the current status info.
 func Get() (status StatusInfo) {
 	info.ExternalServices = []ExternalServiceInfo{}
@@ -111,6 +111,19 @@ func istioVersion() (*ExternalServiceInfo, error) {
 	return parseIstioRawVersion(rawVersion)
 }
 
+// IstioVersion returns the version of the running upstream Istio release, e.g. 1.9.0. An error is returned for
+// the other Istio implementations and for the unreleased versions.
+func IstioVersion() (string, error) {
+	product, err := istioVersion()
+	if err != nil {
+		return "", err
+	}
+	if product.Name != "Istio" {
+		return "", fmt.Errorf("no Istio release version for %s %s", product.Name, product.Version)
+	}
+	return product.Version, nil
+}
+
 func parseIstioRawVersion(rawVersion string) (*ExternalServiceInfo, error) {
 	product := ExternalServiceInfo{Name: "Unknown", Version: "Unknown"}
 
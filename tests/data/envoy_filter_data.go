@@ -0,0 +1,41 @@
+package data
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func CreateEnvoyFilter(name string, namespace string) kubernetes.IstioObject {
+	return (&kubernetes.GenericIstioObject{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			ClusterName: "svc.cluster.local",
+		},
+		Spec: map[string]interface{}{
+			"configPatches": []interface{}{},
+		},
+	}).DeepCopyIstioObject()
+}
+
+func CreateEnvoyFilterPatch(applyTo, operation string, match map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"applyTo": applyTo,
+		"match":   match,
+		"patch": map[string]interface{}{
+			"operation": operation,
+			"value":     map[string]interface{}{},
+		},
+	}
+}
+
+func AddPatchToEnvoyFilter(patch map[string]interface{}, ef kubernetes.IstioObject) kubernetes.IstioObject {
+	ef.GetSpec()["configPatches"] = append(ef.GetSpec()["configPatches"].([]interface{}), patch)
+	return ef
+}
+
+func AddSelectorToEnvoyFilter(selector map[string]interface{}, ef kubernetes.IstioObject) kubernetes.IstioObject {
+	ef.GetSpec()["workloadSelector"] = selector
+	return ef
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateTLSSecret returns a kubernetes.io/tls Secret holding a self-signed certificate for the hosts, valid until notAfter
+func CreateTLSSecret(name, namespace string, hosts []string, notAfter time.Time) core_v1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Kiali"}},
+		DNSNames:     hosts,
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return core_v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: core_v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			core_v1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			core_v1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		},
+	}
+}
+
+func AddTLSToServer(mode, credentialName string, server map[string]interface{}) map[string]interface{} {
+	server["tls"] = map[string]interface{}{
+		"mode":           mode,
+		"credentialName": credentialName,
+	}
+	return server
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func CreateWorkloadEntry(name string, namespace string, address string, labels map[string]interface{}) kubernetes.IstioObject {
+	return (&kubernetes.GenericIstioObject{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			ClusterName: "svc.cluster.local",
+		},
+		Spec: map[string]interface{}{
+			"address": address,
+			"labels":  labels,
+		},
+	}).DeepCopyIstioObject()
+}
+
+func CreateWorkloadGroup(name string, namespace string, labels map[string]interface{}) kubernetes.IstioObject {
+	return (&kubernetes.GenericIstioObject{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			ClusterName: "svc.cluster.local",
+		},
+		Spec: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": labels,
+			},
+			"template": map[string]interface{}{},
+		},
+	}).DeepCopyIstioObject()
+}
+
+func AddServiceAccountToWorkloadEntry(serviceAccount string, we kubernetes.IstioObject) kubernetes.IstioObject {
+	we.GetSpec()["serviceAccount"] = serviceAccount
+	return we
+}
+
+func AddServiceAccountToWorkloadGroup(serviceAccount string, wg kubernetes.IstioObject) kubernetes.IstioObject {
+	wg.GetSpec()["template"].(map[string]interface{})["serviceAccount"] = serviceAccount
+	return wg
+}
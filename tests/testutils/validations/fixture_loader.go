@@ -1,12 +1,8 @@
 package validations
 
 import (
-	"bytes"
-	"fmt"
 	"io/ioutil"
 
-	"gopkg.in/yaml.v2"
-
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/log"
 )
@@ -29,19 +25,10 @@ func (l *YamlFixtureLoader) Load() error {
 		return err
 	}
 
-	r := bytes.NewReader(yamlFile)
-	dec := yaml.NewDecoder(r)
-
-	var resources []kubernetes.GenericIstioObject
-
-	for err == nil {
-		var resource kubernetes.GenericIstioObject
-
-		err = dec.Decode(&resource)
-		if err == nil {
-			resource.Spec = cleanUpStringInterfaceMap(resource.Spec)
-			resources = append(resources, resource)
-		}
+	resources, err := kubernetes.ParseManifests(yamlFile)
+	if err != nil {
+		log.Errorf("Error parsing test file: #%v ", err)
+		return err
 	}
 
 	l.sortResources(&kubernetes.GenericIstioObjectList{Items: resources})
@@ -131,44 +118,3 @@ func (l YamlFixtureLoader) GetResource(kind, name, namespace string) kubernetes.
 
 	return nil
 }
-
-// Needed due to Yaml.Decode default map type is map[interface{}]interface{}
-// We need to convert it to map[string]interface{} to be compliant with real Istio Objects.
-// Known issue: https://github.com/go-yaml/yaml/issues/139
-
-func cleanUpInterfaceArray(in []interface{}) []interface{} {
-	result := make([]interface{}, len(in))
-	for i, v := range in {
-		result[i] = cleanUpMapValue(v)
-	}
-	return result
-}
-
-func cleanUpInterfaceMap(in map[interface{}]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
-	for k, v := range in {
-		result[fmt.Sprintf("%v", k)] = cleanUpMapValue(v)
-	}
-	return result
-}
-
-func cleanUpStringInterfaceMap(in map[string]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
-	for k, v := range in {
-		result[k] = cleanUpMapValue(v)
-	}
-	return result
-}
-
-func cleanUpMapValue(v interface{}) interface{} {
-	switch v := v.(type) {
-	case []interface{}:
-		return cleanUpInterfaceArray(v)
-	case map[interface{}]interface{}:
-		return cleanUpInterfaceMap(v)
-	case map[string]interface{}:
-		return cleanUpStringInterfaceMap(v)
-	default:
-		return v
-	}
-}
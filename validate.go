@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+// Exit codes of the validate command
+const (
+	validateOK     = 0 // no error found, warnings don't fail the validation
+	validateErrors = 1 // errors found
+	validateFailed = 2 // the command failed, e.g. invalid arguments or manifests
+)
+
+// manifestObject is an object loaded from a manifest file
+type manifestObject struct {
+	file   string
+	object kubernetes.GenericIstioObject
+}
+
+// runValidate implements the validate command: it validates the Istio config of YAML manifests with the
+// checkers of the server, without a cluster, and prints the checks. See business.ValidateManifests.
+// Usage: kiali validate [-config <file>] [-namespace <namespace>] [-output text|sarif|junit] <file or directory>...
+func runValidate(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configFile := flags.String("config", "", "Path to the YAML configuration file. If not specified, the default configuration is used.")
+	namespace := flags.String("namespace", "default", "Namespace of the objects defined without a namespace.")
+	output := flags.String("output", "text", "Output format: text | sarif | junit.")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: kiali validate [flags] <file or directory>...\n\nValidates the Istio config of YAML manifests (*.yaml, *.yml). Exits with 1 when errors are found.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return validateFailed
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return validateFailed
+	}
+	if *output != "text" && *output != models.ValidationFormatSARIF && *output != models.ValidationFormatJUnit {
+		fmt.Fprintf(os.Stderr, "Invalid output format [%s], expecting one of: text | sarif | junit\n", *output)
+		return validateFailed
+	}
+
+	if *configFile != "" {
+		c, err := config.LoadFromFile(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load the configuration: %v\n", err)
+			return validateFailed
+		}
+		config.Set(c)
+	} else {
+		config.Set(config.NewConfig())
+	}
+
+	manifestObjects, err := loadManifests(flags.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load the manifests: %v\n", err)
+		return validateFailed
+	}
+
+	objects := make([]kubernetes.GenericIstioObject, 0, len(manifestObjects))
+	files := make(map[models.IstioValidationKey]string)
+	for _, mo := range manifestObjects {
+		objects = append(objects, mo.object)
+		ns := mo.object.Namespace
+		if ns == "" {
+			ns = *namespace
+		}
+		files[models.IstioValidationKey{ObjectType: strings.ToLower(mo.object.Kind), Name: mo.object.Name, Namespace: ns}] = mo.file
+	}
+
+	validations, err := business.ValidateManifests(objects, *namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to validate the manifests: %v\n", err)
+		return validateFailed
+	}
+
+	var errors int
+	switch *output {
+	case models.ValidationFormatSARIF:
+		errors, err = printReport(out, validations, validations.SARIF(version, files), false)
+	case models.ValidationFormatJUnit:
+		errors, err = printReport(out, validations, validations.JUnit("kiali", files), true)
+	default:
+		errors = printValidations(out, validations, files, len(objects))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print the validations: %v\n", err)
+		return validateFailed
+	}
+	if errors > 0 {
+		return validateErrors
+	}
+	return validateOK
+}
+
+// loadManifests loads the objects of the manifest files, directories are walked for *.yaml and *.yml files
+func loadManifests(paths []string) ([]manifestObject, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// explicit files are always loaded
+			if ext := filepath.Ext(file); file == path || ext == ".yaml" || ext == ".yml" {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []manifestObject
+	for _, file := range files {
+		manifests, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		objects, err := kubernetes.ParseManifests(manifests)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		for _, object := range objects {
+			result = append(result, manifestObject{file: file, object: object})
+		}
+	}
+	return result, nil
+}
+
+// printReport prints a SARIF (JSON) or JUnit (XML) report of the validations and returns the number of errors
+func printReport(out io.Writer, validations models.IstioValidations, report interface{}, isXML bool) (int, error) {
+	var b []byte
+	var err error
+	if isXML {
+		b, err = xml.MarshalIndent(report, "", "  ")
+		b = append([]byte(xml.Header), b...)
+	} else {
+		b, err = json.MarshalIndent(report, "", "  ")
+	}
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(out, "%s\n", b)
+
+	errors := 0
+	for _, validation := range validations {
+		for _, check := range validation.Checks {
+			if check.Severity == models.ErrorSeverity {
+				errors++
+			}
+		}
+	}
+	return errors, nil
+}
+
+// printValidations prints the checks sorted by namespace, object type and name, one per line, and returns
+// the number of errors
+func printValidations(out io.Writer, validations models.IstioValidations, files map[models.IstioValidationKey]string, objectCount int) int {
+	keys := make([]models.IstioValidationKey, 0, len(validations))
+	for key := range validations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		if keys[i].ObjectType != keys[j].ObjectType {
+			return keys[i].ObjectType < keys[j].ObjectType
+		}
+		return keys[i].Name < keys[j].Name
+	})
+
+	errors, warnings := 0, 0
+	for _, key := range keys {
+		for _, check := range validations[key].Checks {
+			switch check.Severity {
+			case models.ErrorSeverity:
+				errors++
+			case models.WarningSeverity:
+				warnings++
+			}
+			file, ok := files[key]
+			if !ok {
+				file = "-"
+			}
+			fmt.Fprintf(out, "%s: %s %s %s %s/%s %s: %s\n", file, check.Severity, check.Code, key.ObjectType, key.Namespace, key.Name, check.Path, check.Message)
+		}
+	}
+	fmt.Fprintf(out, "Validated %d objects: %d errors, %d warnings\n", objectCount, errors, warnings)
+	return errors
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	out := &bytes.Buffer{}
+	assert.Equal(validateErrors, runValidate([]string{"tests/data/validations/offline"}, out))
+	assert.Contains(out.String(), "tests/data/validations/offline/bookinfo.yaml: error KIA1101 virtualservice bookinfo/reviews spec/http[0]/route[1]/destination/host:")
+	assert.Contains(out.String(), "Validated 12 objects: 2 errors, 4 warnings\n")
+
+	out.Reset()
+	assert.Equal(validateOK, runValidate([]string{"-namespace", "bookinfo", "tests/data/validations/offline/valid.yaml"}, out))
+	assert.Equal("Validated 4 objects: 0 errors, 0 warnings\n", out.String())
+
+	out.Reset()
+	assert.Equal(validateErrors, runValidate([]string{"-output", "sarif", "tests/data/validations/offline/bookinfo.yaml"}, out))
+	assert.Contains(out.String(), `"ruleId": "KIA1101"`)
+	assert.Contains(out.String(), `"uri": "tests/data/validations/offline/bookinfo.yaml"`)
+
+	out.Reset()
+	assert.Equal(validateErrors, runValidate([]string{"-output", "junit", "tests/data/validations/offline/bookinfo.yaml"}, out))
+	assert.Contains(out.String(), `<testcase name="virtualservice/reviews" classname="bookinfo.virtualservice">`)
+
+	assert.Equal(validateFailed, runValidate([]string{"-output", "html", "tests/data/validations/offline/bookinfo.yaml"}, out))
+	assert.Equal(validateFailed, runValidate([]string{}, out))
+	assert.Equal(validateFailed, runValidate([]string{"tests/data/validations/offline/missing.yaml"}, out))
+}